@@ -8,16 +8,574 @@ package api
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oapi-codegen/runtime"
 )
 
+const (
+	CookieAuthScopes = "CookieAuth.Scopes"
+)
+
+// Defines values for CommunityAlgorithm.
+const (
+	LabelPropagation CommunityAlgorithm = "label_propagation"
+	Louvain          CommunityAlgorithm = "louvain"
+)
+
+// Defines values for Direction.
+const (
+	Both Direction = "both"
+	In   Direction = "in"
+	Out  Direction = "out"
+)
+
+// Defines values for EdgeKind.
+const (
+	CoPlaylisted    EdgeKind = "co_playlisted"
+	CommentedOn     EdgeKind = "commented_on"
+	Follows         EdgeKind = "follows"
+	LikedTrackOf    EdgeKind = "liked_track_of"
+	RepostedTrackOf EdgeKind = "reposted_track_of"
+	SoundsLike      EdgeKind = "sounds_like"
+)
+
+// Defines values for ExportFormat.
+const (
+	Csv     ExportFormat = "csv"
+	Dot     ExportFormat = "dot"
+	Gexf    ExportFormat = "gexf"
+	Graphml ExportFormat = "graphml"
+	Json    ExportFormat = "json"
+)
+
+// Defines values for ExportScope.
+const (
+	ExportScopeAll       ExportScope = "all"
+	ExportScopeCommunity ExportScope = "community"
+	ExportScopeCrawl     ExportScope = "crawl"
+	ExportScopeEgo       ExportScope = "ego"
+)
+
+// Defines values for Layer.
+const (
+	Playlists  Layer = "playlists"
+	Similarity Layer = "similarity"
+	Social     Layer = "social"
+)
+
+// Defines values for Metric.
+const (
+	Authority Metric = "authority"
+	Hub       Metric = "hub"
+	InDegree  Metric = "in_degree"
+	OutDegree Metric = "out_degree"
+	Pagerank  Metric = "pagerank"
+)
+
+// Defines values for Plan.
+const (
+	Artist    Plan = "Artist"
+	ArtistPro Plan = "ArtistPro"
+	None      Plan = "None"
+)
+
+// CacheStats defines model for CacheStats.
+type CacheStats struct {
+	// Generation Counts the changes to the graph, each of which invalidated every cached result.
+	Generation int64             `json:"generation"`
+	Queries    []QueryCacheStats `json:"queries"`
+}
+
+// Community defines model for Community.
+type Community struct {
+	// Id The community's number within its run, from 0 by decreasing size.
+	Id int `json:"id"`
+
+	// Reciprocity The share of follows between members that are reciprocated.
+	Reciprocity float64 `json:"reciprocity"`
+	Size        int     `json:"size"`
+
+	// TopMembers The ids of the most followed members, most followed first.
+	TopMembers []int64 `json:"topMembers"`
+}
+
+// CommunityAlgorithm defines model for CommunityAlgorithm.
+type CommunityAlgorithm string
+
+// CommunityMove defines model for CommunityMove.
+type CommunityMove struct {
+	// From The person's community in the earlier snapshot.
+	From   int            `json:"from"`
+	Person SnapshotPerson `json:"person"`
+	To     int            `json:"to"`
+}
+
+// CommunityPage defines model for CommunityPage.
+type CommunityPage struct {
+	Communities []Community  `json:"communities"`
+	Limit       int          `json:"limit"`
+	Offset      int          `json:"offset"`
+	Run         CommunityRun `json:"run"`
+}
+
+// CommunityRun defines model for CommunityRun.
+type CommunityRun struct {
+	Algorithm CommunityAlgorithm `json:"algorithm"`
+
+	// Communities How many communities the run found.
+	Communities int       `json:"communities"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Id          int64     `json:"id"`
+	Modularity  float64   `json:"modularity"`
+}
+
+// Direction defines model for Direction.
+type Direction string
+
+// Edge defines model for Edge.
+type Edge struct {
+	// FirstInteractionAt Time of the earliest interaction the edge aggregates, when known.
+	FirstInteractionAt *time.Time `json:"firstInteractionAt,omitempty"`
+	Kind               EdgeKind   `json:"kind"`
+
+	// LastInteractionAt Time of the latest interaction the edge aggregates, when known.
+	LastInteractionAt *time.Time `json:"lastInteractionAt,omitempty"`
+
+	// Reciprocal Whether the target has an edge of the same kind back to the source.
+	Reciprocal bool    `json:"reciprocal"`
+	Source     int64   `json:"source"`
+	Target     int64   `json:"target"`
+	Weight     float64 `json:"weight"`
+}
+
+// EdgeKind defines model for EdgeKind.
+type EdgeKind string
+
+// EgoNetwork defines model for EgoNetwork.
+type EgoNetwork struct {
+	Edges []Edge   `json:"edges"`
+	Nodes []Person `json:"nodes"`
+
+	// Total How many people are within the radius, including those left out.
+	Total     int  `json:"total"`
+	Truncated bool `json:"truncated"`
+}
+
+// ExportFormat The file format: GEXF for Gephi, GraphML for Cytoscape, yEd and NetworkX, Graphviz DOT,
+// d3 node-link JSON, or a zip archive of CSV node and edge lists.
+type ExportFormat string
+
+// ExportScope Which part of the graph is exported.
+type ExportScope string
+
+// FollowerGain defines model for FollowerGain.
+type FollowerGain struct {
+	After int `json:"after"`
+
+	// Before The person's followers in the earlier snapshot, 0 if they were missing from it.
+	Before int            `json:"before"`
+	Person SnapshotPerson `json:"person"`
+}
+
+// Graph defines model for Graph.
+type Graph struct {
+	Edges []Edge   `json:"edges"`
+	Nodes []Person `json:"nodes"`
+}
+
+// Layer social holds follows and engagement between people; similarity holds
+// sounds_like edges rolled up from SoundCloud's related tracks; playlists
+// holds co_playlisted edges between artists appearing in the same playlists.
+type Layer string
+
+// LayoutRun defines model for LayoutRun.
+type LayoutRun struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Id        int64     `json:"id"`
+
+	// Incremental Whether the run only placed people missing from the previous run.
+	Incremental bool `json:"incremental"`
+	Iterations  int  `json:"iterations"`
+
+	// Nodes How many people the run placed.
+	Nodes int `json:"nodes"`
+}
+
+// Metric A centrality metric computed over the follow graph. pagerank and hub/authority
+// are the PageRank and HITS scores, reading a follow as an endorsement.
+type Metric string
+
+// PathSet defines model for PathSet.
+type PathSet struct {
+	Edges []Edge   `json:"edges"`
+	Nodes []Person `json:"nodes"`
+
+	// Paths The person ids along each path, from start to end.
+	Paths [][]int64 `json:"paths"`
+}
+
+// Person defines model for Person.
+type Person struct {
+	// Community The person's community in the latest Louvain run.
+	Community *int           `json:"community,omitempty"`
+	Id        int64          `json:"id"`
+	ImageUrl  string         `json:"imageUrl"`
+	Metrics   *PersonMetrics `json:"metrics,omitempty"`
+	Name      string         `json:"name"`
+	Plan      Plan           `json:"plan"`
+
+	// Position Where the latest layout run placed a person.
+	Position   *Position `json:"position,omitempty"`
+	Profile    *Profile  `json:"profile,omitempty"`
+	TrackCount int64     `json:"trackCount"`
+	Urn        *string   `json:"urn,omitempty"`
+	Username   string    `json:"username"`
+	Verified   bool      `json:"verified"`
+}
+
+// PersonMetrics defines model for PersonMetrics.
+type PersonMetrics struct {
+	Authority  float64   `json:"authority"`
+	ComputedAt time.Time `json:"computedAt"`
+	Hub        float64   `json:"hub"`
+	InDegree   int       `json:"inDegree"`
+	OutDegree  int       `json:"outDegree"`
+	PageRank   float64   `json:"pageRank"`
+}
+
+// PersonPage defines model for PersonPage.
+type PersonPage struct {
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+	People []Person `json:"people"`
+
+	// Total How many people there are across every page, when known.
+	Total *int `json:"total,omitempty"`
+}
+
+// PersonPosition defines model for PersonPosition.
+type PersonPosition struct {
+	Id int64   `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+}
+
+// Plan defines model for Plan.
+type Plan string
+
+// Position Where the latest layout run placed a person.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// PositionPage defines model for PositionPage.
+type PositionPage struct {
+	Limit     int              `json:"limit"`
+	Offset    int              `json:"offset"`
+	Positions []PersonPosition `json:"positions"`
+	Run       LayoutRun        `json:"run"`
+}
+
+// Profile defines model for Profile.
+type Profile struct {
+	City         string        `json:"city"`
+	Country      string        `json:"country"`
+	Description  string        `json:"description"`
+	DiscogsName  string        `json:"discogsName"`
+	Links        []ProfileLink `json:"links"`
+	Website      string        `json:"website"`
+	WebsiteTitle string        `json:"websiteTitle"`
+}
+
+// ProfileLink defines model for ProfileLink.
+type ProfileLink struct {
+	Service  string `json:"service"`
+	Title    string `json:"title"`
+	Url      string `json:"url"`
+	Username string `json:"username"`
+}
+
+// QueryCacheStats defines model for QueryCacheStats.
+type QueryCacheStats struct {
+	HitRate float64 `json:"hitRate"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	Query   string  `json:"query"`
+}
+
+// Reciprocity defines model for Reciprocity.
+type Reciprocity struct {
+	Followers int `json:"followers"`
+	Following int `json:"following"`
+
+	// Mutuals How many people both follow and are followed by the person.
+	Mutuals int `json:"mutuals"`
+
+	// Ratio The share of the person's follows, in either direction, that are reciprocated.
+	Ratio float64 `json:"ratio"`
+}
+
+// Snapshot defines model for Snapshot.
+type Snapshot struct {
+	// CommunityRunId The Louvain run people's communities were copied from, if any had run.
+	CommunityRunId *int64    `json:"communityRunId,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	Follows        int       `json:"follows"`
+	Name           string    `json:"name"`
+	People         int       `json:"people"`
+}
+
+// SnapshotDiff defines model for SnapshotDiff.
+type SnapshotDiff struct {
+	AddedFollows      []SnapshotFollow `json:"addedFollows"`
+	AddedFollowsCount int              `json:"addedFollowsCount"`
+	AddedPeople       []SnapshotPerson `json:"addedPeople"`
+	AddedPeopleCount  int              `json:"addedPeopleCount"`
+	CommunityMoves    []CommunityMove  `json:"communityMoves"`
+
+	// FollowerGains The people who gained the most followers, biggest gain first.
+	FollowerGains       []FollowerGain   `json:"followerGains"`
+	From                Snapshot         `json:"from"`
+	MovedPeopleCount    int              `json:"movedPeopleCount"`
+	RemovedFollows      []SnapshotFollow `json:"removedFollows"`
+	RemovedFollowsCount int              `json:"removedFollowsCount"`
+	RemovedPeople       []SnapshotPerson `json:"removedPeople"`
+	RemovedPeopleCount  int              `json:"removedPeopleCount"`
+	To                  Snapshot         `json:"to"`
+}
+
+// SnapshotFollow defines model for SnapshotFollow.
+type SnapshotFollow struct {
+	// Followee The followee's handle.
+	Followee   string `json:"followee"`
+	FolloweeId int64  `json:"followeeId"`
+
+	// Follower The follower's handle.
+	Follower   string `json:"follower"`
+	FollowerId int64  `json:"followerId"`
+}
+
+// SnapshotPerson defines model for SnapshotPerson.
+type SnapshotPerson struct {
+	Community  *int    `json:"community,omitempty"`
+	Followers  int     `json:"followers"`
+	Followings int     `json:"followings"`
+	Id         int64   `json:"id"`
+	Name       string  `json:"name"`
+	Urn        *string `json:"urn,omitempty"`
+	Username   string  `json:"username"`
+}
+
+// Tile defines model for Tile.
+type Tile struct {
+	Edges []TileEdge `json:"edges"`
+	Nodes []TileNode `json:"nodes"`
+
+	// Total How many nodes are in the viewport, including those left out.
+	Total     int  `json:"total"`
+	Truncated bool `json:"truncated"`
+	Zoom      int  `json:"zoom"`
+}
+
+// TileEdge defines model for TileEdge.
+type TileEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+
+	// Weight How many follows go from the source's people to the target's.
+	Weight int `json:"weight"`
+}
+
+// TileNode defines model for TileNode.
+type TileNode struct {
+	Community *int `json:"community,omitempty"`
+
+	// Id Identifies the node among those at the same zoom.
+	Id     string  `json:"id"`
+	Person *Person `json:"person,omitempty"`
+
+	// PersonId The person, or the most followed member of a cluster.
+	PersonId int64 `json:"personId"`
+
+	// Size How many people the node stands for, 1 for a single person.
+	Size int     `json:"size"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// Limit defines model for Limit.
+type Limit = int
+
+// Offset defines model for Offset.
+type Offset = int
+
+// PersonId defines model for PersonId.
+type PersonId = int64
+
+// SnapshotName defines model for SnapshotName.
+type SnapshotName = string
+
+// ListCommunitiesParams defines parameters for ListCommunities.
+type ListCommunitiesParams struct {
+	// Algorithm Which community detection algorithm's latest run to read.
+	Algorithm *CommunityAlgorithm `form:"algorithm,omitempty" json:"algorithm,omitempty"`
+	Limit     *Limit              `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset    *Offset             `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListCommunityMembersParams defines parameters for ListCommunityMembers.
+type ListCommunityMembersParams struct {
+	// Algorithm Which community detection algorithm's latest run to read.
+	Algorithm *CommunityAlgorithm `form:"algorithm,omitempty" json:"algorithm,omitempty"`
+	Limit     *Limit              `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset    *Offset             `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ExportGraphParams defines parameters for ExportGraph.
+type ExportGraphParams struct {
+	Format *ExportFormat `form:"format,omitempty" json:"format,omitempty"`
+	Scope  *ExportScope  `form:"scope,omitempty" json:"scope,omitempty"`
+
+	// PersonId The person whose ego network is exported.
+	PersonId *int64 `form:"personId,omitempty" json:"personId,omitempty"`
+
+	// Radius How many follows away people of the ego network may be.
+	Radius *int `form:"radius,omitempty" json:"radius,omitempty"`
+
+	// Direction Which edges to follow relative to the person.
+	Direction *Direction `form:"direction,omitempty" json:"direction,omitempty"`
+
+	// Algorithm Which community detection algorithm's latest run to read.
+	Algorithm *CommunityAlgorithm `form:"algorithm,omitempty" json:"algorithm,omitempty"`
+
+	// CommunityId The community exported, from the latest run of the algorithm.
+	CommunityId *int `form:"communityId,omitempty" json:"communityId,omitempty"`
+
+	// Since Only follows found after this time are exported.
+	Since *time.Time `form:"since,omitempty" json:"since,omitempty"`
+
+	// Until Only follows found up to this time are exported.
+	Until *time.Time `form:"until,omitempty" json:"until,omitempty"`
+}
+
+// ListLayoutPositionsParams defines parameters for ListLayoutPositions.
+type ListLayoutPositionsParams struct {
+	Limit  *int    `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// GetLayoutTileParams defines parameters for GetLayoutTile.
+type GetLayoutTileParams struct {
+	MinX float64 `form:"minX" json:"minX"`
+	MinY float64 `form:"minY" json:"minY"`
+	MaxX float64 `form:"maxX" json:"maxX"`
+	MaxY float64 `form:"maxY" json:"maxY"`
+	Zoom int     `form:"zoom" json:"zoom"`
+
+	// Limit The most nodes to return. When more are visible, the largest are kept.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetPathsParams defines parameters for GetPaths.
+type GetPathsParams struct {
+	// From The person to start from. Defaults to the person linked to the logged in user.
+	From *int64 `form:"from,omitempty" json:"from,omitempty"`
+	To   int64  `form:"to" json:"to"`
+
+	// K How many alternative paths to return, shortest first.
+	K *int `form:"k,omitempty" json:"k,omitempty"`
+
+	// Direction Which edges to follow relative to the person.
+	Direction *Direction `form:"direction,omitempty" json:"direction,omitempty"`
+}
+
+// ListPeopleParams defines parameters for ListPeople.
+type ListPeopleParams struct {
+	// Q Text matched against profile descriptions, websites and linked usernames.
+	Q       *string `form:"q,omitempty" json:"q,omitempty"`
+	City    *string `form:"city,omitempty" json:"city,omitempty"`
+	Country *string `form:"country,omitempty" json:"country,omitempty"`
+
+	// Service Only include people linking to this platform, e.g. instagram or bandcamp.
+	Service *string `form:"service,omitempty" json:"service,omitempty"`
+
+	// Sort Rank people by this metric, highest first, instead of by id.
+	Sort   *Metric `form:"sort,omitempty" json:"sort,omitempty"`
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// SearchPeopleParams defines parameters for SearchPeople.
+type SearchPeopleParams struct {
+	Q      string  `form:"q" json:"q"`
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListTopPeopleParams defines parameters for ListTopPeople.
+type ListTopPeopleParams struct {
+	Metric *Metric `form:"metric,omitempty" json:"metric,omitempty"`
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// GetPersonEdgesParams defines parameters for GetPersonEdges.
+type GetPersonEdgesParams struct {
+	// Layer Which graph layer to read edges from.
+	Layer *Layer `form:"layer,omitempty" json:"layer,omitempty"`
+
+	// Kind Edge kinds to include in the social layer. Defaults to every kind.
+	Kind *[]EdgeKind `form:"kind,omitempty" json:"kind,omitempty"`
+
+	// Direction Which edges to follow relative to the person.
+	Direction *Direction `form:"direction,omitempty" json:"direction,omitempty"`
+}
+
+// GetPersonEgoNetworkParams defines parameters for GetPersonEgoNetwork.
+type GetPersonEgoNetworkParams struct {
+	// Radius How many follows away people may be.
+	Radius *int `form:"radius,omitempty" json:"radius,omitempty"`
+
+	// Direction Which edges to follow relative to the person.
+	Direction *Direction `form:"direction,omitempty" json:"direction,omitempty"`
+
+	// Limit The most people to return. When more are within the radius, the best connected are kept.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListPersonMutualsParams defines parameters for ListPersonMutuals.
+type ListPersonMutualsParams struct {
+	Limit  *Limit  `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// CreateSnapshotJSONBody defines parameters for CreateSnapshot.
+type CreateSnapshotJSONBody struct {
+	Name string `json:"name"`
+}
+
+// GetSnapshotDiffParams defines parameters for GetSnapshotDiff.
+type GetSnapshotDiffParams struct {
+	// From The earlier snapshot.
+	From string `form:"from" json:"from"`
+
+	// Limit The most changes of each kind to list, most followed people first.
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// CreateSnapshotJSONRequestBody defines body for CreateSnapshot for application/json ContentType.
+type CreateSnapshotJSONRequestBody CreateSnapshotJSONBody
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// Logs out the user.
@@ -32,6 +590,63 @@ type ServerInterface interface {
 	// Validates the user's session.
 	// (GET /auth/validate)
 	Validate(w http.ResponseWriter, r *http.Request)
+	// Counts how often each cached query was answered from the cache since the server started.
+	// (GET /cache/stats)
+	GetCacheStats(w http.ResponseWriter, r *http.Request)
+	// Lists the communities found by the latest run of a community detection algorithm.
+	// (GET /communities)
+	ListCommunities(w http.ResponseWriter, r *http.Request, params ListCommunitiesParams)
+	// Lists the members of a community found by the latest run of a community detection algorithm.
+	// (GET /communities/{communityId}/members)
+	ListCommunityMembers(w http.ResponseWriter, r *http.Request, communityId int, params ListCommunityMembersParams)
+	// Streams the whole graph, or a subgraph of it, in a file format of graph analysis tools.
+	// (GET /export)
+	ExportGraph(w http.ResponseWriter, r *http.Request, params ExportGraphParams)
+	// Lists where the latest layout run placed people.
+	// (GET /layout/positions)
+	ListLayoutPositions(w http.ResponseWriter, r *http.Request, params ListLayoutPositionsParams)
+	// Lists the people and follows visible in a viewport of the latest layout.
+	// (GET /layout/tiles)
+	GetLayoutTile(w http.ResponseWriter, r *http.Request, params GetLayoutTileParams)
+	// Finds the shortest chains of follows between two people.
+	// (GET /paths)
+	GetPaths(w http.ResponseWriter, r *http.Request, params GetPathsParams)
+	// Lists people whose profile matches the given filters.
+	// (GET /people)
+	ListPeople(w http.ResponseWriter, r *http.Request, params ListPeopleParams)
+	// Searches people by handle and display name.
+	// (GET /people/search)
+	SearchPeople(w http.ResponseWriter, r *http.Request, params SearchPeopleParams)
+	// Lists the people ranking highest by a centrality metric.
+	// (GET /people/top)
+	ListTopPeople(w http.ResponseWriter, r *http.Request, params ListTopPeopleParams)
+	// Gets a person and their profile.
+	// (GET /people/{personId})
+	GetPerson(w http.ResponseWriter, r *http.Request, personId PersonId)
+	// Lists the typed edges of a person.
+	// (GET /people/{personId}/edges)
+	GetPersonEdges(w http.ResponseWriter, r *http.Request, personId PersonId, params GetPersonEdgesParams)
+	// Lists everyone within a number of follows of a person, and the follows between them.
+	// (GET /people/{personId}/ego)
+	GetPersonEgoNetwork(w http.ResponseWriter, r *http.Request, personId PersonId, params GetPersonEgoNetworkParams)
+	// Lists the people who both follow and are followed by a person.
+	// (GET /people/{personId}/mutuals)
+	ListPersonMutuals(w http.ResponseWriter, r *http.Request, personId PersonId, params ListPersonMutualsParams)
+	// Reports how many of a person's follows are reciprocated.
+	// (GET /people/{personId}/reciprocity)
+	GetPersonReciprocity(w http.ResponseWriter, r *http.Request, personId PersonId)
+	// Lists the named snapshots of the people graph, newest first.
+	// (GET /snapshots)
+	ListSnapshots(w http.ResponseWriter, r *http.Request)
+	// Copies the people and follows, with people's communities, under a name.
+	// (POST /snapshots)
+	CreateSnapshot(w http.ResponseWriter, r *http.Request)
+	// Deletes a snapshot.
+	// (DELETE /snapshots/{name})
+	DeleteSnapshot(w http.ResponseWriter, r *http.Request, name SnapshotName)
+	// Reports what changed from an earlier snapshot to this one.
+	// (GET /snapshots/{name}/diff)
+	GetSnapshotDiff(w http.ResponseWriter, r *http.Request, name SnapshotName, params GetSnapshotDiffParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -99,6 +714,985 @@ func (siw *ServerInterfaceWrapper) Validate(w http.ResponseWriter, r *http.Reque
 	handler.ServeHTTP(w, r)
 }
 
+// GetCacheStats operation middleware
+func (siw *ServerInterfaceWrapper) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetCacheStats(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListCommunities operation middleware
+func (siw *ServerInterfaceWrapper) ListCommunities(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListCommunitiesParams
+
+	// ------------- Optional query parameter "algorithm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "algorithm", r.URL.Query(), &params.Algorithm)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "algorithm", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListCommunities(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListCommunityMembers operation middleware
+func (siw *ServerInterfaceWrapper) ListCommunityMembers(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "communityId" -------------
+	var communityId int
+
+	err = runtime.BindStyledParameterWithOptions("simple", "communityId", r.PathValue("communityId"), &communityId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "communityId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListCommunityMembersParams
+
+	// ------------- Optional query parameter "algorithm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "algorithm", r.URL.Query(), &params.Algorithm)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "algorithm", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListCommunityMembers(w, r, communityId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ExportGraph operation middleware
+func (siw *ServerInterfaceWrapper) ExportGraph(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportGraphParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "scope" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "scope", r.URL.Query(), &params.Scope)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "scope", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "personId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "personId", r.URL.Query(), &params.PersonId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "personId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "radius" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "radius", r.URL.Query(), &params.Radius)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "radius", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "direction" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "direction", r.URL.Query(), &params.Direction)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "direction", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "algorithm" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "algorithm", r.URL.Query(), &params.Algorithm)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "algorithm", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "communityId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "communityId", r.URL.Query(), &params.CommunityId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "communityId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "since" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "since", r.URL.Query(), &params.Since)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "since", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "until" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "until", r.URL.Query(), &params.Until)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "until", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ExportGraph(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListLayoutPositions operation middleware
+func (siw *ServerInterfaceWrapper) ListLayoutPositions(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListLayoutPositionsParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListLayoutPositions(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetLayoutTile operation middleware
+func (siw *ServerInterfaceWrapper) GetLayoutTile(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetLayoutTileParams
+
+	// ------------- Required query parameter "minX" -------------
+
+	if paramValue := r.URL.Query().Get("minX"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "minX"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "minX", r.URL.Query(), &params.MinX)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "minX", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "minY" -------------
+
+	if paramValue := r.URL.Query().Get("minY"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "minY"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "minY", r.URL.Query(), &params.MinY)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "minY", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "maxX" -------------
+
+	if paramValue := r.URL.Query().Get("maxX"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "maxX"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "maxX", r.URL.Query(), &params.MaxX)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "maxX", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "maxY" -------------
+
+	if paramValue := r.URL.Query().Get("maxY"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "maxY"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "maxY", r.URL.Query(), &params.MaxY)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "maxY", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "zoom" -------------
+
+	if paramValue := r.URL.Query().Get("zoom"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "zoom"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "zoom", r.URL.Query(), &params.Zoom)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "zoom", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetLayoutTile(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPaths operation middleware
+func (siw *ServerInterfaceWrapper) GetPaths(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPathsParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "to" -------------
+
+	if paramValue := r.URL.Query().Get("to"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "to"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "k" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "k", r.URL.Query(), &params.K)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "k", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "direction" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "direction", r.URL.Query(), &params.Direction)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "direction", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPaths(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListPeople operation middleware
+func (siw *ServerInterfaceWrapper) ListPeople(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListPeopleParams
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "city" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "city", r.URL.Query(), &params.City)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "city", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "country" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "country", r.URL.Query(), &params.Country)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "country", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "service" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "service", r.URL.Query(), &params.Service)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "service", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListPeople(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// SearchPeople operation middleware
+func (siw *ServerInterfaceWrapper) SearchPeople(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchPeopleParams
+
+	// ------------- Required query parameter "q" -------------
+
+	if paramValue := r.URL.Query().Get("q"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "q"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SearchPeople(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListTopPeople operation middleware
+func (siw *ServerInterfaceWrapper) ListTopPeople(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTopPeopleParams
+
+	// ------------- Optional query parameter "metric" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "metric", r.URL.Query(), &params.Metric)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "metric", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListTopPeople(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPerson operation middleware
+func (siw *ServerInterfaceWrapper) GetPerson(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "personId" -------------
+	var personId PersonId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "personId", r.PathValue("personId"), &personId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "personId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPerson(w, r, personId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPersonEdges operation middleware
+func (siw *ServerInterfaceWrapper) GetPersonEdges(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "personId" -------------
+	var personId PersonId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "personId", r.PathValue("personId"), &personId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "personId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPersonEdgesParams
+
+	// ------------- Optional query parameter "layer" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "layer", r.URL.Query(), &params.Layer)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "layer", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "kind" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "kind", r.URL.Query(), &params.Kind)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "kind", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "direction" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "direction", r.URL.Query(), &params.Direction)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "direction", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPersonEdges(w, r, personId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPersonEgoNetwork operation middleware
+func (siw *ServerInterfaceWrapper) GetPersonEgoNetwork(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "personId" -------------
+	var personId PersonId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "personId", r.PathValue("personId"), &personId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "personId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPersonEgoNetworkParams
+
+	// ------------- Optional query parameter "radius" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "radius", r.URL.Query(), &params.Radius)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "radius", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "direction" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "direction", r.URL.Query(), &params.Direction)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "direction", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPersonEgoNetwork(w, r, personId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListPersonMutuals operation middleware
+func (siw *ServerInterfaceWrapper) ListPersonMutuals(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "personId" -------------
+	var personId PersonId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "personId", r.PathValue("personId"), &personId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "personId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListPersonMutualsParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListPersonMutuals(w, r, personId, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPersonReciprocity operation middleware
+func (siw *ServerInterfaceWrapper) GetPersonReciprocity(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "personId" -------------
+	var personId PersonId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "personId", r.PathValue("personId"), &personId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "personId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPersonReciprocity(w, r, personId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListSnapshots operation middleware
+func (siw *ServerInterfaceWrapper) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListSnapshots(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateSnapshot operation middleware
+func (siw *ServerInterfaceWrapper) CreateSnapshot(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateSnapshot(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteSnapshot operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name SnapshotName
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", r.PathValue("name"), &name, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteSnapshot(w, r, name)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSnapshotDiff operation middleware
+func (siw *ServerInterfaceWrapper) GetSnapshotDiff(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name SnapshotName
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", r.PathValue("name"), &name, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, CookieAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSnapshotDiffParams
+
+	// ------------- Required query parameter "from" -------------
+
+	if paramValue := r.URL.Query().Get("from"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "from"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSnapshotDiff(w, r, name, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 type UnescapedCookieParamError struct {
 	ParamName string
 	Err       error
@@ -223,6 +1817,25 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 	m.HandleFunc("GET "+options.BaseURL+"/auth", wrapper.Authenticate)
 	m.HandleFunc("GET "+options.BaseURL+"/auth/callback", wrapper.Callback)
 	m.HandleFunc("GET "+options.BaseURL+"/auth/validate", wrapper.Validate)
+	m.HandleFunc("GET "+options.BaseURL+"/cache/stats", wrapper.GetCacheStats)
+	m.HandleFunc("GET "+options.BaseURL+"/communities", wrapper.ListCommunities)
+	m.HandleFunc("GET "+options.BaseURL+"/communities/{communityId}/members", wrapper.ListCommunityMembers)
+	m.HandleFunc("GET "+options.BaseURL+"/export", wrapper.ExportGraph)
+	m.HandleFunc("GET "+options.BaseURL+"/layout/positions", wrapper.ListLayoutPositions)
+	m.HandleFunc("GET "+options.BaseURL+"/layout/tiles", wrapper.GetLayoutTile)
+	m.HandleFunc("GET "+options.BaseURL+"/paths", wrapper.GetPaths)
+	m.HandleFunc("GET "+options.BaseURL+"/people", wrapper.ListPeople)
+	m.HandleFunc("GET "+options.BaseURL+"/people/search", wrapper.SearchPeople)
+	m.HandleFunc("GET "+options.BaseURL+"/people/top", wrapper.ListTopPeople)
+	m.HandleFunc("GET "+options.BaseURL+"/people/{personId}", wrapper.GetPerson)
+	m.HandleFunc("GET "+options.BaseURL+"/people/{personId}/edges", wrapper.GetPersonEdges)
+	m.HandleFunc("GET "+options.BaseURL+"/people/{personId}/ego", wrapper.GetPersonEgoNetwork)
+	m.HandleFunc("GET "+options.BaseURL+"/people/{personId}/mutuals", wrapper.ListPersonMutuals)
+	m.HandleFunc("GET "+options.BaseURL+"/people/{personId}/reciprocity", wrapper.GetPersonReciprocity)
+	m.HandleFunc("GET "+options.BaseURL+"/snapshots", wrapper.ListSnapshots)
+	m.HandleFunc("POST "+options.BaseURL+"/snapshots", wrapper.CreateSnapshot)
+	m.HandleFunc("DELETE "+options.BaseURL+"/snapshots/{name}", wrapper.DeleteSnapshot)
+	m.HandleFunc("GET "+options.BaseURL+"/snapshots/{name}/diff", wrapper.GetSnapshotDiff)
 
 	return m
 }
@@ -230,15 +1843,100 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/7STzWrcMBDHX0XMpRdje3dz8i1sC10amlBDL6UUVZ5di8gaVTNaWBa/e5Edp2myFBrI",
-	"TRr+8/GbjzMYGgJ59MLQnIHRpGjl1JoeB5xMW6J7i9dJ+vyzHhowkwkK8HpAaKD90La7288/du+hADmF",
-	"bNPBfsITjONYgPV7ys5ixU168nZILrG6vttBAUeMbClHrstVWcNYAAX0OlhoYFPW5RUUELT0U0GVfqil",
-	"Q4eC+UUBoxZLftdBAzd0oCRQQEQO5Hnm2NTr2YlNtEHmfF+wsxGNsBJS0qNydLBeBX3AEgroUXcYJ/cb",
-	"Mnp2OgPn7uj82lMctEADKdo/7CzR+kNmz/SchkHH01wYK0oyZUqMscyoB5SXDLnf6MUaLfj/JC0l320d",
-	"pW4GegOWO4x7Glhpr7QxlLw89C7ir4Qs6mj10zpuM5Fal3UJOdA0xMpo535qc5/TX2zDdhG8apg9DfhW",
-	"s/yofeeQpzwLhtpHGi5CP2E+amc7Pe/tReavi+AZ87quXzK3yPl0lGU1BZ426qpe/VNp/aL9C2lJzI/7",
-	"+Y4Vz27lfMmM8Tg18dsZUnTQQC8SuKkqR0a7nliaTV3XlQ62Oq5gLJ7reLn90tDwKPs+/g4AAP//Kg4b",
-	"booEAAA=",
+	"H4sIAAAAAAAC/+x9bXPbOJLwX0Hx2ap8eBjZSWa3ar2fckkmm9u8+OLc7NzF2RREtkSMSYADgFaUlP/7",
+	"FRovBEVQoux4bu72PtkSwUaj0d3oV+hbVoimFRy4VtnZt6ylkjagQeKnZ6JpOs709mm9FpLpqjHflqAK",
+	"yVrNBM/Osr9XrKhI4UeSEjQU5hmh/qUHitRUg9JEdpxoQSTQcpHlGTMAfu1AbrM847SB7CwLb2V5pooK",
+	"Gmrm/IOEVXaW/b+THt0T+1SdJLC8ucmz50xaRKZwhnINyqCzEnUtNkRCTTW7BvOVroC0IJXgU3iWAfxc",
+	"PHuEDHqv6RbkFGprSduK1GaIp5dDdyVFM4USjp+NjkUAUWEN02Z4Eig+jIGWsKJdrbOzP57mWUO/sKZr",
+	"srPHp+YT4/bTozzT29a8z7iGtZvo3WqlYHImYZ8mp4phnyZhn+NuvSoD9Jbqqgfe+sd5JuHXjkkoszMt",
+	"O4inWwnZUG0B/+mHLDnPBaetqoR+i3CTc+GfffM4sEpLxtfZzc2Nf2iljhYVXGjqJFKKFqRmgJ/WwEHS",
+	"NFc/Ex3XClm3qCh3zG0+IjvlBGhREbEiG+Qxxq9pzUqqoSRwDXJLCjNxSSSortaGyQ7SI8c9dLgxDY06",
+	"xHX/ZvY8WuFNAEqlpFskcU+4j/GC+8k+hZfE8hcotIEStMCYaKwcE+uDoZJ/5YEivGuWIMmG6YpxwrQy",
+	"uipHcSOnZGn0WiGBKsbXRLGvsEhSQ0LBWikKh8Z4SlVRCWYPrM5RZAl6A8BJA2Z+s3tUEzPGgzL7M9iL",
+	"UnTLGvrpLeZmdoNXxF4RXlq0b+wEabRYqQxShlcaobTDDkqPVr7z9YpJhRwS9nwGq+zdZ1ZmbgEDbIck",
+	"3bvvO2eUUxxZLbprygz3ADfa42P0TU2XUH82zELXlsc+5bvCGc3wRlzDmLsMi6SpanXOAxWdjowjkYHK",
+	"moEkyimTNDfZ9w9JlFdIVgHa3U5xwQ7BHfDc4o8v7SXvOV0nFu9XdowKCCDHTJG74ybJxCKcHuNnsuOz",
+	"p33f8RE1zPv5YDV5OPncvHup877jY+LQmCOPtWDyXdoO+euvYkMayrckGoWsZUysleh4meYpo8Q0lE/1",
+	"QGjNKfBQswayBP+zcjB2WsAbUXY1lU75HVRYKQ0QW38RuN2t6VeR2pUdy89rAtHpSAvYT6gGlkJXScl/",
+	"UaZ4HpXfK65BUpzlqR5vzwfWgNeoVtiVJqx/xz4o10Doei1hTTWonGwq4OSKiw0favx9u3PFeHmIv8w6",
+	"/mbGGQGjRyHvDPd7QT0ccXXKDgZdgcTJNJVr0KSiilBup3bYKdoAMRQgS1pceXNHiU4W8Qm9FKIGirrR",
+	"Ppt7YuHEMwdvgK0rfRvOdziF+dymBpADSqUYPuzv2bfA387AQD12BeVnLWlx9VmsEForlB5+Z+QLuPnS",
+	"ejRGg6jP5lV8+Lmt6bZm5q20pKzFW9AbIa/G8oKOy+zTAWUucTBwUR4BJToMd+BooWm9R5+2INoa0ARz",
+	"xiBqVVqyTuWE8aLuSmME6kooIDWsNBHdxAGuZcfRgIsOrcCLO0xg15c7ank8YxjJnf/SCql/dAwXa7s1",
+	"fDH7OrZKVqwGYln0jLx88fOP5gN5CW3FcvLSeApvXuNXz7ZaqIK2kJPti5JQXhK3xz+7gdfsK3n+7kN+",
+	"ycsnxCzgYc34FfnXi3dvcyIkoeQrawmVRWXcarEizy5+wnEIDAXZ8JRaXMb2mcMcnZbGkKAUOsuzX6y5",
+	"UqjrNAciJS4K0cKQELSuszzpZbdUaq9JcDbCFAGEY21uj5EFAWsRHUR4KEm6qZPY/GgtZfnSGJpjw2Cl",
+	"re8/ZpklrISEA/aks8OlmrInc3JKGC5sSzYggTRMoeuC/gz7rvbmlF3pVpK71aa4F5no960w9kppak1R",
+	"XMdzoBIFo2MmtF+TStSlCv4gCgZf0zUYdRzcQ6uV/kIUa5i1iex7lzzS1C5EJEVdQ0m61u72hRnwrBZd",
+	"+UDZGBeUBDW/+gvxal1dcovHQNU7gB4JKrUZSmjbAjWc7tkPj+EAaijNYfE96lmehcFJ6XlNt6LTSZv6",
+	"Pu1XxguJZD9kkBgbW/B6a9ZcQOnPjIGQmXGthGsmOowlpM0Rpl10Q6XVQWDi/aeVR8ris0hHrkbGtmfm",
+	"CIkhEQ4Z2m9AS1YMmb2la5CUX43Y/SkpgGtJa8O8Db5pnJe2M4wmrh1pXSwW1fGCeGAoFlW3PKGdroTh",
+	"oUtuTmjzhnFM3/sxf3314YKoQkhQOYZNzX5QD9UZkLwUUuEah6zK+OcS1hIgyzPR6f5DtKaqW2Z5FtBI",
+	"su851dUF6DHz/r4sIRO0VPtOGgwL0VrwtQ0dmhdcSExpc3ZqYYg5iAPdPiB0lOb12Ke48jwcY+lYxfbY",
+	"aI3zgV7buNGOMMf6Y7aiaega/l3WiVhwnlnRmLmvb9zgGx98TgBsa3rwUD+vrT5qhWLec9473o8z70hh",
+	"bMqDr7hhaBfT4grD1TMJ1kmeXFqnQE6u+xokW7FZxjeqwwAs9xH8sE8RMEfPwSKmufBNv5k7JmDQIHMc",
+	"xjzzmvKYg8/oqnnQGX9udV068NbpfY9bp4BvFfTxE8ezRCDHCndAimnCp8OVt4wu2lP2t3M+dQXSuqC0",
+	"kEIpl58xVNmNtByM8iLms4KZjnCRBkilUWZI65eZXHf7MOGXzLydXIRTdv5Mfyu4gfkUTdfwz7kU6ZM7",
+	"WvzI/JMQnwY12qiR1UVolDEeUu5eCbKHFm4131cWHNBjLZD4xNgVixnR+94lSIfue7Tm8Xp/Zu0YCU4l",
+	"j1ijMJpepp8NWCX1nKlCrNXbqZPKRE2OoKfF/TXjVylibmCpmE5P5J59YLpODdihbOH1rV35cJ39RDtg",
+	"h8v1i9uzCbiQ0UYokNesSC9DT+BvDIX6WENhZ9F+XgtrYBXYaVMr2c1qj1ZTMf2eapipByqm59rQxueE",
+	"uYNtwcVBEthhDo0wRR4WkaLA+2Hmeydj4uNVaZViHxtMko+bTne0nuEFmzxOcPV4iQdoSFkvtztFPeOJ",
+	"0Ak+kLfX4zgchoUJMAwPhLKg/A6p/J396OmTR6TsCeMxT+2Lj9btcYfed65sZrzsyOVxRH6gBhlHDC4W",
+	"omWmKECKJjdhR7MlFS29ozSDMW8R1XG0nwibTPpCwZI7YDg5kQ/2U59O2R8S8fR+zlarhNVfllD+2GM+",
+	"S997kPa9lMqPwQa3akwVHHZ+nC2bqC5ITW6h7pm7iIsoblErYF5Lzb6KAu2TwQzUDptKkDVlHMpRnYtU",
+	"OVmy9RqUxiGJ+pZ9SA6C/SkcXYXIHDrbPPr1DIpKwHHfn5uGgA9j8N05agB3z/xazJ1rrFJD0UuCgZMY",
+	"pKQsTas8m3r53GuTIeWGoEdQs10uH0nTPkXk9nnqTJ5INfmnDxSpKC/rOJu+q4MBXs31DP069k4qZ00q",
+	"Z06aPEvx7QH+EW7993sJOyvMOE2CQ7bQxPPZTvjkEXiLWNq8aFlsmkTLSNHwQ9L3Oi48bmB8nxC5gfRW",
+	"lHCbiA3OhFaeCxZfM9i0QurvWiuQZ1+FaKInU+yNw/JbFhMEio6dsVA1M/bFQo1Mwtv0FTETxPNJz7Xo",
+	"c2Z2qgcqxMJEVAP0QM2IeY3LaRwiU2vGvT9WjlNFxa9K4JqtfCmerXRoROAAqvt0qdmppH6bl4XvT882",
+	"KnyfympgNcZUda9xaygp6k5pkDMNdl9qfDgtiURQmnJMcsucPDJ/CCUmVVrvd8h+q0BiqDwOtByzilk0",
+	"FJ2JQV+YPQDXISOuGDztdBV6AQr8qu8GuHhxcfHq3dvPr573KNGW/Q22tvKf8ZWt17VBjexCmD6HulPk",
+	"6fkrm3lQlryni0eLU7Ne0QKnLcvOsieL08UPWZTLwwyp3ZkabMDBMDWmdw2PZK/F2pY+SlCt4C548OT0",
+	"8Xg734P1ZkMfQS3WjGMY2uxXBbR059hr49y66FeinaKTbMzpN7h61TUNlVuLmDLqEWcyp8vCLNXpluEa",
+	"DL2NpBlVdvxK+oIIu6B7WMs5yJVoMNtMC4yfOdoZBgSlyTWjMR7vzIrI48XpAhkNN/GkoHVtyhpt30eC",
+	"DM/8gFttZiUauK+9/Cuab64XxWG5W43SLzpas29JmVzzT37Azpofn56O13wByogOYYogYOSoH04f7R3p",
+	"+mIW2XBJfmIV+POBIsq+5nYN+2dOlI8AJvF/CTqKE6YXUQiuwTo9tG1rZvfj5Be1uyl7Ped+FtycRPtL",
+	"JyVwbdt+SN9og7Ez5BCmbUGSjQCac8ImojBASGohrrDOyJH1h0RLEi0qrBNSpGSKLmsoFwNVmp19HCrR",
+	"j59uPsVkd01NldgQsdLAbSWC61SyeGywrENtQLoglGM7syrFeGHPIRPXBWkLFzwWJzsl9skde82UfhaN",
+	"ywdNkh/Tu9APSRf3H3zL9uTNGOh66m4+3ScvDZpAJtjJxAgx6opKxTCLYZ+IwjmpjUGmtA+vTHGNgRYa",
+	"AbDsmwubaduCPpJ/XmPFmo66vRgo2yDhQ8JRb6q1hPb1so4Z5+RbeOFVeXPS9H1WB9lp27c57fBUoqsw",
+	"mmVOc2FkHf8zsGiU9U/w59PAk3qn7y80uAlZogJZbgkrb8mbBorjLS6I6uLG6FvzrcNwlzfvzMK20Dni",
+	"0+FCz6M6eMm0Ub2mHt5MxyRxNT+5K+RTeTSlUQIuO+6zsnnwOS65Q3m3AVJX0CyIIS+sBVGFaIE4JlfE",
+	"2+V5OJv66XDoJY/Ew4LB4mwHyK5VDSZ2BHTT24PCQO+4ZnWY6JL3voxptuIcClc2OJRrW39uK5rT4rzT",
+	"7uwsqrnt2oNKfxTqFFBc7pEwbc08gpysBNyg/2p2htsWgN1S+RQyUc/1UT3WB+MFdEODj+lkOkatoVuy",
+	"hCmsbCtHus38cdTQ/uRQO/tBvRc1/N9aB+/pWg70z3urZ6gIdKyspugxPFf2nyNDbN7xut8TK05Y6090",
+	"xRTRrLHq4xCboOileWRPBnAWOl1rvZ1j0EH5Px6d4w430+Py/7809YSTtWScym1ilnwIxbbI3B3Q+Kw9",
+	"FsJX1h4PQMMXfXLNy8XadRMdCyJpiCIwYo8nW/FNtaZFhQXf9mA/ncjyQ+0OS6ZCNX8vo96mRSW730Sw",
+	"kLA1APWnkPGZLMCaDfCFqWMN2gstgTb2KNtUog63O9igWre0y0dUsTCBxh1f5ns7gHJab5URDCFq5WwC",
+	"e2yfDGq8Jq1YW5J1HsbOOvX2XCfy6PQ0vlDk0an9fDcd/JvYnnGd3WzvyGxgIDRh2pURDizRUIc/zW1v",
+	"hTe2jDF6Fydpc7jE0Z65Q2bRrB54zzuWt8ZIN/nqACNE1VKuyON/fDVrNH8QxoKc+5C/IEUtFKDyhrom",
+	"FFv1bPj8kiNAo8obkGsog10am8rWTO1ljgOVxrTnWvhQt83d5ESJS461Ogau62pSbY3eqx2prFHYh6wR",
+	"TQkNZTxlC74EJxyY55olFw3jP8+7m2Y6vD0J+T/uCTL98vO9Qb4nnF2CbBpyf6HRD4fuHEopfkyw2KSg",
+	"FkSC7iRfkL9XwEkjXFH3NVNsaU4nKxI2JmIeXEGrJy93mlacj49XnPepDpHpJ9SgS5dy31q4kzT9LdSc",
+	"7kuCDB7eYHSbYk9Mj8/OLQgWA6f+QsplKs57jgNG0j/pY2nh2qrwji/y3G6vGl5HRkw5LZRRRsZoQMZd",
+	"xiTNO67a5UgnLAVJi7teoDXp3NFag+T2BjakbS9AOVGVkDoKHabXeTVhWAyE43u6dfdqVbguwglJsiRC",
+	"Nyf3N3opAk2rt7ZFJOZzCWhvuvAFlNNm8FvHhGj5Ot+fKrJm18D76MuQ7VwYzLOmz+geJZs/MpMg1hX0",
+	"W11UlHGVuixLb8TQFOnLOiet1VBrtV8c4YsmDdWYXqCmFlBpH+wi0VBz44kterfazC3dF8WoKQ79Ndt3",
+	"D9yE0Lk6/OPfC4X7e19NeNG2dCXwj1mdYQjvS7c11UbUcwKL9YIYItG1pI3xQZaUlwVt2klvP9TXH4EU",
+	"9vf6Ku+tRcJGHnNSsXUVNEOOyAAtDdu4UO4EHkLOD765Rueb/61RceR4K/K9VB15rvZltgqCyCBglzNF",
+	"HWKcUbP8geCeKKCyqCadiDcOSuXSykbiSqbMJQIExa3XWcEXwO1Gr1iCgmZZm6dMX/ISVMs0EL1thVqQ",
+	"n1xjZ2wT+KUYWGi19RduYDc6spxMWf4XuJApXTOlEfZYo4y/Br7WVXxYxXLyT8CNOVka+fas5JOHRwVN",
+	"cFtARTrE8tKIlYZ8qUW791D5INpj9tqqrP/TOm6f/fZ6DW5ky2z7bXZ4ZNt7YB74ckvo+AaK4XZ/8xmL",
+	"m712vb/h5rg6gHBB7W9A+0mj0Ztm016WHYImXa+02bFlGy9Bq9AQ683GPm84RfaTUAK8n/gvwiUQt9uB",
+	"GQJlb0ceWSJmZrxxDt0Tbyf5e3DsjT54D/PQgbN1M+a9Sd/FXvnW7/LsO0L8zX6712f8XhwZmxPdy5EP",
+	"lIsHeAcjkXQlWtxaJxjS+JADJshjHyXFhmsxgwn7O+/uxIlHJDt/n5nNiShYX8CdDoMlrtcz/+NhHzzV",
+	"u8TF/ni6c0H5f2dULOKWg6LQ57L3Z5nsG3fLJ1khQf0keNgU6i/EjrzvSHD6SECyjGNKrKJO3j1+Ot6b",
+	"4kbeq4r/n1q/FBjF0rNvQ96tYbqTAWX6JQ91VB9UpDsXou9XqHEP+e/TuooxPCTF4Vclwiv3L8zvwdY5",
+	"Vf4QiWT2QXTJ4KgpHbfP3x65X0Avwqg7EvqobtGEgTMi/ws0svo71W/J/+ZgKQMY1UsdioXLtnPYDCtZ",
+	"W6FSJfoSqIawCOvqg9L/IsrtUcQa9ib5Pr2Wag3SrP0fH58+/E/68Ovpwz9/6v9dfH746dtp/qcnN39I",
+	"FmCMOt4TfS83u/GJm9G+P/puAtZvd1q6/K4QTa+A76/mMAsaFvSbwX9O5Kh7sLSWQMttcH/6kMARFeut",
+	"bwAbp5lyG1RK3aWQk46XIAmNJu1F8uSb+fZmX2fPc/w+4rXjNOjgh00SWnSq0sWTzqQJLGoHymfDG3dS",
+	"dXa1xskcCvyIZCelu4ohGVqMKvttmgTNHmN3ctiYI856bvhjIEq47oNQVSBWyet3LzlTLl4VMnX9S+ai",
+	"VROZQCPZlRT5ygW0h5kigkM+CEVW4pIDL+29rlAr2IQ7wiTYgjZCFcG+8QXBtnB8iNeUhhGYGMJDPVDp",
+	"kuMdHihPrnxvdMGHLSKGcqLWYXDnxd3YLulNpH4tY0+ac/7v7kz7Lv4HdMTKbjneMa8FXle9+2Mobov2",
+	"Zia/368o3ad5M9jIqa4h25ATeoEspXqHwBVAaNPsMyDftFJ4Ya/O+T56wZtAG1PRY5FzXUGUjzgpZLQE",
+	"R42LE8lrz7p4m1RWad2qs5OTWhS0roTSZ09OT09PaMtOrh8hyw7HKd/AuShEE4Z9uvmvAQADSXsqbG0A",
+	"AA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/cache"
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func main() {
+	interval := flag.Duration("interval", 0, "recompute on this schedule instead of once, e.g. 6h")
+	flag.Parse()
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	engine := graph.NewEngine(repo.NewGraphRepository(db))
+	metricsRepo := repo.NewMetricsRepository(db)
+	communitiesRepo := repo.NewCommunitiesRepository(db)
+
+	if err := analyze(ctx, e.DB.RedisURI, engine, metricsRepo, communitiesRepo); err != nil {
+		os.Exit(1)
+	}
+	if *interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A failed run is retried on the next tick
+			analyze(ctx, e.DB.RedisURI, engine, metricsRepo, communitiesRepo)
+		}
+	}
+}
+
+// labelPropagationIterations bounds the passes of label propagation, which usually settles
+// within a few dozen.
+const labelPropagationIterations = 100
+
+// analyze loads the follow graph, and stores the centrality metrics of everyone in it and
+// a run of every community detection algorithm, then invalidates the queries servers cached.
+func analyze(ctx context.Context, redisURI string, engine *graph.Engine, metricsRepo *repo.MetricsRepository, communitiesRepo *repo.CommunitiesRepository) error {
+	if err := engine.Load(ctx); err != nil {
+		return err
+	}
+	g := engine.Graph()
+
+	start := time.Now()
+	metrics := graph.Centrality(g, graph.DefaultCentralityOptions)
+	slog.Info("Computed centrality", "people", len(metrics), "duration", time.Since(start))
+
+	if err := metricsRepo.ReplaceMetrics(ctx, metrics, start); err != nil {
+		slog.Error("failed to store metrics", "error", err)
+		return err
+	}
+	slog.Info("Stored metrics", "people", len(metrics))
+
+	detectors := []struct {
+		algorithm repo.CommunityAlgorithm
+		detect    func(*graph.CSR) graph.Communities
+	}{
+		{repo.CommunityAlgorithmLouvain, graph.Louvain},
+		{repo.CommunityAlgorithmLabelPropagation, func(c *graph.CSR) graph.Communities {
+			return graph.LabelPropagation(c, labelPropagationIterations)
+		}},
+	}
+	for _, d := range detectors {
+		algorithm := d.algorithm
+		start := time.Now()
+		communities := d.detect(g)
+		slog.Info("Detected communities", "algorithm", algorithm, "communities", len(communities.Sizes), "modularity", communities.Modularity, "duration", time.Since(start))
+
+		run, err := communitiesRepo.CreateRun(ctx, repo.CommunityRun{Algorithm: algorithm, Modularity: communities.Modularity}, communities.Summaries(g), communities.Membership)
+		if err != nil {
+			slog.Error("failed to store communities", "algorithm", algorithm, "error", err)
+			return err
+		}
+		slog.Info("Stored communities", "algorithm", algorithm, "run", run.Id)
+	}
+
+	cache.NotifyChanged(ctx, redisURI)
+	return nil
+}
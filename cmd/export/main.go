@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/export"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func main() {
+	format := flag.String("format", string(export.FormatGEXF), "file format: gexf, graphml, dot, json or csv")
+	out := flag.String("out", "", "file to write to, standard output when empty")
+	scope := flag.String("scope", string(graph.ExportScopeAll), "part of the graph to export: all, ego, community or crawl")
+	personID := flag.Int64("person", 0, "person whose ego network is exported")
+	radius := flag.Int("radius", 2, "how many follows away people of the ego network may be")
+	direction := flag.String("direction", string(repo.DirectionOut), "direction of the follows the ego network follows: out, in or both")
+	algorithm := flag.String("algorithm", string(repo.CommunityAlgorithmLouvain), "community detection algorithm whose latest run is read")
+	communityID := flag.Int("community", 0, "community exported")
+	since := flag.String("since", "", "RFC 3339 time after which crawled follows are exported")
+	until := flag.String("until", "", "RFC 3339 time up to which crawled follows are exported")
+	flag.Parse()
+
+	f := export.Format(*format)
+	if !f.Valid() {
+		slog.Error("invalid format", "format", *format)
+		os.Exit(2)
+	}
+	sel := graph.ExportSelection{
+		Scope:       graph.ExportScope(*scope),
+		PersonID:    *personID,
+		Radius:      *radius,
+		Direction:   repo.Direction(*direction),
+		Algorithm:   repo.CommunityAlgorithm(*algorithm),
+		CommunityID: *communityID,
+	}
+	if !sel.Scope.Valid() {
+		slog.Error("invalid scope", "scope", *scope)
+		os.Exit(2)
+	}
+	var err error
+	if sel.Since, err = parseTime(*since); err != nil {
+		slog.Error("invalid since", "error", err)
+		os.Exit(2)
+	}
+	if sel.Until, err = parseTime(*until); err != nil {
+		slog.Error("invalid until", "error", err)
+		os.Exit(2)
+	}
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	graphRepo := repo.NewGraphRepository(db)
+	engine := graph.NewEngine(graphRepo)
+	if sel.Scope != graph.ExportScopeCrawl {
+		if err := engine.Load(ctx); err != nil {
+			os.Exit(1)
+		}
+	}
+	gc := graph.NewGraphController(
+		graphRepo,
+		engine,
+		repo.NewPeopleRepository(db),
+		repo.NewEdgesRepository(db),
+		repo.NewProfilesRepository(db),
+		repo.NewMetricsRepository(db),
+		repo.NewCommunitiesRepository(db),
+		repo.NewLayoutsRepository(db),
+	)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			slog.Error("failed to create output file", "error", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	enc, err := export.NewEncoder(w, f)
+	if err != nil {
+		slog.Error("failed to create encoder", "error", err)
+		os.Exit(1)
+	}
+
+	start := time.Now()
+	found, err := gc.Export(ctx, sel, enc)
+	if err != nil {
+		slog.Error("failed to export graph", "error", err)
+		os.Exit(1)
+	}
+	if !found {
+		slog.Error("person or community not found")
+		os.Exit(1)
+	}
+	if err := enc.Close(); err != nil {
+		slog.Error("failed to write export", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Exported graph", "format", f, "scope", sel.Scope, "duration", time.Since(start))
+}
+
+// parseTime parses an RFC 3339 time, where empty is the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
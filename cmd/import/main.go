@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/cache"
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/importer"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "validate the files and report what would be imported, without storing anything")
+	maxProblems := flag.Int("problems", 100, "how many problems to list, all when negative")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file...\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Imports people and edges from .csv node or edge lists, .jsonl records, .json node-link graphs and .zip archives of those.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ds := importer.NewDataset()
+	for _, name := range flag.Args() {
+		if err := ds.ReadFile(name); err != nil {
+			slog.Error("failed to read file", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	im := importer.NewImporter(repo.NewGraphRepository(db), repo.NewPeopleRepository(db))
+	report, err := im.Import(ctx, ds, *dryRun)
+	if err != nil {
+		slog.Error("failed to import", "error", err)
+		os.Exit(1)
+	}
+
+	for i, p := range report.Problems {
+		if *maxProblems >= 0 && i >= *maxProblems {
+			slog.Warn("More problems not listed", "count", len(report.Problems)-i)
+			break
+		}
+		slog.Warn(p.String())
+	}
+
+	msg := "Imported"
+	if *dryRun {
+		msg = "Dry run, nothing stored"
+	}
+	slog.Info(
+		msg,
+		"nodeRecords", report.NodeRecords,
+		"edgeRecords", report.EdgeRecords,
+		"people", report.People,
+		"placeholders", report.Placeholders,
+		"created", report.Created,
+		"updated", report.Updated,
+		"duplicatePeople", report.DuplicatePeople,
+		"follows", report.Follows,
+		"engagement", report.Engagement,
+		"duplicateEdges", report.DuplicateEdges,
+		"invalid", report.Invalid,
+		"duration", time.Since(start),
+	)
+
+	if !*dryRun {
+		cache.NotifyChanged(ctx, e.DB.RedisURI)
+	}
+}
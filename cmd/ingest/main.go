@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/ingest"
+	"lopa.to/sonimulus/internal/cache"
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func main() {
+	mode := flag.String("mode", string(ingest.ModeEngagement), "kind of data to ingest (engagement, comments, related, profiles, playlists)")
+	workers := flag.Int("workers", 4, "number of concurrent workers")
+	limit := flag.Int("limit", 200, "max items fetched per SoundCloud collection")
+	flag.Parse()
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		return
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		return
+	}
+
+	scc, err := data.NewSoundCloudClient(e.Soundcloud.APIURL)
+	if err != nil {
+		slog.Error("failed to initialize soundcloud client", "error", err)
+		return
+	}
+
+	// The ingester acts as the application itself, so it authenticates with client credentials
+	ctx := context.Background()
+	tokens := (&clientcredentials.Config{
+		ClientID:     e.Soundcloud.ClientID,
+		ClientSecret: e.Soundcloud.ClientSecret,
+		TokenURL:     e.Soundcloud.TokenURL,
+	}).TokenSource(ctx)
+
+	ingester := ingest.NewIngester(
+		repo.NewSoundCloudRepository(scc, e),
+		repo.NewPeopleRepository(db),
+		repo.NewEdgesRepository(db),
+		repo.NewCommentsRepository(db),
+		repo.NewTracksRepository(db),
+		repo.NewProfilesRepository(db),
+		repo.NewPlaylistsRepository(db),
+		tokens,
+		*limit,
+	)
+
+	if err := ingester.Run(ctx, ingest.Mode(*mode), *workers); err != nil {
+		slog.Error("ingestion failed", "error", err)
+	}
+
+	cache.NotifyChanged(ctx, e.DB.RedisURI)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/cache"
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func main() {
+	iterations := flag.Int("iterations", graph.DefaultLayoutOptions.Iterations, "how many layout iterations to run")
+	incremental := flag.Bool("incremental", false, "keep everyone placed by the latest layout in place, and only place newcomers")
+	seed := flag.Uint64("seed", graph.DefaultLayoutOptions.Seed, "seed for the random initial placement")
+	flag.Parse()
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	engine := graph.NewEngine(repo.NewGraphRepository(db))
+	if err := engine.Load(ctx); err != nil {
+		os.Exit(1)
+	}
+	layoutsRepo := repo.NewLayoutsRepository(db)
+
+	opts := graph.DefaultLayoutOptions
+	opts.Iterations = *iterations
+	opts.Seed = *seed
+	if *incremental {
+		initial, err := latestPositions(ctx, layoutsRepo)
+		if err != nil {
+			os.Exit(1)
+		}
+		opts.Initial, opts.Pinned = initial, true
+		slog.Info("Placing newcomers", "placed", len(initial))
+	}
+
+	start := time.Now()
+	positions := graph.Layout(engine.Graph(), opts)
+	slog.Info("Computed layout", "people", len(positions), "iterations", opts.Iterations, "duration", time.Since(start))
+
+	run, err := layoutsRepo.CreateRun(ctx, repo.LayoutRun{Iterations: opts.Iterations, Incremental: *incremental}, positions)
+	if err != nil {
+		slog.Error("failed to store layout", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Stored layout", "run", run.Id, "people", run.Nodes)
+
+	cache.NotifyChanged(ctx, e.DB.RedisURI)
+}
+
+// latestPositions returns the positions placed by the latest layout run, by person id.
+// There are none before the first run.
+func latestPositions(ctx context.Context, layoutsRepo *repo.LayoutsRepository) (map[int64]repo.Position, error) {
+	run, found, err := layoutsRepo.LatestRun(ctx)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	positions, err := layoutsRepo.ListPositions(ctx, run.Id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]repo.Position, len(positions))
+	for _, p := range positions {
+		byID[p.PersonID] = p
+	}
+	return byID, nil
+}
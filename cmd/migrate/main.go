@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/data"
+)
+
+func main() {
+	steps := flag.Int("steps", 1, "number of migrations to revert with down")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: migrate [flags] up|down|status")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "up":
+		applied, err := data.MigrateUp(ctx, db)
+		if err != nil {
+			slog.Error("failed to apply migrations", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Applied migrations", "count", applied)
+	case "down":
+		reverted, err := data.MigrateDown(ctx, db, *steps)
+		if err != nil {
+			slog.Error("failed to revert migrations", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Reverted migrations", "count", reverted)
+	case "status":
+		statuses, err := data.MigrationsStatus(ctx, db)
+		if err != nil {
+			slog.Error("failed to read migration status", "error", err)
+			os.Exit(1)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d %-20s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
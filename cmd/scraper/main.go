@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log/slog"
+	"time"
 
 	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/cache"
 	"lopa.to/sonimulus/internal/data"
 	"lopa.to/sonimulus/internal/repo"
 	"lopa.to/sonimulus/scraper"
@@ -13,6 +16,8 @@ import (
 func main() {
 	rootHandle := flag.String("handle", "dxmfromcvs", "root user to perform bfs from")
 	depth := flag.Int("depth", 0, "max bfs depth")
+	batchSize := flag.Int("batch", 5000, "number of follows stored at once")
+	flushInterval := flag.Duration("flush", 5*time.Second, "max time follows wait before being stored")
 	flag.Parse()
 
 	// Load config struct from environment variables and program arguments
@@ -22,35 +27,29 @@ func main() {
 		return
 	}
 
-	scraper := scraper.NewScraper(*depth, e)
-	db, err := data.NewPostgresDB(e.DB.PostgresURI)
+	s := scraper.NewScraper(*depth, e)
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
 	if err != nil {
 		slog.Error("failed to initialize database connection", "error", err)
 		return
 	}
-	repo := repo.NewPeopleRepository(db)
+	peopleRepo := repo.NewPeopleRepository(db)
 
+	ctx := context.Background()
 	user := *rootHandle
 
-	onPerson := func(handle, name, imageUrl string, verified bool, plan repository.Plan, trackCount int64) (id int64) {
-		person, err := repo.CreatePerson(handle, name, imageUrl, verified, plan, trackCount)
+	onPerson := func(handle, name, imageUrl string, verified bool, plan repo.Plan, trackCount int64) (id int64) {
+		person, err := peopleRepo.Create(ctx, handle, name, imageUrl, verified, plan, trackCount)
 		if err != nil {
 			slog.Error("error creating person in people table", "error", err)
 			return -1
 		}
-		if person == nil {
-			slog.Error("person empty")
-			return -1
-		}
 		return person.Id
 	}
 
-	onFollows := func(followerId int64, followeeHandles []string) {
-		err := repo.CreateFollows(followerId, followeeHandles)
-		if err != nil {
-			slog.Error("error creating follows", "error", err)
-		}
-	}
+	sink := scraper.NewFollowSink(ctx, peopleRepo, max(*batchSize, 1), max(*flushInterval, time.Millisecond))
+	s.ScrapePeopleConcurrent(10, user, onPerson, sink.Add)
+	sink.Close()
 
-	scraper.ScrapePeopleConcurrent(10, user, onPerson, onFollows)
+	cache.NotifyChanged(ctx, e.DB.RedisURI)
 }
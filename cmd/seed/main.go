@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/cache"
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/internal/synth"
+)
+
+func main() {
+	people := flag.Int("people", synth.DefaultOptions.People, "how many people to generate")
+	communities := flag.Int("communities", synth.DefaultOptions.Communities, "how many communities to split people into")
+	follows := flag.Float64("follows", synth.DefaultOptions.FollowsPerPerson, "how many people each person follows on average")
+	mixing := flag.Float64("mixing", synth.DefaultOptions.Mixing, "share of follows made outside of the follower's community")
+	reciprocity := flag.Float64("reciprocity", synth.DefaultOptions.Reciprocity, "chance that a follow is returned")
+	seed := flag.Uint64("seed", synth.DefaultOptions.Seed, "seed for every random choice, the same seed generating the same network")
+	batchSize := flag.Int("batch", 5000, "number of people or follows stored at once")
+	flag.Parse()
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	net := synth.Generate(synth.Options{
+		People:           *people,
+		Communities:      *communities,
+		FollowsPerPerson: *follows,
+		Mixing:           *mixing,
+		Reciprocity:      *reciprocity,
+		Seed:             *seed,
+	})
+	slog.Info("Generated network", "people", len(net.People), "follows", len(net.Follows), "duration", time.Since(start))
+
+	if err := store(ctx, repo.NewPeopleRepository(db), repo.NewGraphRepository(db), net, max(*batchSize, 1)); err != nil {
+		os.Exit(1)
+	}
+	slog.Info("Stored network", "people", len(net.People), "follows", len(net.Follows), "duration", time.Since(start))
+
+	cache.NotifyChanged(ctx, e.DB.RedisURI)
+}
+
+// store upserts the people of a network, then the follows between them. Generated people
+// whose handle is taken by a crawled person are left out, along with their follows, so
+// that seeding never overwrites crawled data.
+func store(ctx context.Context, peopleRepo *repo.PeopleRepository, graphRepo *repo.GraphRepository, net synth.Network, batchSize int) error {
+	ids := make(map[string]int64, len(net.People))
+	var skipped int
+	for batch := range slices.Chunk(net.People, batchSize) {
+		handles := make([]string, 0, len(batch))
+		for _, p := range batch {
+			handles = append(handles, p.Username)
+		}
+		existing, err := peopleRepo.FindPeopleByUsernamesOrUrns(ctx, handles, nil)
+		if err != nil {
+			slog.Error("failed to find stored people", "error", err)
+			return err
+		}
+		crawled := make(map[string]bool)
+		for _, p := range existing {
+			if !synth.IsSynthetic(p) {
+				crawled[p.Username] = true
+			}
+		}
+		if len(crawled) > 0 {
+			batch = slices.DeleteFunc(slices.Clone(batch), func(p repo.Person) bool { return crawled[p.Username] })
+			skipped += len(crawled)
+		}
+
+		stored, err := graphRepo.UpsertNodes(ctx, batch)
+		if err != nil {
+			slog.Error("failed to store people", "error", err)
+			return err
+		}
+		for _, p := range stored {
+			ids[p.Username] = p.Id
+		}
+	}
+	if skipped > 0 {
+		slog.Warn("left out people whose handle is taken by a crawled person", "count", skipped)
+	}
+
+	for batch := range slices.Chunk(net.Follows, batchSize) {
+		edges := make([]repo.Edge, 0, len(batch))
+		for _, f := range batch {
+			source, ok := ids[net.People[f[0]].Username]
+			if !ok {
+				continue
+			}
+			target, ok := ids[net.People[f[1]].Username]
+			if !ok {
+				continue
+			}
+			edges = append(edges, repo.Edge{SourceID: source, TargetID: target, Kind: repo.EdgeKindFollows, Weight: 1})
+		}
+		if err := graphRepo.UpsertEdges(ctx, edges); err != nil {
+			slog.Error("failed to store follows", "error", err)
+			return err
+		}
+	}
+	return nil
+}
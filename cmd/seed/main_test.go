@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/internal/synth"
+)
+
+func TestStoreTwice(t *testing.T) {
+	ctx := context.Background()
+	db, err := data.NewSQLiteDB(filepath.Join(t.TempDir(), "sonimulus.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := data.MigrateUp(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	peopleRepo, graphRepo := repo.NewPeopleRepository(db), repo.NewGraphRepository(db)
+
+	first := synth.Generate(synth.Options{People: 300, Communities: 4, FollowsPerPerson: 5, Mixing: 0.1, Reciprocity: 0.25, Seed: 1})
+	second := synth.Generate(synth.Options{People: 500, Communities: 6, FollowsPerPerson: 5, Mixing: 0.1, Reciprocity: 0.25, Seed: 2})
+
+	// A crawled person sharing a generated handle keeps their own data
+	taken := first.People[0].Username
+	crawled, err := peopleRepo.Upsert(ctx, repo.Person{Username: taken, Urn: "soundcloud:users:42", Name: "Crawled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store(ctx, peopleRepo, graphRepo, first, 100); err != nil {
+		t.Fatalf("storing the first network: %v", err)
+	}
+	// The networks share handles, at other indexes
+	if err := store(ctx, peopleRepo, graphRepo, second, 100); err != nil {
+		t.Fatalf("storing the second network: %v", err)
+	}
+
+	person, found, err := peopleRepo.FindPersonByUsername(ctx, taken)
+	if err != nil || !found {
+		t.Fatalf("finding %s: found = %v, err = %v", taken, found, err)
+	}
+	if person.Urn != crawled.Urn || person.Name != crawled.Name {
+		t.Errorf("got %+v, want the crawled person %+v untouched", person, crawled)
+	}
+	if degree, err := graphRepo.Degree(ctx, person.Id, repo.DirectionBoth, []repo.EdgeKind{repo.EdgeKindFollows}); err != nil || degree != 0 {
+		t.Errorf("crawled person got %d generated follows, err = %v", degree, err)
+	}
+
+	people, err := peopleRepo.FindPeopleByUsernamesOrUrns(ctx, []string{second.People[0].Username}, nil)
+	if err != nil || len(people) != 1 || people[0].Urn != second.People[0].Urn {
+		t.Errorf("got %+v, err = %v, want %+v", people, err, second.People[0])
+	}
+}
@@ -1,19 +1,28 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 
 	"lopa.to/sonimulus/api/v1"
 	"lopa.to/sonimulus/env"
 	"lopa.to/sonimulus/handlers"
 	"lopa.to/sonimulus/internal/auth"
+	"lopa.to/sonimulus/internal/cache"
 	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/graph"
 	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/internal/snapshot"
 )
 
 func main() {
@@ -24,27 +33,57 @@ func main() {
 		return
 	}
 
-	// Initialize PostgreSQL connection
-	pgdb, err := data.NewPostgresDB(e.DB.PostgresURI)
+	// Initialize the database connection, to Postgres or an SQLite file
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
 	if err != nil {
 		slog.Error("failed to initialize database", "error", err)
 		return
 	}
 
-	// Initialize Redis connection
-	rdb, err := data.NewRedisClient(e.DB.RedisURI)
+	// Bring the schema up to date before serving requests
+	if e.DB.MigrateOnStart {
+		applied, err := data.MigrateUp(context.Background(), db)
+		if err != nil {
+			slog.Error("failed to apply migrations", "error", err)
+			return
+		}
+		slog.Info("Applied migrations", "count", applied)
+	}
+
+	// Redis is optional, for storing sessions and caching queries
+	var rdb *redis.Client
+	if e.DB.RedisURI != "" {
+		rdb, err = data.NewRedisClient(e.DB.RedisURI)
+		if err != nil {
+			slog.Error("failed to initialize redis client", "error", err)
+			return
+		}
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	kv, err := newKVStore(ctx, e.DB.SessionStore, db, rdb)
 	if err != nil {
-		slog.Error("failed to initialize redis client", "error", err)
+		slog.Error("failed to initialize session store", "error", err)
 		return
 	}
 
 	scc, err := data.NewSoundCloudClient(e.Soundcloud.APIURL)
 
 	// Initialize data repositories
-	stateRepo := repo.NewStateRepository(rdb)
-	sessionRepo := repo.NewSessionRepository(rdb)
+	stateRepo := repo.NewStateRepository(kv)
+	sessionRepo := repo.NewSessionRepository(kv)
 	soundCloudRepo := repo.NewSoundCloudRepository(scc, e)
-	usersRepo := repo.NewUsersRepository(pgdb)
+	usersRepo := repo.NewUsersRepository(db)
+	peopleRepo := repo.NewPeopleRepository(db)
+	edgesRepo := repo.NewEdgesRepository(db)
+	profilesRepo := repo.NewProfilesRepository(db)
+	graphRepo := repo.NewGraphRepository(db)
+	metricsRepo := repo.NewMetricsRepository(db)
+	communitiesRepo := repo.NewCommunitiesRepository(db)
+	layoutsRepo := repo.NewLayoutsRepository(db)
+	snapshotsRepo := repo.NewSnapshotsRepository(db)
 
 	// Initialize server
 	authController := auth.NewAuthController(
@@ -55,10 +94,24 @@ func main() {
 		usersRepo,
 	)
 
-	baseHandler := handlers.NewHandler(authController, e)
+	// Keep the follow graph in memory for traversals, refreshing it in the background
+	engine := graph.NewEngine(graphRepo)
+	go engine.Run(ctx, e.Graph.RefreshInterval, e.Graph.ReloadInterval)
+
+	graphController := graph.NewGraphController(graphRepo, engine, peopleRepo, edgesRepo, profilesRepo, metricsRepo, communitiesRepo, layoutsRepo)
+
+	// Answer repeated queries from Redis until a command changes the graph
+	var graphHandler handlers.GraphController = graphController
+	if e.Cache.Enabled && rdb != nil {
+		graphHandler = graph.NewCachedGraphController(graphController, cache.NewCache(rdb, e.Cache.TTL))
+	}
+
+	snapshotController := snapshot.NewSnapshotController(snapshotsRepo)
+
+	baseHandler := handlers.NewHandler(authController, graphHandler, snapshotController, e)
 	apiHandler := api.HandlerWithOptions(baseHandler, api.StdHTTPServerOptions{
 		BaseURL:     e.Server.Route,
-		Middlewares: []api.MiddlewareFunc{handlers.CorsMiddleware},
+		Middlewares: []api.MiddlewareFunc{baseHandler.AuthMiddleware, handlers.CorsMiddleware},
 	})
 	server := http.Server{Addr: fmt.Sprintf(":%d", e.Server.Port), Handler: apiHandler}
 
@@ -79,3 +132,39 @@ func main() {
 		slog.Info("Server closed", "error", err)
 	}
 }
+
+// kvEvictInterval is how often expired sessions and states are deleted from stores that do
+// not expire them themselves.
+const kvEvictInterval = time.Minute
+
+// newKVStore opens the store sessions and OAuth states are kept in, and evicts their expired
+// values in the background until ctx is done.
+func newKVStore(ctx context.Context, store string, db *sql.DB, rdb *redis.Client) (repo.KVStore, error) {
+	switch store {
+	case "redis":
+		if rdb == nil {
+			return nil, errors.New("redis session store requires REDIS_URI")
+		}
+		return repo.NewRedisKV(rdb), nil
+	case "postgres":
+		if data.DialectOf(db) != data.DialectPostgres {
+			return nil, errors.New("postgres session store requires DB_DRIVER=postgres")
+		}
+		kv := repo.NewPostgresKV(db)
+		go kv.Run(ctx, kvEvictInterval)
+		return kv, nil
+	case "sqlite":
+		if data.DialectOf(db) != data.DialectSQLite {
+			return nil, errors.New("sqlite session store requires DB_DRIVER=sqlite")
+		}
+		kv := repo.NewSQLiteKV(db)
+		go kv.Run(ctx, kvEvictInterval)
+		return kv, nil
+	case "memory":
+		kv := repo.NewMemoryKV()
+		go kv.Run(ctx, kvEvictInterval)
+		return kv, nil
+	default:
+		return nil, fmt.Errorf("unknown session store %q, want redis, postgres, sqlite or memory", store)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/internal/snapshot"
+)
+
+func main() {
+	limit := flag.Int("limit", 20, "how many changes of each kind diff lists")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: snapshot [flags] create <name> | list | delete <name> | diff <from> <to>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := map[string]int{"create": 2, "list": 1, "delete": 2, "diff": 3}
+	if want, ok := args[flag.Arg(0)]; !ok || flag.NArg() != want {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load config struct from environment variables and program arguments
+	e, err := env.NewEnv()
+	if err != nil {
+		slog.Error("failed to initialize config", "error", err)
+		os.Exit(1)
+	}
+
+	db, err := data.NewDB(e.DB.Driver, e.DB.PostgresURI, e.DB.SQLitePath)
+	if err != nil {
+		slog.Error("failed to initialize database connection", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sc := snapshot.NewSnapshotController(repo.NewSnapshotsRepository(db))
+	switch flag.Arg(0) {
+	case "create":
+		name := flag.Arg(1)
+		if !snapshot.ValidName(name) {
+			slog.Error("invalid snapshot name, want letters, digits, dots, dashes and underscores", "name", name)
+			os.Exit(2)
+		}
+		s, err := sc.Create(ctx, name)
+		if errors.Is(err, repo.ErrSnapshotExists) {
+			slog.Error("snapshot already exists", "name", name)
+			os.Exit(1)
+		}
+		if err != nil {
+			slog.Error("failed to create snapshot", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Created snapshot", "name", s.Name, "people", s.People, "follows", s.Follows)
+	case "list":
+		snapshots, err := sc.List(ctx)
+		if err != nil {
+			slog.Error("failed to list snapshots", "error", err)
+			os.Exit(1)
+		}
+		for _, s := range snapshots {
+			fmt.Printf("%-24s %s %10d people %10d follows\n", s.Name, s.CreatedAt.Format("2006-01-02 15:04:05"), s.People, s.Follows)
+		}
+	case "delete":
+		found, err := sc.Delete(ctx, flag.Arg(1))
+		if err != nil {
+			slog.Error("failed to delete snapshot", "error", err)
+			os.Exit(1)
+		}
+		if !found {
+			slog.Error("snapshot not found", "name", flag.Arg(1))
+			os.Exit(1)
+		}
+		slog.Info("Deleted snapshot", "name", flag.Arg(1))
+	case "diff":
+		diff, found, err := sc.Diff(ctx, flag.Arg(1), flag.Arg(2), *limit)
+		if err != nil {
+			slog.Error("failed to compare snapshots", "error", err)
+			os.Exit(1)
+		}
+		if !found {
+			slog.Error("snapshot not found", "from", flag.Arg(1), "to", flag.Arg(2))
+			os.Exit(1)
+		}
+		printDiff(diff)
+	}
+}
+
+// printDiff prints a diff as sections of aligned lines.
+func printDiff(diff snapshot.Diff) {
+	fmt.Printf("%s (%s) -> %s (%s)\n", diff.From.Name, diff.From.CreatedAt.Format("2006-01-02 15:04"), diff.To.Name, diff.To.CreatedAt.Format("2006-01-02 15:04"))
+	fmt.Printf("people  %+d -%d  (%d -> %d)\n", diff.Counts.AddedPeople, diff.Counts.RemovedPeople, diff.From.People, diff.To.People)
+	fmt.Printf("follows %+d -%d  (%d -> %d)\n", diff.Counts.AddedFollows, diff.Counts.RemovedFollows, diff.From.Follows, diff.To.Follows)
+
+	section := func(title string, total, listed int) {
+		if listed > 0 {
+			fmt.Printf("\n%s (%d of %d)\n", title, listed, total)
+		}
+	}
+	section("Added people", diff.Counts.AddedPeople, len(diff.AddedPeople))
+	for _, p := range diff.AddedPeople {
+		fmt.Printf("  + @%-30s %8d followers\n", p.Username, p.Followers)
+	}
+	section("Removed people", diff.Counts.RemovedPeople, len(diff.RemovedPeople))
+	for _, p := range diff.RemovedPeople {
+		fmt.Printf("  - @%-30s %8d followers\n", p.Username, p.Followers)
+	}
+	section("Added follows", diff.Counts.AddedFollows, len(diff.AddedFollows))
+	for _, f := range diff.AddedFollows {
+		fmt.Printf("  + @%s -> @%s\n", f.FollowerUsername, f.FolloweeUsername)
+	}
+	section("Removed follows", diff.Counts.RemovedFollows, len(diff.RemovedFollows))
+	for _, f := range diff.RemovedFollows {
+		fmt.Printf("  - @%s -> @%s\n", f.FollowerUsername, f.FolloweeUsername)
+	}
+	if len(diff.FollowerGains) > 0 {
+		fmt.Println("\nBiggest follower gains")
+	}
+	for _, g := range diff.FollowerGains {
+		fmt.Printf("  @%-30s %8d -> %-8d %+d\n", g.Person.Username, g.Before, g.After, g.After-g.Before)
+	}
+	if diff.From.CommunityRunID == nil || diff.To.CommunityRunID == nil {
+		fmt.Println("\nCommunity moves are unknown, as a snapshot was taken before communities were detected")
+		return
+	}
+	section("Community moves", diff.MovedPeople, len(diff.CommunityMoves))
+	for _, m := range diff.CommunityMoves {
+		fmt.Printf("  @%-30s %4d -> %d\n", m.Person.Username, m.From, m.To)
+	}
+}
@@ -5,6 +5,7 @@ package env
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go-simpler.org/env"
@@ -31,9 +32,26 @@ type Env struct {
 		TokenURL     string `env:"TOKEN_URL"`
 	} `env:"SOUNDCLOUD_"`
 	DB struct {
-		PostgresURI string `env:"POSTGRES_URI"`
-		RedisURI    string `env:"REDIS_URI"`
+		// Driver is the database people, follows and users are stored in: postgres, or
+		// sqlite, which the analysis commands do not support.
+		Driver         string `env:"DB_DRIVER" default:"postgres"`
+		PostgresURI    string `env:"POSTGRES_URI"`
+		SQLitePath     string `env:"SQLITE_PATH" default:"sonimulus.db"`
+		RedisURI       string `env:"REDIS_URI"`
+		MigrateOnStart bool   `env:"MIGRATE_ON_START" default:"false"`
+		// SessionStore is where sessions and OAuth states are kept: redis, postgres, sqlite,
+		// or memory, which loses them on restart. Without REDIS_URI, queries are not cached
+		// either.
+		SessionStore string `env:"SESSION_STORE" default:"redis"`
 	}
+	Graph struct {
+		RefreshInterval time.Duration `env:"REFRESH_INTERVAL" default:"1m"`
+		ReloadInterval  time.Duration `env:"RELOAD_INTERVAL" default:"1h"`
+	} `env:"GRAPH_"`
+	Cache struct {
+		Enabled bool          `env:"ENABLED" default:"true"`
+		TTL     time.Duration `env:"TTL" default:"1h"`
+	} `env:"CACHE_"`
 }
 
 // NewEnv initializes a new Env instance, drawing from environment variables.
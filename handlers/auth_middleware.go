@@ -5,10 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
 )
 
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only operations declaring CookieAuth security require a session
+		if r.Context().Value(api.CookieAuthScopes) == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		sessionID, err := r.Cookie("SESSION_ID")
 		if err != nil {
 			slog.Error("Failed to get session id cookie", "error", err)
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
+	"lopa.to/sonimulus/internal/cache"
+)
+
+// CachingGraphController is a GraphController that answers queries from a cache.
+type CachingGraphController interface {
+	CacheStats(ctx context.Context) (generation int64, stats []cache.QueryStats, err error)
+}
+
+func (h *Handler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	cc, ok := h.graph.(CachingGraphController)
+	if !ok {
+		http.Error(w, "caching is disabled", http.StatusNotFound)
+		return
+	}
+
+	generation, stats, err := cc.CacheStats(r.Context())
+	if err != nil {
+		slog.Error("reading cache stats", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	res := api.CacheStats{Generation: generation, Queries: make([]api.QueryCacheStats, 0, len(stats))}
+	for _, s := range stats {
+		res.Queries = append(res.Queries, api.QueryCacheStats{Query: s.Query, Hits: s.Hits, Misses: s.Misses, HitRate: s.HitRate()})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func (h *Handler) ListCommunities(w http.ResponseWriter, r *http.Request, params api.ListCommunitiesParams) {
+	limit, offset := page(params.Limit, params.Offset)
+	algorithm := communityAlgorithm(params.Algorithm)
+
+	run, communities, found, err := h.graph.Communities(r.Context(), algorithm, limit, offset)
+	if err != nil {
+		slog.Error("listing communities", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "no community detection run", http.StatusNotFound)
+		return
+	}
+
+	res := api.CommunityPage{
+		Run: api.CommunityRun{
+			Id:          run.Id,
+			Algorithm:   api.CommunityAlgorithm(run.Algorithm),
+			Modularity:  run.Modularity,
+			Communities: run.Communities,
+			CreatedAt:   run.CreatedAt,
+		},
+		Communities: make([]api.Community, 0, len(communities)),
+		Limit:       limit,
+		Offset:      offset,
+	}
+	for _, c := range communities {
+		res.Communities = append(res.Communities, api.Community{
+			Id:          c.Id,
+			Size:        c.Size,
+			TopMembers:  c.TopMembers,
+			Reciprocity: graph.Reciprocity{Follows: c.Follows, Reciprocated: c.Reciprocated}.Ratio(),
+		})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) ListCommunityMembers(w http.ResponseWriter, r *http.Request, communityId int, params api.ListCommunityMembersParams) {
+	limit, offset := page(params.Limit, params.Offset)
+	algorithm := communityAlgorithm(params.Algorithm)
+
+	people, total, found, err := h.graph.CommunityMembers(r.Context(), algorithm, communityId, limit, offset)
+	if err != nil {
+		slog.Error("listing community members", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "community not found", http.StatusNotFound)
+		return
+	}
+
+	res := toAPIPersonPage(people, limit, offset)
+	res.Total = &total
+	writeJSON(w, http.StatusOK, res)
+}
+
+// communityAlgorithm resolves the optional algorithm parameter, defaulting to Louvain.
+func communityAlgorithm(algorithm *api.CommunityAlgorithm) repo.CommunityAlgorithm {
+	if algorithm == nil {
+		return repo.CommunityAlgorithmLouvain
+	}
+	return repo.CommunityAlgorithm(*algorithm)
+}
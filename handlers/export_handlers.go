@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
+	"lopa.to/sonimulus/internal/export"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func (h *Handler) ExportGraph(w http.ResponseWriter, r *http.Request, params api.ExportGraphParams) {
+	format := export.FormatGEXF
+	if params.Format != nil {
+		format = export.Format(*params.Format)
+	}
+	if !format.Valid() {
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return
+	}
+
+	sel, err := exportSelection(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Headers are only sent with the first bytes, so that errors before then get a status
+	aw := &attachmentWriter{w: w, format: format}
+	enc, err := export.NewEncoder(aw, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.graph.Export(r.Context(), sel, enc)
+	if err == nil && found {
+		err = enc.Close()
+	}
+	if err != nil {
+		slog.Error("exporting graph", "error", err)
+		if !aw.started {
+			http.Error(w, err.Error(), 500)
+		}
+		return
+	}
+
+	if !found {
+		http.Error(w, "person or community not found", http.StatusNotFound)
+	}
+}
+
+// exportSelection validates the selection of an export request.
+func exportSelection(params api.ExportGraphParams) (graph.ExportSelection, error) {
+	sel := graph.ExportSelection{
+		Scope:     graph.ExportScopeAll,
+		Radius:    defaultEgoRadius,
+		Direction: repo.DirectionOut,
+		Algorithm: communityAlgorithm(params.Algorithm),
+	}
+	if params.Scope != nil {
+		sel.Scope = graph.ExportScope(*params.Scope)
+	}
+	if params.Radius != nil && *params.Radius > 0 {
+		sel.Radius = min(*params.Radius, maxEgoRadius)
+	}
+	if params.Direction != nil {
+		sel.Direction = repo.Direction(*params.Direction)
+	}
+	if params.Since != nil {
+		sel.Since = *params.Since
+	}
+	if params.Until != nil {
+		sel.Until = *params.Until
+	}
+
+	switch sel.Scope {
+	case graph.ExportScopeAll, graph.ExportScopeCrawl:
+	case graph.ExportScopeEgo:
+		if params.PersonId == nil {
+			return sel, fmt.Errorf("the ego scope requires personId")
+		}
+		sel.PersonID = *params.PersonId
+		switch sel.Direction {
+		case repo.DirectionOut, repo.DirectionIn, repo.DirectionBoth:
+		default:
+			return sel, fmt.Errorf("invalid direction %q", sel.Direction)
+		}
+	case graph.ExportScopeCommunity:
+		if params.CommunityId == nil {
+			return sel, fmt.Errorf("the community scope requires communityId")
+		}
+		sel.CommunityID = *params.CommunityId
+		if !sel.Algorithm.Valid() {
+			return sel, fmt.Errorf("invalid algorithm %q", sel.Algorithm)
+		}
+	default:
+		return sel, fmt.Errorf("invalid scope %q", sel.Scope)
+	}
+	return sel, nil
+}
+
+// attachmentWriter sends the export as a file download once its first bytes are written.
+type attachmentWriter struct {
+	w       http.ResponseWriter
+	format  export.Format
+	started bool
+}
+
+func (aw *attachmentWriter) Write(p []byte) (int, error) {
+	if !aw.started {
+		aw.started = true
+		aw.w.Header().Set("Content-Type", aw.format.ContentType())
+		aw.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="sonimulus.%s"`, aw.format.Extension()))
+		aw.w.WriteHeader(http.StatusOK)
+	}
+	return aw.w.Write(p)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+const (
+	defaultEgoRadius = 2
+	maxEgoRadius     = 3
+	defaultEgoLimit  = 500
+	maxEgoLimit      = 2000
+	maxPaths         = 10
+)
+
+func (h *Handler) GetPersonEdges(w http.ResponseWriter, r *http.Request, personId api.PersonId, params api.GetPersonEdgesParams) {
+	layer := repo.LayerSocial
+	if params.Layer != nil {
+		layer = repo.Layer(*params.Layer)
+	}
+	if !layer.Valid() {
+		http.Error(w, "invalid layer", http.StatusBadRequest)
+		return
+	}
+
+	direction := repo.DirectionOut
+	if params.Direction != nil {
+		direction = repo.Direction(*params.Direction)
+	}
+	if !direction.Valid() {
+		http.Error(w, "invalid direction", http.StatusBadRequest)
+		return
+	}
+
+	var kinds []repo.EdgeKind
+	if params.Kind != nil {
+		for _, k := range *params.Kind {
+			kinds = append(kinds, repo.EdgeKind(k))
+		}
+	}
+
+	g, err := h.graph.PersonEdges(r.Context(), personId, layer, direction, kinds)
+	if err != nil {
+		slog.Error("getting person edges", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIGraph(g))
+}
+
+func (h *Handler) GetPersonEgoNetwork(w http.ResponseWriter, r *http.Request, personId api.PersonId, params api.GetPersonEgoNetworkParams) {
+	radius := defaultEgoRadius
+	if params.Radius != nil && *params.Radius > 0 {
+		radius = min(*params.Radius, maxEgoRadius)
+	}
+
+	direction := repo.DirectionOut
+	if params.Direction != nil {
+		direction = repo.Direction(*params.Direction)
+	}
+	if !direction.Valid() {
+		http.Error(w, "invalid direction", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultEgoLimit
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = min(*params.Limit, maxEgoLimit)
+	}
+
+	network, err := h.graph.EgoNetwork(r.Context(), personId, radius, direction, limit)
+	if err != nil {
+		slog.Error("getting ego network", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if len(network.Nodes) == 0 {
+		http.Error(w, "person not found", http.StatusNotFound)
+		return
+	}
+
+	g := toAPIGraph(network.Graph)
+	writeJSON(w, http.StatusOK, api.EgoNetwork{
+		Nodes:     g.Nodes,
+		Edges:     g.Edges,
+		Total:     network.Total,
+		Truncated: network.Truncated(),
+	})
+}
+
+func (h *Handler) GetPaths(w http.ResponseWriter, r *http.Request, params api.GetPathsParams) {
+	var from int64
+	if params.From != nil {
+		from = *params.From
+	} else {
+		// Default to the person linked to the logged in user
+		user, ok := r.Context().Value("user").(repo.User)
+		if !ok || user.PersonID == nil {
+			http.Error(w, "no starting person given, and no person is linked to the user", http.StatusBadRequest)
+			return
+		}
+		from = *user.PersonID
+	}
+
+	k := 1
+	if params.K != nil && *params.K > 0 {
+		k = min(*params.K, maxPaths)
+	}
+
+	direction := repo.DirectionOut
+	if params.Direction != nil {
+		direction = repo.Direction(*params.Direction)
+	}
+	if !direction.Valid() {
+		http.Error(w, "invalid direction", http.StatusBadRequest)
+		return
+	}
+
+	paths, err := h.graph.Paths(r.Context(), from, params.To, direction, k)
+	if err != nil {
+		slog.Error("finding paths", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	g := toAPIGraph(paths.Graph)
+	res := api.PathSet{
+		Nodes: g.Nodes,
+		Edges: g.Edges,
+		Paths: make([][]int64, 0, len(paths.Paths)),
+	}
+	res.Paths = append(res.Paths, paths.Paths...)
+	writeJSON(w, http.StatusOK, res)
+}
+
+func toAPIGraph(g graph.Graph) api.Graph {
+	res := api.Graph{
+		Nodes: make([]api.Person, 0, len(g.Nodes)),
+		Edges: make([]api.Edge, 0, len(g.Edges)),
+	}
+	for _, p := range g.Nodes {
+		res.Nodes = append(res.Nodes, toAPIPerson(p))
+	}
+	for _, e := range g.Edges {
+		res.Edges = append(res.Edges, toAPIEdge(e))
+	}
+	return res
+}
+
+func toAPIPerson(p repo.Person) api.Person {
+	person := api.Person{
+		Id:         p.Id,
+		Username:   p.Username,
+		Name:       p.Name,
+		ImageUrl:   p.ImageUrl,
+		Verified:   p.Verified,
+		Plan:       api.Plan(p.Plan),
+		TrackCount: p.TrackCount,
+		Profile:    toAPIProfile(p.Profile),
+		Metrics:    toAPIMetrics(p.Metrics),
+		Community:  p.Community,
+		Position:   toAPIPosition(p.Position),
+	}
+	if p.Urn != "" {
+		person.Urn = &p.Urn
+	}
+	return person
+}
+
+func toAPIEdge(e repo.Edge) api.Edge {
+	return api.Edge{
+		Source:             e.SourceID,
+		Target:             e.TargetID,
+		Kind:               api.EdgeKind(e.Kind),
+		Weight:             e.Weight,
+		FirstInteractionAt: e.FirstAt,
+		LastInteractionAt:  e.LastAt,
+		Reciprocal:         e.Reciprocal,
+	}
+}
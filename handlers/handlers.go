@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 
 	"lopa.to/sonimulus/env"
+	"lopa.to/sonimulus/internal/graph"
 	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/internal/snapshot"
 )
 
 type AuthController interface {
@@ -15,15 +19,43 @@ type AuthController interface {
 	DeleteSession(ctx context.Context, sessionID string) (found bool, err error)
 }
 
+type GraphController interface {
+	PersonEdges(ctx context.Context, personID int64, layer repo.Layer, direction repo.Direction, kinds []repo.EdgeKind) (g graph.Graph, err error)
+	EgoNetwork(ctx context.Context, personID int64, radius int, direction repo.Direction, maxNodes int) (network graph.EgoNetwork, err error)
+	Paths(ctx context.Context, fromID, toID int64, direction repo.Direction, k int) (paths graph.PathSet, err error)
+	Mutuals(ctx context.Context, personID int64, limit, offset int) (people []repo.Person, total int, err error)
+	PersonReciprocity(personID int64) (reciprocity graph.PersonReciprocity)
+	Person(ctx context.Context, personID int64) (person repo.Person, found bool, err error)
+	SearchPeople(ctx context.Context, query string, limit, offset int) (people []repo.Person, err error)
+	SearchProfiles(ctx context.Context, filter repo.ProfileFilter, limit, offset int) (people []repo.Person, err error)
+	TopPeople(ctx context.Context, key repo.MetricKey, limit, offset int) (people []repo.Person, err error)
+	Communities(ctx context.Context, algorithm repo.CommunityAlgorithm, limit, offset int) (run repo.CommunityRun, communities []repo.Community, found bool, err error)
+	CommunityMembers(ctx context.Context, algorithm repo.CommunityAlgorithm, communityID, limit, offset int) (people []repo.Person, total int, found bool, err error)
+	Positions(ctx context.Context, limit, offset int) (run repo.LayoutRun, positions []repo.Position, found bool, err error)
+	Tile(ctx context.Context, vp graph.Viewport, maxNodes int) (tile graph.Tile, found bool, err error)
+	Export(ctx context.Context, sel graph.ExportSelection, w graph.GraphWriter) (found bool, err error)
+}
+
+type SnapshotController interface {
+	Create(ctx context.Context, name string) (snapshot repo.Snapshot, err error)
+	List(ctx context.Context) (snapshots []repo.Snapshot, err error)
+	Delete(ctx context.Context, name string) (found bool, err error)
+	Diff(ctx context.Context, from, to string, limit int) (diff snapshot.Diff, found bool, err error)
+}
+
 type Handler struct {
-	auth AuthController
-	env  env.Env
+	auth      AuthController
+	graph     GraphController
+	snapshots SnapshotController
+	env       env.Env
 }
 
-func NewHandler(auth AuthController, e env.Env) *Handler {
+func NewHandler(auth AuthController, graph GraphController, snapshots SnapshotController, e env.Env) *Handler {
 	return &Handler{
-		auth: auth,
-		env:  e,
+		auth:      auth,
+		graph:     graph,
+		snapshots: snapshots,
+		env:       e,
 	}
 }
 
@@ -40,3 +72,12 @@ func CorsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// writeJSON writes v to w as a JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("encoding response", "error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+const (
+	defaultPositionLimit = 1000
+	maxPositionLimit     = 10000
+	defaultTileLimit     = 2000
+	maxTileLimit         = 10000
+)
+
+func (h *Handler) ListLayoutPositions(w http.ResponseWriter, r *http.Request, params api.ListLayoutPositionsParams) {
+	limit, offset := defaultPositionLimit, 0
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = min(*params.Limit, maxPositionLimit)
+	}
+	if params.Offset != nil && *params.Offset > 0 {
+		offset = *params.Offset
+	}
+
+	run, positions, found, err := h.graph.Positions(r.Context(), limit, offset)
+	if err != nil {
+		slog.Error("listing positions", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "no layout run", http.StatusNotFound)
+		return
+	}
+
+	res := api.PositionPage{
+		Run: api.LayoutRun{
+			Id:          run.Id,
+			Nodes:       run.Nodes,
+			Iterations:  run.Iterations,
+			Incremental: run.Incremental,
+			CreatedAt:   run.CreatedAt,
+		},
+		Positions: make([]api.PersonPosition, 0, len(positions)),
+		Limit:     limit,
+		Offset:    offset,
+	}
+	for _, p := range positions {
+		res.Positions = append(res.Positions, api.PersonPosition{Id: p.PersonID, X: p.X, Y: p.Y})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) GetLayoutTile(w http.ResponseWriter, r *http.Request, params api.GetLayoutTileParams) {
+	if params.Zoom < 0 || params.Zoom > graph.MaxTileZoom || params.MinX > params.MaxX || params.MinY > params.MaxY {
+		http.Error(w, "invalid viewport", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultTileLimit
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = min(*params.Limit, maxTileLimit)
+	}
+
+	vp := graph.Viewport{MinX: params.MinX, MinY: params.MinY, MaxX: params.MaxX, MaxY: params.MaxY, Zoom: params.Zoom}
+	tile, found, err := h.graph.Tile(r.Context(), vp, limit)
+	if err != nil {
+		slog.Error("getting tile", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "no layout run", http.StatusNotFound)
+		return
+	}
+
+	res := api.Tile{
+		Zoom:      params.Zoom,
+		Nodes:     make([]api.TileNode, 0, len(tile.Nodes)),
+		Edges:     make([]api.TileEdge, 0, len(tile.Edges)),
+		Total:     tile.Total,
+		Truncated: tile.Truncated(),
+	}
+	for _, n := range tile.Nodes {
+		node := api.TileNode{
+			Id:        n.Key,
+			X:         n.X,
+			Y:         n.Y,
+			Size:      n.Size,
+			Community: n.Community,
+			PersonId:  n.PersonID,
+		}
+		if n.Person != nil {
+			person := toAPIPerson(*n.Person)
+			node.Person = &person
+		}
+		res.Nodes = append(res.Nodes, node)
+	}
+	for _, e := range tile.Edges {
+		res.Edges = append(res.Edges, api.TileEdge{Source: e.Source, Target: e.Target, Weight: e.Weight})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func toAPIPosition(p *repo.Position) *api.Position {
+	if p == nil {
+		return nil
+	}
+	return &api.Position{X: p.X, Y: p.Y}
+}
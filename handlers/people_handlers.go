@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+func (h *Handler) GetPerson(w http.ResponseWriter, r *http.Request, personId api.PersonId) {
+	person, found, err := h.graph.Person(r.Context(), personId)
+	if err != nil {
+		slog.Error("getting person", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "person not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIPerson(person))
+}
+
+func (h *Handler) ListPeople(w http.ResponseWriter, r *http.Request, params api.ListPeopleParams) {
+	limit, offset := page(params.Limit, params.Offset)
+	filter := repo.ProfileFilter{
+		Query:   deref(params.Q),
+		City:    deref(params.City),
+		Country: deref(params.Country),
+		Service: deref(params.Service),
+		Sort:    repo.MetricKey(deref(params.Sort)),
+	}
+	if filter.Sort != "" && !filter.Sort.Valid() {
+		http.Error(w, "invalid sort metric", http.StatusBadRequest)
+		return
+	}
+
+	people, err := h.graph.SearchProfiles(r.Context(), filter, limit, offset)
+	if err != nil {
+		slog.Error("listing people", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIPersonPage(people, limit, offset))
+}
+
+func (h *Handler) SearchPeople(w http.ResponseWriter, r *http.Request, params api.SearchPeopleParams) {
+	limit, offset := page(params.Limit, params.Offset)
+
+	people, err := h.graph.SearchPeople(r.Context(), params.Q, limit, offset)
+	if err != nil {
+		slog.Error("searching people", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIPersonPage(people, limit, offset))
+}
+
+func (h *Handler) ListTopPeople(w http.ResponseWriter, r *http.Request, params api.ListTopPeopleParams) {
+	limit, offset := page(params.Limit, params.Offset)
+	key := repo.MetricKeyPageRank
+	if params.Metric != nil {
+		key = repo.MetricKey(*params.Metric)
+	}
+	if !key.Valid() {
+		http.Error(w, "invalid metric", http.StatusBadRequest)
+		return
+	}
+
+	people, err := h.graph.TopPeople(r.Context(), key, limit, offset)
+	if err != nil {
+		slog.Error("ranking people", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIPersonPage(people, limit, offset))
+}
+
+func (h *Handler) ListPersonMutuals(w http.ResponseWriter, r *http.Request, personId api.PersonId, params api.ListPersonMutualsParams) {
+	limit, offset := page(params.Limit, params.Offset)
+
+	people, total, err := h.graph.Mutuals(r.Context(), personId, limit, offset)
+	if err != nil {
+		slog.Error("listing mutuals", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	res := toAPIPersonPage(people, limit, offset)
+	res.Total = &total
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) GetPersonReciprocity(w http.ResponseWriter, r *http.Request, personId api.PersonId) {
+	// The follow graph only holds people with follows, so existence is checked in the database
+	_, found, err := h.graph.Person(r.Context(), personId)
+	if err != nil {
+		slog.Error("getting person", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "person not found", http.StatusNotFound)
+		return
+	}
+
+	rec := h.graph.PersonReciprocity(personId)
+	writeJSON(w, http.StatusOK, api.Reciprocity{
+		Following: rec.Following,
+		Followers: rec.Followers,
+		Mutuals:   rec.Mutuals,
+		Ratio:     rec.Ratio(),
+	})
+}
+
+func toAPIPersonPage(people []repo.Person, limit, offset int) api.PersonPage {
+	res := api.PersonPage{
+		People: make([]api.Person, 0, len(people)),
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, p := range people {
+		res.People = append(res.People, toAPIPerson(p))
+	}
+	return res
+}
+
+func toAPIProfile(p *repo.Profile) *api.Profile {
+	if p == nil {
+		return nil
+	}
+
+	profile := api.Profile{
+		City:         p.City,
+		Country:      p.Country,
+		Description:  p.Description,
+		Website:      p.Website,
+		WebsiteTitle: p.WebsiteTitle,
+		DiscogsName:  p.DiscogsName,
+		Links:        make([]api.ProfileLink, 0, len(p.Links)),
+	}
+	for _, l := range p.Links {
+		profile.Links = append(profile.Links, api.ProfileLink{
+			Service:  l.Service,
+			Url:      l.Url,
+			Username: l.Username,
+			Title:    l.Title,
+		})
+	}
+	return &profile
+}
+
+func toAPIMetrics(m *repo.PersonMetrics) *api.PersonMetrics {
+	if m == nil {
+		return nil
+	}
+
+	return &api.PersonMetrics{
+		InDegree:   m.InDegree,
+		OutDegree:  m.OutDegree,
+		PageRank:   m.PageRank,
+		Hub:        m.Hub,
+		Authority:  m.Authority,
+		ComputedAt: m.ComputedAt,
+	}
+}
+
+// page resolves optional pagination parameters, clamping the limit to maxPageLimit.
+func page(limit, offset *int) (int, int) {
+	l, o := defaultPageLimit, 0
+	if limit != nil && *limit > 0 {
+		l = min(*limit, maxPageLimit)
+	}
+	if offset != nil && *offset > 0 {
+		o = *offset
+	}
+	return l, o
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
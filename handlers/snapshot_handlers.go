@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"lopa.to/sonimulus/api/v1"
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/internal/snapshot"
+)
+
+func (h *Handler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := h.snapshots.List(r.Context())
+	if err != nil {
+		slog.Error("listing snapshots", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	res := make([]api.Snapshot, 0, len(snapshots))
+	for _, s := range snapshots {
+		res = append(res, toAPISnapshot(s))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) CreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	var body api.CreateSnapshotJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	if !snapshot.ValidName(body.Name) {
+		http.Error(w, "invalid name", http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.snapshots.Create(r.Context(), body.Name)
+	if errors.Is(err, repo.ErrSnapshotExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		slog.Error("creating snapshot", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toAPISnapshot(s))
+}
+
+func (h *Handler) DeleteSnapshot(w http.ResponseWriter, r *http.Request, name api.SnapshotName) {
+	found, err := h.snapshots.Delete(r.Context(), name)
+	if err != nil {
+		slog.Error("deleting snapshot", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetSnapshotDiff(w http.ResponseWriter, r *http.Request, name api.SnapshotName, params api.GetSnapshotDiffParams) {
+	limit, _ := page(params.Limit, nil)
+
+	diff, found, err := h.snapshots.Diff(r.Context(), params.From, name, limit)
+	if err != nil {
+		slog.Error("comparing snapshots", "error", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	if !found {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+
+	res := api.SnapshotDiff{
+		From:                toAPISnapshot(diff.From),
+		To:                  toAPISnapshot(diff.To),
+		AddedPeopleCount:    diff.Counts.AddedPeople,
+		RemovedPeopleCount:  diff.Counts.RemovedPeople,
+		AddedFollowsCount:   diff.Counts.AddedFollows,
+		RemovedFollowsCount: diff.Counts.RemovedFollows,
+		MovedPeopleCount:    diff.MovedPeople,
+		AddedPeople:         toAPISnapshotPeople(diff.AddedPeople),
+		RemovedPeople:       toAPISnapshotPeople(diff.RemovedPeople),
+		AddedFollows:        toAPISnapshotFollows(diff.AddedFollows),
+		RemovedFollows:      toAPISnapshotFollows(diff.RemovedFollows),
+		FollowerGains:       make([]api.FollowerGain, 0, len(diff.FollowerGains)),
+		CommunityMoves:      make([]api.CommunityMove, 0, len(diff.CommunityMoves)),
+	}
+	for _, g := range diff.FollowerGains {
+		res.FollowerGains = append(res.FollowerGains, api.FollowerGain{Person: toAPISnapshotPerson(g.Person), Before: g.Before, After: g.After})
+	}
+	for _, m := range diff.CommunityMoves {
+		res.CommunityMoves = append(res.CommunityMoves, api.CommunityMove{Person: toAPISnapshotPerson(m.Person), From: m.From, To: m.To})
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func toAPISnapshot(s repo.Snapshot) api.Snapshot {
+	return api.Snapshot{
+		Name:           s.Name,
+		People:         s.People,
+		Follows:        s.Follows,
+		CommunityRunId: s.CommunityRunID,
+		CreatedAt:      s.CreatedAt,
+	}
+}
+
+func toAPISnapshotPerson(p repo.SnapshotPerson) api.SnapshotPerson {
+	person := api.SnapshotPerson{
+		Id:         p.Id,
+		Username:   p.Username,
+		Name:       p.Name,
+		Followers:  p.Followers,
+		Followings: p.Followings,
+		Community:  p.Community,
+	}
+	if p.Urn != "" {
+		person.Urn = &p.Urn
+	}
+	return person
+}
+
+func toAPISnapshotPeople(people []repo.SnapshotPerson) []api.SnapshotPerson {
+	res := make([]api.SnapshotPerson, 0, len(people))
+	for _, p := range people {
+		res = append(res, toAPISnapshotPerson(p))
+	}
+	return res
+}
+
+func toAPISnapshotFollows(follows []repo.SnapshotFollow) []api.SnapshotFollow {
+	res := make([]api.SnapshotFollow, 0, len(follows))
+	for _, f := range follows {
+		res = append(res, api.SnapshotFollow{
+			FollowerId: f.FollowerID,
+			FolloweeId: f.FolloweeID,
+			Follower:   f.FollowerUsername,
+			Followee:   f.FolloweeUsername,
+		})
+	}
+	return res
+}
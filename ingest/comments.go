@@ -0,0 +1,95 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/soundcloud"
+)
+
+// commentTimeLayout is the timestamp format SoundCloud uses for comments.
+const commentTimeLayout = "2006/01/02 15:04:05 -0700"
+
+// IngestComments stores the comments left on a person's tracks and rebuilds
+// the commented_on edges from each commenter to the person.
+func (in *Ingester) IngestComments(ctx context.Context, person repo.Person) error {
+	person, err := in.resolve(ctx, person)
+	if err != nil {
+		return err
+	}
+
+	tracks, err := in.sc.GetUserTracks(ctx, person.Urn, in.limit)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]int64)
+	var comments []repo.Comment
+	for _, track := range tracks {
+		if track.Urn == nil {
+			continue
+		}
+
+		trackComments, err := in.sc.GetTrackComments(ctx, *track.Urn, in.limit)
+		if err != nil {
+			slog.Error("error getting comments", "track", *track.Urn, "error", err)
+			continue
+		}
+
+		for _, c := range trackComments {
+			if c.Urn == nil || c.User == nil || c.User.Permalink == nil {
+				continue
+			}
+			createdAt, err := parseCommentTime(deref(c.CreatedAt))
+			if err != nil {
+				slog.Error("error parsing comment time", "comment", *c.Urn, "error", err)
+				continue
+			}
+
+			commenterID, ok := ids[*c.User.Permalink]
+			if !ok {
+				commenter, err := in.upsertUser(ctx, &soundcloud.User{
+					Permalink: c.User.Permalink,
+					Urn:       c.User.Urn,
+					Username:  c.User.Username,
+					AvatarUrl: c.User.AvatarUrl,
+				})
+				if err != nil {
+					slog.Error("error storing commenter", "handle", *c.User.Permalink, "error", err)
+					continue
+				}
+				commenterID = commenter.Id
+				ids[*c.User.Permalink] = commenterID
+			}
+
+			comments = append(comments, repo.Comment{
+				Urn:         *c.Urn,
+				TrackUrn:    *track.Urn,
+				CommenterID: commenterID,
+				ArtistID:    person.Id,
+				Body:        deref(c.Body),
+				CreatedAt:   createdAt,
+			})
+		}
+	}
+
+	slog.Info("ingested comments", "handle", person.Username, "comments", len(comments))
+
+	if err := in.comments.UpsertComments(ctx, comments); err != nil {
+		return err
+	}
+	return in.comments.MaterializeEdges(ctx, person.Id)
+}
+
+// parseCommentTime parses a SoundCloud comment timestamp.
+func parseCommentTime(s string) (time.Time, error) {
+	for _, layout := range []string{commentTimeLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid comment time %q", s)
+}
@@ -0,0 +1,99 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/soundcloud"
+)
+
+type edgeKey struct {
+	sourceID int64
+	targetID int64
+	kind     repo.EdgeKind
+}
+
+// IngestEngagement records who a person liked tracks of, and who liked or reposted the person's tracks.
+//
+// Each edge is weighted by the number of tracks the interaction happened on.
+func (in *Ingester) IngestEngagement(ctx context.Context, person repo.Person) error {
+	person, err := in.resolve(ctx, person)
+	if err != nil {
+		return err
+	}
+
+	weights := make(map[edgeKey]float64)
+	ids := make(map[string]int64)
+
+	// lookup stores a SoundCloud user once per ingestion and returns its person id.
+	lookup := func(user *soundcloud.User) (int64, bool) {
+		if user == nil || user.Permalink == nil {
+			return 0, false
+		}
+		if id, ok := ids[*user.Permalink]; ok {
+			return id, true
+		}
+		p, err := in.upsertUser(ctx, user)
+		if err != nil {
+			slog.Error("error storing user", "handle", *user.Permalink, "error", err)
+			return 0, false
+		}
+		ids[p.Username] = p.Id
+		return p.Id, true
+	}
+
+	likes, err := in.sc.GetUserLikedTracks(ctx, person.Urn, in.limit)
+	if err != nil {
+		return err
+	}
+	for _, track := range likes {
+		if artistID, ok := lookup(track.User); ok && artistID != person.Id {
+			weights[edgeKey{person.Id, artistID, repo.EdgeKindLikedTrackOf}]++
+		}
+	}
+
+	tracks, err := in.sc.GetUserTracks(ctx, person.Urn, in.limit)
+	if err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		if track.Urn == nil {
+			continue
+		}
+
+		favoriters, err := in.sc.GetTrackFavoriters(ctx, *track.Urn, in.limit)
+		if err != nil {
+			slog.Error("error getting favoriters", "track", *track.Urn, "error", err)
+		}
+		for _, user := range favoriters {
+			if id, ok := lookup(&user); ok && id != person.Id {
+				weights[edgeKey{id, person.Id, repo.EdgeKindLikedTrackOf}]++
+			}
+		}
+
+		reposters, err := in.sc.GetTrackReposters(ctx, *track.Urn, in.limit)
+		if err != nil {
+			slog.Error("error getting reposters", "track", *track.Urn, "error", err)
+		}
+		for _, user := range reposters {
+			if id, ok := lookup(&user); ok && id != person.Id {
+				weights[edgeKey{id, person.Id, repo.EdgeKindRepostedTrackOf}]++
+			}
+		}
+	}
+
+	edges := make([]repo.Edge, 0, len(weights))
+	for k, w := range weights {
+		edges = append(edges, repo.Edge{
+			SourceID: k.sourceID,
+			TargetID: k.targetID,
+			Kind:     k.kind,
+			Weight:   w,
+		})
+	}
+
+	slog.Info("ingested engagement", "handle", person.Username, "edges", len(edges))
+
+	return in.edges.UpsertEdges(ctx, edges)
+}
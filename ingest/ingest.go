@@ -0,0 +1,242 @@
+// Package ingest enriches the crawled people graph with data from the SoundCloud API.
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/soundcloud"
+)
+
+// Mode selects which kind of data an ingestion run collects.
+type Mode string
+
+const (
+	ModeEngagement Mode = "engagement"
+	ModeComments   Mode = "comments"
+	ModeRelated    Mode = "related"
+	ModeProfiles   Mode = "profiles"
+	ModePlaylists  Mode = "playlists"
+)
+
+type SoundCloudProvider interface {
+	ResolveUser(ctx context.Context, handle string) (*soundcloud.User, error)
+	GetUser(ctx context.Context, userUrn string) (*soundcloud.User, error)
+	GetWebProfiles(ctx context.Context, userUrn string, limit int) (soundcloud.WebProfiles, error)
+	GetUserTracks(ctx context.Context, userUrn string, limit int) ([]soundcloud.Track, error)
+	GetUserLikedTracks(ctx context.Context, userUrn string, limit int) ([]soundcloud.Track, error)
+	GetTrackFavoriters(ctx context.Context, trackUrn string, limit int) ([]soundcloud.User, error)
+	GetTrackReposters(ctx context.Context, trackUrn string, limit int) ([]soundcloud.User, error)
+	GetTrackComments(ctx context.Context, trackUrn string, limit int) ([]soundcloud.Comment, error)
+	GetRelatedTracks(ctx context.Context, trackUrn string, limit int) ([]soundcloud.Track, error)
+	GetUserPlaylists(ctx context.Context, userUrn string, limit int) ([]soundcloud.Playlist, error)
+	GetPlaylistTracks(ctx context.Context, playlistUrn string) ([]soundcloud.Track, error)
+}
+
+type PeopleStorer interface {
+	List(ctx context.Context, afterID int64, limit int) (people []repo.Person, err error)
+	Upsert(ctx context.Context, p repo.Person) (person repo.Person, err error)
+}
+
+type EdgeStorer interface {
+	UpsertEdges(ctx context.Context, edges []repo.Edge) error
+}
+
+type CommentStorer interface {
+	UpsertComments(ctx context.Context, comments []repo.Comment) error
+	MaterializeEdges(ctx context.Context, artistID int64) error
+}
+
+type TrackStorer interface {
+	UpsertTracks(ctx context.Context, tracks []repo.Track) error
+	ReplaceRelated(ctx context.Context, trackUrn string, relatedUrns []string) error
+	RollUpSimilarity(ctx context.Context, artistID int64) error
+}
+
+type ProfileStorer interface {
+	Upsert(ctx context.Context, p repo.Profile) error
+}
+
+type PlaylistStorer interface {
+	UpsertPlaylist(ctx context.Context, p repo.Playlist, trackUrns []string) error
+	RebuildCoOccurrence(ctx context.Context) error
+}
+
+// Ingester walks the people table and pulls additional relationships for each person.
+type Ingester struct {
+	sc        SoundCloudProvider
+	people    PeopleStorer
+	edges     EdgeStorer
+	comments  CommentStorer
+	tracks    TrackStorer
+	profiles  ProfileStorer
+	playlists PlaylistStorer
+	tokens    oauth2.TokenSource
+	limit     int
+}
+
+// NewIngester creates a new Ingester. Every SoundCloud collection is truncated to limit items.
+func NewIngester(
+	sc SoundCloudProvider,
+	people PeopleStorer,
+	edges EdgeStorer,
+	comments CommentStorer,
+	tracks TrackStorer,
+	profiles ProfileStorer,
+	playlists PlaylistStorer,
+	tokens oauth2.TokenSource,
+	limit int,
+) *Ingester {
+	return &Ingester{
+		sc:        sc,
+		people:    people,
+		edges:     edges,
+		comments:  comments,
+		tracks:    tracks,
+		profiles:  profiles,
+		playlists: playlists,
+		tokens:    tokens,
+		limit:     limit,
+	}
+}
+
+// Run ingests data of the given mode for every stored person using numWorkers concurrent workers.
+func (in *Ingester) Run(ctx context.Context, mode Mode, numWorkers int) error {
+	var ingest func(ctx context.Context, person repo.Person) error
+	switch mode {
+	case ModeEngagement:
+		ingest = in.IngestEngagement
+	case ModeComments:
+		ingest = in.IngestComments
+	case ModeRelated:
+		ingest = in.IngestRelated
+	case ModeProfiles:
+		ingest = in.IngestProfile
+	case ModePlaylists:
+		ingest = in.IngestPlaylists
+	default:
+		return fmt.Errorf("unknown ingestion mode %q", mode)
+	}
+
+	wg := sync.WaitGroup{}
+	queue := make(chan repo.Person, 100)
+
+	for i := range numWorkers {
+		wg.Add(1)
+		go func(workerId int) {
+			defer wg.Done()
+			for person := range queue {
+				slog.Info("working new ingestion job", "id", workerId, "mode", mode, "handle", person.Username)
+
+				pctx, err := in.authorize(ctx)
+				if err != nil {
+					slog.Error("error obtaining access token", "error", err)
+					continue
+				}
+				if err := ingest(pctx, person); err != nil {
+					slog.Error("error ingesting person", "handle", person.Username, "error", err)
+				}
+			}
+		}(i)
+	}
+
+	var (
+		afterID int64
+		err     error
+	)
+	for {
+		var people []repo.Person
+		people, err = in.people.List(ctx, afterID, 500)
+		if err != nil || len(people) == 0 {
+			break
+		}
+		for _, person := range people {
+			queue <- person
+		}
+		afterID = people[len(people)-1].Id
+	}
+
+	close(queue)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+
+	// The co-occurrence graph spans every playlist, so it is derived once all of them are stored
+	if mode == ModePlaylists {
+		return in.playlists.RebuildCoOccurrence(ctx)
+	}
+	return nil
+}
+
+// authorize attaches a fresh access token to ctx for the SoundCloud client.
+func (in *Ingester) authorize(ctx context.Context) (context.Context, error) {
+	token, err := in.tokens.Token()
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, "access_token", token.AccessToken), nil
+}
+
+// resolve returns the SoundCloud user for a stored person, recording its URN if it was unknown.
+func (in *Ingester) resolve(ctx context.Context, person repo.Person) (repo.Person, error) {
+	if person.Urn != "" {
+		return person, nil
+	}
+	user, err := in.sc.ResolveUser(ctx, person.Username)
+	if err != nil {
+		return person, err
+	}
+	if user.Urn == nil {
+		return person, fmt.Errorf("user %s has no urn", person.Username)
+	}
+	person.Urn = *user.Urn
+	return in.people.Upsert(ctx, person)
+}
+
+// upsertUser stores a SoundCloud user as a person.
+func (in *Ingester) upsertUser(ctx context.Context, user *soundcloud.User) (repo.Person, error) {
+	if user == nil || user.Permalink == nil {
+		return repo.Person{}, fmt.Errorf("user has no permalink")
+	}
+	return in.people.Upsert(ctx, PersonFromUser(user))
+}
+
+// PersonFromUser converts a SoundCloud API user to a person.
+func PersonFromUser(user *soundcloud.User) repo.Person {
+	person := repo.Person{
+		Username: deref(user.Permalink),
+		Urn:      deref(user.Urn),
+		Name:     deref(user.Username),
+		ImageUrl: deref(user.AvatarUrl),
+		Plan:     planFromString(deref(user.Plan)),
+	}
+	if user.TrackCount != nil {
+		person.TrackCount = int64(*user.TrackCount)
+	}
+	return person
+}
+
+func planFromString(plan string) repo.Plan {
+	switch {
+	case strings.EqualFold(plan, "Artist"):
+		return repo.PlanArtist
+	case strings.Contains(strings.ToLower(plan), "pro"):
+		return repo.PlanArtistPro
+	default:
+		return repo.PlanNone
+	}
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,347 @@
+package ingest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/ingest"
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/soundcloud"
+)
+
+// fakeSoundCloud serves tracks and their audience from memory. Methods a test does not
+// need are left to the embedded nil interface, and panic when called.
+type fakeSoundCloud struct {
+	ingest.SoundCloudProvider
+
+	users      map[string]*soundcloud.User
+	liked      map[string][]soundcloud.Track
+	tracks     map[string][]soundcloud.Track
+	favoriters map[string][]soundcloud.User
+	reposters  map[string][]soundcloud.User
+	comments   map[string][]soundcloud.Comment
+	profiles   map[string]soundcloud.WebProfiles
+}
+
+func (fs *fakeSoundCloud) ResolveUser(_ context.Context, handle string) (*soundcloud.User, error) {
+	user, ok := fs.users[handle]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return user, nil
+}
+
+func (fs *fakeSoundCloud) GetUser(_ context.Context, userUrn string) (*soundcloud.User, error) {
+	for _, user := range fs.users {
+		if user.Urn != nil && *user.Urn == userUrn {
+			return user, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (fs *fakeSoundCloud) GetWebProfiles(_ context.Context, userUrn string, limit int) (soundcloud.WebProfiles, error) {
+	profiles, ok := fs.profiles[userUrn]
+	if !ok {
+		return nil, errors.New("unavailable")
+	}
+	return truncate(profiles, limit), nil
+}
+
+func (fs *fakeSoundCloud) GetUserLikedTracks(_ context.Context, userUrn string, limit int) ([]soundcloud.Track, error) {
+	return truncate(fs.liked[userUrn], limit), nil
+}
+
+func (fs *fakeSoundCloud) GetUserTracks(_ context.Context, userUrn string, limit int) ([]soundcloud.Track, error) {
+	return truncate(fs.tracks[userUrn], limit), nil
+}
+
+func (fs *fakeSoundCloud) GetTrackFavoriters(_ context.Context, trackUrn string, limit int) ([]soundcloud.User, error) {
+	return truncate(fs.favoriters[trackUrn], limit), nil
+}
+
+func (fs *fakeSoundCloud) GetTrackReposters(_ context.Context, trackUrn string, limit int) ([]soundcloud.User, error) {
+	return truncate(fs.reposters[trackUrn], limit), nil
+}
+
+func (fs *fakeSoundCloud) GetTrackComments(_ context.Context, trackUrn string, limit int) ([]soundcloud.Comment, error) {
+	return truncate(fs.comments[trackUrn], limit), nil
+}
+
+func truncate[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+// fakePeople stores people in memory, numbering them from 1 in order of first upsert.
+type fakePeople struct {
+	mu     sync.Mutex
+	people map[string]repo.Person
+}
+
+func (fp *fakePeople) List(context.Context, int64, int) ([]repo.Person, error) {
+	return nil, nil
+}
+
+func (fp *fakePeople) Upsert(_ context.Context, p repo.Person) (repo.Person, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	if fp.people == nil {
+		fp.people = make(map[string]repo.Person)
+	}
+	if stored, ok := fp.people[p.Username]; ok {
+		p.Id = stored.Id
+	} else {
+		p.Id = int64(len(fp.people) + 1)
+	}
+	fp.people[p.Username] = p
+	return p, nil
+}
+
+// fakeEdges records the edges upserted.
+type fakeEdges struct {
+	edges []repo.Edge
+}
+
+func (fe *fakeEdges) UpsertEdges(_ context.Context, edges []repo.Edge) error {
+	fe.edges = append(fe.edges, edges...)
+	return nil
+}
+
+// fakeComments records the comments upserted and the artists whose edges were materialized.
+type fakeComments struct {
+	comments     []repo.Comment
+	materialized []int64
+}
+
+func (fc *fakeComments) UpsertComments(_ context.Context, comments []repo.Comment) error {
+	fc.comments = append(fc.comments, comments...)
+	return nil
+}
+
+func (fc *fakeComments) MaterializeEdges(_ context.Context, artistID int64) error {
+	fc.materialized = append(fc.materialized, artistID)
+	return nil
+}
+
+// fakeProfiles records the profiles upserted.
+type fakeProfiles struct {
+	profiles []repo.Profile
+}
+
+func (fp *fakeProfiles) Upsert(_ context.Context, p repo.Profile) error {
+	fp.profiles = append(fp.profiles, p)
+	return nil
+}
+
+func user(handle string) soundcloud.User {
+	urn := "soundcloud:users:" + handle
+	return soundcloud.User{Permalink: &handle, Urn: &urn, Username: &handle}
+}
+
+func track(urn string, artist soundcloud.User) soundcloud.Track {
+	return soundcloud.Track{Urn: &urn, User: &artist}
+}
+
+func TestIngestEngagement(t *testing.T) {
+	alice, bob, carol := user("alice"), user("bob"), user("carol")
+	anonymous := soundcloud.User{}
+
+	sc := &fakeSoundCloud{
+		users: map[string]*soundcloud.User{"alice": &alice},
+		liked: map[string][]soundcloud.Track{
+			// Liking her own track is not an edge, nor is a track without an artist
+			*alice.Urn: {track("b1", bob), track("b2", bob), track("a1", alice), {User: &anonymous}},
+		},
+		tracks: map[string][]soundcloud.Track{
+			*alice.Urn: {track("a1", alice), track("a2", alice), {}},
+		},
+		favoriters: map[string][]soundcloud.User{
+			"a1": {bob, carol, alice},
+			"a2": {bob},
+		},
+		reposters: map[string][]soundcloud.User{
+			"a2": {carol, anonymous},
+		},
+	}
+	people := &fakePeople{}
+	edges := &fakeEdges{}
+	in := ingest.NewIngester(sc, people, edges, nil, nil, nil, nil, nil, 10)
+
+	// alice was crawled by handle only, so her URN is resolved and stored first
+	if err := in.IngestEngagement(context.Background(), repo.Person{Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := people.people["alice"]
+	if stored.Urn != *alice.Urn {
+		t.Errorf("stored alice as %+v, want her URN resolved", stored)
+	}
+
+	names := make(map[int64]string)
+	for _, p := range people.people {
+		names[p.Id] = p.Username
+	}
+	var got []string
+	for _, e := range edges.edges {
+		got = append(got, strings.Join([]string{names[e.SourceID], string(e.Kind), names[e.TargetID], strconv.FormatFloat(e.Weight, 'g', -1, 64)}, " "))
+	}
+	slices.Sort(got)
+
+	want := []string{
+		"alice liked_track_of bob 2",
+		"bob liked_track_of alice 2",
+		"carol liked_track_of alice 1",
+		"carol reposted_track_of alice 1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got edges\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestIngestEngagementLimit(t *testing.T) {
+	alice, bob, carol := user("alice"), user("bob"), user("carol")
+	sc := &fakeSoundCloud{
+		liked:      map[string][]soundcloud.Track{*alice.Urn: {track("b1", bob), track("c1", carol)}},
+		tracks:     map[string][]soundcloud.Track{},
+		favoriters: map[string][]soundcloud.User{},
+		reposters:  map[string][]soundcloud.User{},
+	}
+	edges := &fakeEdges{}
+	in := ingest.NewIngester(sc, &fakePeople{}, edges, nil, nil, nil, nil, nil, 1)
+
+	person := repo.Person{Id: 100, Username: "alice", Urn: *alice.Urn}
+	if err := in.IngestEngagement(context.Background(), person); err != nil {
+		t.Fatal(err)
+	}
+	// Only the first like fits in the limit
+	if len(edges.edges) != 1 || edges.edges[0].SourceID != 100 || edges.edges[0].Weight != 1 {
+		t.Errorf("got %+v", edges.edges)
+	}
+}
+
+func TestIngestComments(t *testing.T) {
+	alice := user("alice")
+
+	var comments []soundcloud.Comment
+	err := json.Unmarshal([]byte(`[
+		{"urn": "c1", "body": "nice", "created_at": "2024/03/01 12:00:00 +0000", "user": {"permalink": "bob", "urn": "soundcloud:users:bob"}},
+		{"urn": "c2", "body": "nicer", "created_at": "2024-03-02T12:00:00Z", "user": {"permalink": "carol"}},
+		{"urn": "c3", "body": "undated", "created_at": "yesterday", "user": {"permalink": "dave"}},
+		{"urn": "c4", "body": "anonymous"}
+	]`), &comments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := &fakeSoundCloud{
+		tracks:   map[string][]soundcloud.Track{*alice.Urn: {track("a1", alice)}},
+		comments: map[string][]soundcloud.Comment{"a1": comments},
+	}
+	people := &fakePeople{}
+	stored := &fakeComments{}
+	in := ingest.NewIngester(sc, people, nil, stored, nil, nil, nil, nil, 10)
+
+	person := repo.Person{Id: 100, Username: "alice", Urn: *alice.Urn}
+	if err := in.IngestComments(context.Background(), person); err != nil {
+		t.Fatal(err)
+	}
+
+	// A comment without a readable time is skipped rather than dated now
+	want := []repo.Comment{
+		{Urn: "c1", TrackUrn: "a1", CommenterID: people.people["bob"].Id, ArtistID: 100, Body: "nice", CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{Urn: "c2", TrackUrn: "a1", CommenterID: people.people["carol"].Id, ArtistID: 100, Body: "nicer", CreatedAt: time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	if len(stored.comments) != len(want) {
+		t.Fatalf("got comments %+v, want %+v", stored.comments, want)
+	}
+	for i, c := range stored.comments {
+		if c.Urn != want[i].Urn || c.TrackUrn != want[i].TrackUrn || c.CommenterID != want[i].CommenterID ||
+			c.ArtistID != want[i].ArtistID || c.Body != want[i].Body || !c.CreatedAt.Equal(want[i].CreatedAt) {
+			t.Errorf("comment %d: got %+v, want %+v", i, c, want[i])
+		}
+	}
+	if _, ok := people.people["dave"]; ok {
+		t.Error("stored the author of a skipped comment")
+	}
+	if !slices.Equal(stored.materialized, []int64{100}) {
+		t.Errorf("materialized edges of %v, want [100]", stored.materialized)
+	}
+}
+
+func TestIngestProfile(t *testing.T) {
+	alice := user("alice")
+	city := "Berlin"
+	alice.City = &city
+
+	var links soundcloud.WebProfiles
+	err := json.Unmarshal([]byte(`[
+		{"service": "instagram", "url": "https://instagram.com/alice", "username": "alice.ig"},
+		{"service": "bandcamp"}
+	]`), &links)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := &fakeSoundCloud{users: map[string]*soundcloud.User{"alice": &alice}}
+	profiles := &fakeProfiles{}
+	in := ingest.NewIngester(sc, &fakePeople{}, nil, nil, nil, profiles, nil, nil, 10)
+	person := repo.Person{Id: 100, Username: "alice", Urn: *alice.Urn}
+
+	// Storing a profile without its links would delete the links stored before
+	if err := in.IngestProfile(context.Background(), person); err == nil {
+		t.Error("expected an error when the links cannot be fetched")
+	}
+	if len(profiles.profiles) != 0 {
+		t.Errorf("stored %+v without its links", profiles.profiles)
+	}
+
+	sc.profiles = map[string]soundcloud.WebProfiles{*alice.Urn: links}
+	if err := in.IngestProfile(context.Background(), person); err != nil {
+		t.Fatal(err)
+	}
+	want := repo.ProfileLink{Service: "instagram", Url: "https://instagram.com/alice", Username: "alice.ig"}
+	if len(profiles.profiles) != 1 {
+		t.Fatalf("got profiles %+v", profiles.profiles)
+	}
+	if p := profiles.profiles[0]; p.PersonID != 100 || p.City != "Berlin" || len(p.Links) != 1 || p.Links[0] != want {
+		t.Errorf("got profile %+v, want alice in Berlin with only her instagram link", p)
+	}
+}
+
+func TestPersonFromUser(t *testing.T) {
+	tests := []struct {
+		plan string
+		want repo.Plan
+	}{
+		{"", repo.PlanNone},
+		{"Free", repo.PlanNone},
+		{"artist", repo.PlanArtist},
+		{"Pro Unlimited", repo.PlanArtistPro},
+		{"Artist Pro", repo.PlanArtistPro},
+	}
+	for _, tt := range tests {
+		u := user("alice")
+		u.Plan = &tt.plan
+		count := 3
+		u.TrackCount = &count
+
+		p := ingest.PersonFromUser(&u)
+		if p.Plan != tt.want || p.Username != "alice" || p.Urn != "soundcloud:users:alice" || p.TrackCount != 3 {
+			t.Errorf("plan %q: got %+v, want plan %q", tt.plan, p, tt.want)
+		}
+	}
+
+	if p := ingest.PersonFromUser(&soundcloud.User{}); p.Username != "" || p.Plan != repo.PlanNone {
+		t.Errorf("empty user: got %+v", p)
+	}
+}
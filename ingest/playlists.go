@@ -0,0 +1,75 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// IngestPlaylists stores the playlists curated by a person, along with every
+// track in them and the artists who uploaded those tracks.
+func (in *Ingester) IngestPlaylists(ctx context.Context, person repo.Person) error {
+	person, err := in.resolve(ctx, person)
+	if err != nil {
+		return err
+	}
+
+	playlists, err := in.sc.GetUserPlaylists(ctx, person.Urn, in.limit)
+	if err != nil {
+		return err
+	}
+
+	ids := map[string]int64{person.Username: person.Id}
+	for _, playlist := range playlists {
+		if playlist.Urn == nil {
+			continue
+		}
+
+		tracks, err := in.sc.GetPlaylistTracks(ctx, *playlist.Urn)
+		if err != nil {
+			slog.Error("error getting playlist tracks", "playlist", *playlist.Urn, "error", err)
+			continue
+		}
+
+		stored := make([]repo.Track, 0, len(tracks))
+		urns := make([]string, 0, len(tracks))
+		for _, t := range tracks {
+			if t.Urn == nil || t.User == nil || t.User.Permalink == nil {
+				continue
+			}
+
+			artistID, ok := ids[*t.User.Permalink]
+			if !ok {
+				artist, err := in.upsertUser(ctx, t.User)
+				if err != nil {
+					slog.Error("error storing artist", "handle", *t.User.Permalink, "error", err)
+					continue
+				}
+				artistID = artist.Id
+				ids[artist.Username] = artistID
+			}
+
+			stored = append(stored, trackFromAPI(&t, artistID))
+			urns = append(urns, *t.Urn)
+		}
+
+		if err := in.tracks.UpsertTracks(ctx, stored); err != nil {
+			return err
+		}
+
+		err = in.playlists.UpsertPlaylist(ctx, repo.Playlist{
+			Urn:          *playlist.Urn,
+			OwnerID:      person.Id,
+			Title:        deref(playlist.Title),
+			PermalinkUrl: deref(playlist.PermalinkUrl),
+		}, urns)
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("ingested playlists", "handle", person.Username, "playlists", len(playlists))
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// IngestProfile stores a person's location, description, website and the
+// links to other platforms listed on their SoundCloud profile.
+func (in *Ingester) IngestProfile(ctx context.Context, person repo.Person) error {
+	person, err := in.resolve(ctx, person)
+	if err != nil {
+		return err
+	}
+
+	user, err := in.sc.GetUser(ctx, person.Urn)
+	if err != nil {
+		return err
+	}
+
+	profile := repo.Profile{
+		PersonID:     person.Id,
+		City:         deref(user.City),
+		Country:      deref(user.Country),
+		Description:  deref(user.Description),
+		Website:      deref(user.Website),
+		WebsiteTitle: deref(user.WebsiteTitle),
+		DiscogsName:  deref(user.DiscogsName),
+	}
+
+	// Upserting replaces every stored link, so a failed lookup must not store the profile
+	links, err := in.sc.GetWebProfiles(ctx, person.Urn, in.limit)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if l.Url == nil {
+			continue
+		}
+		profile.Links = append(profile.Links, repo.ProfileLink{
+			Service:  deref(l.Service),
+			Url:      *l.Url,
+			Username: deref(l.Username),
+			Title:    deref(l.Title),
+		})
+	}
+
+	slog.Info("ingested profile", "handle", person.Username, "links", len(profile.Links))
+
+	return in.profiles.Upsert(ctx, profile)
+}
@@ -0,0 +1,87 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/soundcloud"
+)
+
+// IngestRelated links each of a person's tracks to SoundCloud's related-track
+// recommendations, then rolls the links up into sounds_like edges from the person.
+func (in *Ingester) IngestRelated(ctx context.Context, person repo.Person) error {
+	person, err := in.resolve(ctx, person)
+	if err != nil {
+		return err
+	}
+
+	tracks, err := in.sc.GetUserTracks(ctx, person.Urn, in.limit)
+	if err != nil {
+		return err
+	}
+
+	own := make([]repo.Track, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Urn != nil {
+			own = append(own, trackFromAPI(&track, person.Id))
+		}
+	}
+	if err := in.tracks.UpsertTracks(ctx, own); err != nil {
+		return err
+	}
+
+	ids := map[string]int64{person.Username: person.Id}
+	for _, track := range own {
+		related, err := in.sc.GetRelatedTracks(ctx, track.Urn, in.limit)
+		if err != nil {
+			slog.Error("error getting related tracks", "track", track.Urn, "error", err)
+			continue
+		}
+
+		stored := make([]repo.Track, 0, len(related))
+		urns := make([]string, 0, len(related))
+		for _, r := range related {
+			if r.Urn == nil || r.User == nil || r.User.Permalink == nil {
+				continue
+			}
+
+			artistID, ok := ids[*r.User.Permalink]
+			if !ok {
+				artist, err := in.upsertUser(ctx, r.User)
+				if err != nil {
+					slog.Error("error storing artist", "handle", *r.User.Permalink, "error", err)
+					continue
+				}
+				artistID = artist.Id
+				ids[artist.Username] = artistID
+			}
+
+			stored = append(stored, trackFromAPI(&r, artistID))
+			urns = append(urns, *r.Urn)
+		}
+
+		if err := in.tracks.UpsertTracks(ctx, stored); err != nil {
+			return err
+		}
+		if err := in.tracks.ReplaceRelated(ctx, track.Urn, urns); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("ingested related tracks", "handle", person.Username, "tracks", len(own))
+
+	return in.tracks.RollUpSimilarity(ctx, person.Id)
+}
+
+// trackFromAPI converts a SoundCloud API track to a track uploaded by artistID.
+func trackFromAPI(track *soundcloud.Track, artistID int64) repo.Track {
+	return repo.Track{
+		Urn:          deref(track.Urn),
+		ArtistID:     artistID,
+		Title:        deref(track.Title),
+		Genre:        deref(track.Genre),
+		PermalinkUrl: deref(track.PermalinkUrl),
+		ArtworkUrl:   deref(track.ArtworkUrl),
+	}
+}
@@ -87,7 +87,7 @@ func (ac *AuthController) CreateAuthURL(ctx context.Context) (string, error) {
 	verifier := oauth2.GenerateVerifier()
 	state, err := ac.states.CreateState(ctx, verifier)
 	if err != nil {
-		slog.Error("Saving state to Redis", "error", err)
+		slog.Error("Saving state", "error", err)
 		return "", err
 	}
 
@@ -102,12 +102,12 @@ func (ac *AuthController) ObtainToken(
 ) (string, error) {
 	verifier, found, err := ac.states.GetVerifier(ctx, state)
 	if err != nil {
-		slog.Error("Retrieving state from Redis", "error", err)
+		slog.Error("Retrieving state", "error", err)
 		return "", err
 	}
 
 	if !found {
-		slog.Error("State not found in Redis")
+		slog.Error("State not found")
 		return "", ErrStateNotFound
 	}
 
@@ -0,0 +1,175 @@
+// Package cache caches query results in Redis under versioned keys, so that commands which
+// change the graph invalidate every result at once by starting a new generation.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// generationKey holds the current generation. Entries are keyed under it, so that entries
+// of older generations are never read again, and expire with their TTL.
+const generationKey = "cache:generation"
+
+// Store reads and writes cache entries and the generation.
+type Store interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd
+	Incr(ctx context.Context, key string) *redis.IntCmd
+}
+
+// QueryStats counts how often the results of a query were found cached.
+type QueryStats struct {
+	Query  string
+	Hits   int64
+	Misses int64
+}
+
+// HitRate is the share of lookups answered from the cache.
+func (qs QueryStats) HitRate() float64 {
+	if qs.Hits+qs.Misses == 0 {
+		return 0
+	}
+	return float64(qs.Hits) / float64(qs.Hits+qs.Misses)
+}
+
+type counters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Cache caches query results for a TTL. It counts hits and misses by query since it was
+// created.
+type Cache struct {
+	kv  Store
+	ttl time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*counters
+}
+
+// NewCache creates a new Cache.
+func NewCache(kv Store, ttl time.Duration) *Cache {
+	return &Cache{kv: kv, ttl: ttl, stats: make(map[string]*counters)}
+}
+
+// Generation returns the current generation, 0 until the graph first changes.
+func (c *Cache) Generation(ctx context.Context) (int64, error) {
+	gen, err := c.kv.Get(ctx, generationKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return gen, err
+}
+
+// Invalidate starts a new generation, so that every entry cached before is missed.
+func (c *Cache) Invalidate(ctx context.Context) (generation int64, err error) {
+	return c.kv.Incr(ctx, generationKey).Result()
+}
+
+// Stats returns the hits and misses of every query looked up, by query name.
+func (c *Cache) Stats() []QueryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make([]QueryStats, 0, len(c.stats))
+	for query, n := range c.stats {
+		stats = append(stats, QueryStats{Query: query, Hits: n.hits.Load(), Misses: n.misses.Load()})
+	}
+	slices.SortFunc(stats, func(a, b QueryStats) int { return strings.Compare(a.Query, b.Query) })
+	return stats
+}
+
+func (c *Cache) counters(query string) *counters {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.stats[query]
+	if !ok {
+		n = new(counters)
+		c.stats[query] = n
+	}
+	return n
+}
+
+// Fetch returns the result of a query cached under its arguments in the current generation,
+// or loads and caches it. The arguments are encoded into the key as JSON, so they must
+// identify the result. Redis errors are logged and answered by loading, so that the cache
+// never fails a query that the database can answer.
+func Fetch[T any](ctx context.Context, c *Cache, query string, args []any, load func() (T, error)) (T, error) {
+	n := c.counters(query)
+
+	gen, err := c.Generation(ctx)
+	if err != nil {
+		slog.Warn("Reading cache generation", "error", err)
+		n.misses.Add(1)
+		return load()
+	}
+	b, err := json.Marshal(args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	key := fmt.Sprintf("cache:%d:%s:%s", gen, query, b)
+
+	var result T
+	b, err = c.kv.Get(ctx, key).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(b, &result); err == nil {
+			n.hits.Add(1)
+			return result, nil
+		}
+	}
+	if !errors.Is(err, redis.Nil) {
+		slog.Warn("Reading cache entry", "key", key, "error", err)
+	}
+	n.misses.Add(1)
+
+	result, err = load()
+	if err != nil {
+		return result, err
+	}
+	if b, err = json.Marshal(result); err != nil {
+		slog.Warn("Encoding cache entry", "key", key, "error", err)
+		return result, nil
+	}
+	if err = c.kv.Set(ctx, key, b, c.ttl).Err(); err != nil {
+		slog.Warn("Writing cache entry", "key", key, "error", err)
+	}
+	return result, nil
+}
+
+// NotifyChanged starts a new generation in the Redis server at uri. Commands that change the
+// graph call it when they finish, so that servers stop answering from what they cached before.
+// Without a uri, nothing was cached. A failure is only logged, since the change itself is done
+// and stale answers expire with their TTL.
+func NotifyChanged(ctx context.Context, uri string) {
+	if uri == "" {
+		return
+	}
+	rdb, err := data.NewRedisClient(uri)
+	if err != nil {
+		slog.Warn("failed to invalidate cached queries", "error", err)
+		return
+	}
+	defer rdb.Close()
+
+	gen, err := NewCache(rdb, 0).Invalidate(ctx)
+	if err != nil {
+		slog.Warn("failed to invalidate cached queries", "error", err)
+		return
+	}
+	slog.Info("Invalidated cached queries", "generation", gen)
+}
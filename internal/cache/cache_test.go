@@ -0,0 +1,115 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"lopa.to/sonimulus/internal/cache"
+)
+
+// fakeStore is a Store over a map, failing every call while down is set.
+type fakeStore struct {
+	values map[string]string
+	down   bool
+}
+
+var errDown = errors.New("connection refused")
+
+func (fs *fakeStore) Get(_ context.Context, key string) *redis.StringCmd {
+	if fs.down {
+		return redis.NewStringResult("", errDown)
+	}
+	v, ok := fs.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(v, nil)
+}
+
+func (fs *fakeStore) Set(_ context.Context, key string, value any, _ time.Duration) *redis.StatusCmd {
+	if fs.down {
+		return redis.NewStatusResult("", errDown)
+	}
+	fs.values[key] = string(value.([]byte))
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (fs *fakeStore) Incr(_ context.Context, key string) *redis.IntCmd {
+	if fs.down {
+		return redis.NewIntResult(0, errDown)
+	}
+	n, _ := strconv.ParseInt(fs.values[key], 10, 64)
+	fs.values[key] = strconv.FormatInt(n+1, 10)
+	return redis.NewIntResult(n+1, nil)
+}
+
+type result struct {
+	Names []string
+	Total int
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{values: make(map[string]string)}
+	c := cache.NewCache(store, time.Hour)
+
+	loads := 0
+	fetch := func(query string, arg any) result {
+		t.Helper()
+		r, err := cache.Fetch(ctx, c, query, []any{arg}, func() (result, error) {
+			loads++
+			return result{Names: []string{"alice", "bob"}, Total: loads}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	first := fetch("search", "alice bob")
+	if again := fetch("search", "alice bob"); again.Total != first.Total || len(again.Names) != 2 {
+		t.Errorf("got %+v, want the cached %+v", again, first)
+	}
+	fetch("search", "alice")
+	fetch("ego", 1)
+	if loads != 3 {
+		t.Errorf("loaded %d times, want 3", loads)
+	}
+
+	if gen, err := c.Invalidate(ctx); err != nil || gen != 1 {
+		t.Fatalf("gen = %d, err = %v", gen, err)
+	}
+	if r := fetch("search", "alice bob"); r.Total != 4 {
+		t.Errorf("got %+v, want it loaded again after invalidating", r)
+	}
+
+	want := []cache.QueryStats{{Query: "ego", Misses: 1}, {Query: "search", Hits: 1, Misses: 3}}
+	if got := c.Stats(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if rate := want[1].HitRate(); rate != 0.25 {
+		t.Errorf("got hit rate %g, want 0.25", rate)
+	}
+}
+
+func TestFetchWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewCache(&fakeStore{down: true}, time.Hour)
+
+	r, err := cache.Fetch(ctx, c, "top", []any{"pageRank", 50, 0}, func() (result, error) {
+		return result{Total: 7}, nil
+	})
+	if err != nil || r.Total != 7 {
+		t.Errorf("got %+v, %v, want the query answered while Redis is down", r, err)
+	}
+
+	failed := errors.New("query failed")
+	if _, err := cache.Fetch(ctx, c, "top", nil, func() (result, error) { return result{}, failed }); !errors.Is(err, failed) {
+		t.Errorf("got %v, want the query's error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package data_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+func TestMigrationsAreContiguous(t *testing.T) {
+	for _, dialect := range []data.Dialect{data.DialectPostgres, data.DialectSQLite} {
+		t.Run(string(dialect), func(t *testing.T) {
+			migrations, err := data.Migrations(dialect)
+			if err != nil {
+				t.Fatalf("loading migrations: %v", err)
+			}
+			if len(migrations) == 0 {
+				t.Fatal("no migrations embedded")
+			}
+
+			for i, m := range migrations {
+				if m.Version != int64(i+1) {
+					t.Errorf("migration %s has version %d, want %d", m.Name, m.Version, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	db, err := data.NewSQLiteDB(filepath.Join(t.TempDir(), "sonimulus.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer db.Close()
+
+	if dialect := data.DialectOf(db); dialect != data.DialectSQLite {
+		t.Fatalf("DialectOf() = %q, want %q", dialect, data.DialectSQLite)
+	}
+
+	migrations, err := data.Migrations(data.DialectSQLite)
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	applied, err := data.MigrateUp(ctx, db)
+	if err != nil {
+		t.Fatalf("applying migrations: %v", err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("applied %d migrations, want %d", applied, len(migrations))
+	}
+
+	statuses, err := data.MigrationsStatus(ctx, db)
+	if err != nil {
+		t.Fatalf("reading migration status: %v", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			t.Errorf("migration %d_%s is pending after migrating up", s.Version, s.Name)
+		}
+	}
+
+	reverted, err := data.MigrateDown(ctx, db, len(migrations))
+	if err != nil {
+		t.Fatalf("reverting migrations: %v", err)
+	}
+	if reverted != len(migrations) {
+		t.Errorf("reverted %d migrations, want %d", reverted, len(migrations))
+	}
+	if applied, err = data.MigrateUp(ctx, db); err != nil || applied != len(migrations) {
+		t.Errorf("reapplying migrations: applied %d, error %v", applied, err)
+	}
+}
@@ -0,0 +1,4 @@
+DROP FUNCTION new_follows(bigint, text[]);
+DROP FUNCTION new_person(text, text, text, boolean, text, bigint);
+DROP TABLE follows;
+DROP TABLE people;
@@ -0,0 +1,51 @@
+CREATE TABLE people (
+    id          bigserial PRIMARY KEY,
+    username    text NOT NULL UNIQUE,
+    urn         text UNIQUE,
+    name        text NOT NULL DEFAULT '',
+    image_url   text NOT NULL DEFAULT '',
+    verified    boolean NOT NULL DEFAULT false,
+    plan        text NOT NULL DEFAULT 'None' CHECK (plan IN ('None', 'Artist', 'ArtistPro')),
+    track_count bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE follows (
+    follower_id bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    followee_id bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id)
+);
+
+CREATE INDEX follows_followee_id_idx ON follows (followee_id);
+
+-- new_person creates or updates a scraped person keyed by username.
+CREATE FUNCTION new_person(
+    p_username text,
+    p_name text,
+    p_image_url text,
+    p_verified boolean,
+    p_plan text,
+    p_track_count bigint
+) RETURNS SETOF people AS $$
+    INSERT INTO people (username, name, image_url, verified, plan, track_count)
+    VALUES (p_username, p_name, p_image_url, p_verified, COALESCE(NULLIF(p_plan, ''), 'None'), p_track_count)
+    ON CONFLICT (username) DO UPDATE SET
+        name = EXCLUDED.name,
+        image_url = EXCLUDED.image_url,
+        verified = EXCLUDED.verified,
+        plan = EXCLUDED.plan,
+        track_count = EXCLUDED.track_count
+    RETURNING *;
+$$ LANGUAGE sql;
+
+-- new_follows records that a person follows each of the given handles,
+-- creating placeholder people for handles that were not scraped yet.
+CREATE FUNCTION new_follows(p_follower_id bigint, p_followee_handles text[]) RETURNS void AS $$
+    INSERT INTO people (username)
+    SELECT DISTINCT unnest(p_followee_handles)
+    ON CONFLICT (username) DO NOTHING;
+
+    INSERT INTO follows (follower_id, followee_id)
+    SELECT p_follower_id, id FROM people WHERE username = ANY(p_followee_handles)
+    ON CONFLICT DO NOTHING;
+$$ LANGUAGE sql;
@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id         bigint PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    username   text NOT NULL,
+    person_id  bigint REFERENCES people (id) ON DELETE SET NULL,
+    processed  boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX users_person_id_idx ON users (person_id);
@@ -0,0 +1,13 @@
+-- edges holds engagement between people. Follows live in their own table.
+CREATE TABLE edges (
+    source_id  bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    target_id  bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    kind       text NOT NULL,
+    weight     double precision NOT NULL DEFAULT 1,
+    first_at   timestamptz,
+    last_at    timestamptz,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (source_id, target_id, kind)
+);
+
+CREATE INDEX edges_target_id_idx ON edges (target_id, kind);
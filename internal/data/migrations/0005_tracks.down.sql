@@ -0,0 +1,3 @@
+DROP TABLE artist_similarities;
+DROP TABLE related_tracks;
+DROP TABLE tracks;
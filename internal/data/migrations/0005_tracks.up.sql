@@ -0,0 +1,27 @@
+CREATE TABLE tracks (
+    urn           text PRIMARY KEY,
+    artist_id     bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    title         text NOT NULL DEFAULT '',
+    genre         text NOT NULL DEFAULT '',
+    permalink_url text NOT NULL DEFAULT '',
+    artwork_url   text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX tracks_artist_id_idx ON tracks (artist_id);
+
+CREATE TABLE related_tracks (
+    track_urn   text NOT NULL REFERENCES tracks (urn) ON DELETE CASCADE,
+    related_urn text NOT NULL REFERENCES tracks (urn) ON DELETE CASCADE,
+    rank        bigint NOT NULL,
+    PRIMARY KEY (track_urn, related_urn)
+);
+
+CREATE TABLE artist_similarities (
+    source_id  bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    target_id  bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    weight     double precision NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (source_id, target_id)
+);
+
+CREATE INDEX artist_similarities_target_id_idx ON artist_similarities (target_id);
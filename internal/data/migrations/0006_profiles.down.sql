@@ -0,0 +1,2 @@
+DROP TABLE profile_links;
+DROP TABLE profiles;
@@ -0,0 +1,21 @@
+CREATE TABLE profiles (
+    person_id     bigint PRIMARY KEY REFERENCES people (id) ON DELETE CASCADE,
+    city          text NOT NULL DEFAULT '',
+    country       text NOT NULL DEFAULT '',
+    description   text NOT NULL DEFAULT '',
+    website       text NOT NULL DEFAULT '',
+    website_title text NOT NULL DEFAULT '',
+    discogs_name  text NOT NULL DEFAULT '',
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE profile_links (
+    person_id bigint NOT NULL REFERENCES profiles (person_id) ON DELETE CASCADE,
+    service   text NOT NULL DEFAULT '',
+    url       text NOT NULL,
+    username  text NOT NULL DEFAULT '',
+    title     text NOT NULL DEFAULT '',
+    PRIMARY KEY (person_id, url)
+);
+
+CREATE INDEX profile_links_service_idx ON profile_links (service);
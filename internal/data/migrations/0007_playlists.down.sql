@@ -0,0 +1,3 @@
+DROP TABLE artist_cooccurrences;
+DROP TABLE playlist_tracks;
+DROP TABLE playlists;
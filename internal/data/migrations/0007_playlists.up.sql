@@ -0,0 +1,26 @@
+CREATE TABLE playlists (
+    urn           text PRIMARY KEY,
+    owner_id      bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    title         text NOT NULL DEFAULT '',
+    permalink_url text NOT NULL DEFAULT '',
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE playlist_tracks (
+    playlist_urn text NOT NULL REFERENCES playlists (urn) ON DELETE CASCADE,
+    track_urn    text NOT NULL REFERENCES tracks (urn) ON DELETE CASCADE,
+    position     bigint NOT NULL,
+    PRIMARY KEY (playlist_urn, track_urn)
+);
+
+CREATE INDEX playlist_tracks_track_urn_idx ON playlist_tracks (track_urn);
+
+CREATE TABLE artist_cooccurrences (
+    source_id  bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    target_id  bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    weight     double precision NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (source_id, target_id)
+);
+
+CREATE INDEX artist_cooccurrences_target_id_idx ON artist_cooccurrences (target_id);
@@ -0,0 +1 @@
+DROP INDEX follows_created_at_idx;
@@ -0,0 +1,2 @@
+-- follows_created_at_idx lets the in-memory graph engine fetch only the follows created since its last refresh.
+CREATE INDEX follows_created_at_idx ON follows (created_at);
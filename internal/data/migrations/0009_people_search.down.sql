@@ -0,0 +1,2 @@
+DROP INDEX people_name_trgm_idx;
+DROP INDEX people_username_trgm_idx;
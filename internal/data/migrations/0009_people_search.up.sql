@@ -0,0 +1,6 @@
+-- Trigram indexes back fuzzy and prefix search over handles and display names. The
+-- pg_trgm extension is left installed when reverting, since other objects may use it.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX people_username_trgm_idx ON people USING gin (lower(username) gin_trgm_ops);
+CREATE INDEX people_name_trgm_idx ON people USING gin (lower(name) gin_trgm_ops);
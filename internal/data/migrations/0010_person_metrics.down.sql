@@ -0,0 +1 @@
+DROP TABLE person_metrics;
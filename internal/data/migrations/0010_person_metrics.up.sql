@@ -0,0 +1,16 @@
+-- person_metrics holds centrality scores computed over the follow graph by the analyze command.
+CREATE TABLE person_metrics (
+    person_id   bigint PRIMARY KEY REFERENCES people (id) ON DELETE CASCADE,
+    in_degree   integer NOT NULL,
+    out_degree  integer NOT NULL,
+    pagerank    double precision NOT NULL,
+    hub         double precision NOT NULL,
+    authority   double precision NOT NULL,
+    computed_at timestamptz NOT NULL
+);
+
+CREATE INDEX person_metrics_in_degree_idx ON person_metrics (in_degree DESC);
+CREATE INDEX person_metrics_out_degree_idx ON person_metrics (out_degree DESC);
+CREATE INDEX person_metrics_pagerank_idx ON person_metrics (pagerank DESC);
+CREATE INDEX person_metrics_hub_idx ON person_metrics (hub DESC);
+CREATE INDEX person_metrics_authority_idx ON person_metrics (authority DESC);
@@ -0,0 +1,3 @@
+DROP TABLE community_members;
+DROP TABLE communities;
+DROP TABLE community_runs;
@@ -0,0 +1,30 @@
+-- community_runs records each community detection run over the follow graph by the analyze command.
+CREATE TABLE community_runs (
+    id          bigserial PRIMARY KEY,
+    algorithm   text NOT NULL,
+    modularity  double precision NOT NULL,
+    communities integer NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX community_runs_algorithm_created_at_idx ON community_runs (algorithm, created_at DESC);
+
+-- communities summarizes the communities found by a run, numbered from 0 by decreasing size.
+CREATE TABLE communities (
+    run_id       bigint NOT NULL REFERENCES community_runs (id) ON DELETE CASCADE,
+    id           integer NOT NULL,
+    size         integer NOT NULL,
+    top_members  bigint[] NOT NULL,
+    follows      integer NOT NULL,
+    reciprocated integer NOT NULL,
+    PRIMARY KEY (run_id, id)
+);
+
+CREATE TABLE community_members (
+    run_id       bigint NOT NULL REFERENCES community_runs (id) ON DELETE CASCADE,
+    person_id    bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    community_id integer NOT NULL,
+    PRIMARY KEY (run_id, person_id)
+);
+
+CREATE INDEX community_members_community_idx ON community_members (run_id, community_id, person_id);
@@ -0,0 +1,2 @@
+DROP TABLE layout_positions;
+DROP TABLE layout_runs;
@@ -0,0 +1,18 @@
+-- layout_runs records each force-directed layout of the follow graph computed by the layout command.
+CREATE TABLE layout_runs (
+    id          bigserial PRIMARY KEY,
+    nodes       integer NOT NULL,
+    iterations  integer NOT NULL,
+    incremental boolean NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX layout_runs_created_at_idx ON layout_runs (created_at DESC);
+
+CREATE TABLE layout_positions (
+    run_id    bigint NOT NULL REFERENCES layout_runs (id) ON DELETE CASCADE,
+    person_id bigint NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    x         double precision NOT NULL,
+    y         double precision NOT NULL,
+    PRIMARY KEY (run_id, person_id)
+);
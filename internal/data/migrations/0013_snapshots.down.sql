@@ -0,0 +1,3 @@
+DROP TABLE snapshot_follows;
+DROP TABLE snapshot_people;
+DROP TABLE snapshots;
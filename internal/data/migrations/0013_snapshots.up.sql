@@ -0,0 +1,31 @@
+-- snapshots freeze the people and follows tables under a name, so that crawls can be compared.
+-- Snapshots are full copies rather than references, so that they outlive the people they hold.
+CREATE TABLE snapshots (
+    id               bigserial PRIMARY KEY,
+    name             text NOT NULL UNIQUE,
+    people           integer NOT NULL DEFAULT 0,
+    follows          integer NOT NULL DEFAULT 0,
+    community_run_id bigint REFERENCES community_runs (id) ON DELETE SET NULL,
+    created_at       timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX snapshots_created_at_idx ON snapshots (created_at DESC);
+
+CREATE TABLE snapshot_people (
+    snapshot_id bigint NOT NULL REFERENCES snapshots (id) ON DELETE CASCADE,
+    person_id   bigint NOT NULL,
+    username    text NOT NULL,
+    urn         text,
+    name        text NOT NULL,
+    followers   integer NOT NULL,
+    followings  integer NOT NULL,
+    community   integer,
+    PRIMARY KEY (snapshot_id, person_id)
+);
+
+CREATE TABLE snapshot_follows (
+    snapshot_id bigint NOT NULL REFERENCES snapshots (id) ON DELETE CASCADE,
+    follower_id bigint NOT NULL,
+    followee_id bigint NOT NULL,
+    PRIMARY KEY (snapshot_id, follower_id, followee_id)
+);
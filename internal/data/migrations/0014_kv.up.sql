@@ -0,0 +1,9 @@
+-- kv holds sessions and OAuth states when the server keeps them in Postgres rather than Redis.
+-- Expired values are never read, and are deleted by the server in the background.
+CREATE TABLE kv (
+    key        text PRIMARY KEY,
+    value      bytea NOT NULL,
+    expires_at timestamptz
+);
+
+CREATE INDEX kv_expires_at_idx ON kv (expires_at);
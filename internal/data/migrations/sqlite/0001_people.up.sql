@@ -0,0 +1,21 @@
+-- SQLite stores timestamps as text in UTC, with milliseconds so that they sort like Postgres timestamps.
+CREATE TABLE people (
+    id          integer PRIMARY KEY,
+    username    text NOT NULL UNIQUE,
+    urn         text UNIQUE,
+    name        text NOT NULL DEFAULT '',
+    image_url   text NOT NULL DEFAULT '',
+    verified    boolean NOT NULL DEFAULT false,
+    plan        text NOT NULL DEFAULT 'None' CHECK (plan IN ('None', 'Artist', 'ArtistPro')),
+    track_count integer NOT NULL DEFAULT 0
+);
+
+CREATE TABLE follows (
+    follower_id integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    followee_id integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    created_at  timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (follower_id, followee_id)
+);
+
+CREATE INDEX follows_followee_id_idx ON follows (followee_id);
+CREATE INDEX follows_created_at_idx ON follows (created_at);
@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id         integer PRIMARY KEY,
+    created_at timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    username   text NOT NULL,
+    person_id  integer REFERENCES people (id) ON DELETE SET NULL,
+    processed  boolean NOT NULL DEFAULT false
+);
+
+CREATE INDEX users_person_id_idx ON users (person_id);
@@ -0,0 +1,9 @@
+-- kv holds sessions and OAuth states when the server keeps them in SQLite.
+-- expires_at is in Unix milliseconds, and expired values are deleted by the server in the background.
+CREATE TABLE kv (
+    key        text PRIMARY KEY,
+    value      blob NOT NULL,
+    expires_at integer
+);
+
+CREATE INDEX kv_expires_at_idx ON kv (expires_at);
@@ -0,0 +1,8 @@
+DROP TABLE layout_positions;
+DROP TABLE layout_runs;
+DROP TABLE community_members;
+DROP TABLE communities;
+DROP TABLE community_runs;
+DROP TABLE person_metrics;
+DROP TABLE profile_links;
+DROP TABLE profiles;
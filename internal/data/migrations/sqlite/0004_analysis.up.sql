@@ -0,0 +1,73 @@
+-- The analysis commands only write to Postgres. These tables mirror the ones the server reads
+-- profiles and analysis from, so that it answers without them rather than failing.
+CREATE TABLE profiles (
+    person_id     integer PRIMARY KEY REFERENCES people (id) ON DELETE CASCADE,
+    city          text NOT NULL DEFAULT '',
+    country       text NOT NULL DEFAULT '',
+    description   text NOT NULL DEFAULT '',
+    website       text NOT NULL DEFAULT '',
+    website_title text NOT NULL DEFAULT '',
+    discogs_name  text NOT NULL DEFAULT '',
+    updated_at    timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE profile_links (
+    person_id integer NOT NULL REFERENCES profiles (person_id) ON DELETE CASCADE,
+    service   text NOT NULL DEFAULT '',
+    url       text NOT NULL,
+    username  text NOT NULL DEFAULT '',
+    title     text NOT NULL DEFAULT '',
+    PRIMARY KEY (person_id, url)
+);
+
+CREATE TABLE person_metrics (
+    person_id   integer PRIMARY KEY REFERENCES people (id) ON DELETE CASCADE,
+    in_degree   integer NOT NULL,
+    out_degree  integer NOT NULL,
+    pagerank    double precision NOT NULL,
+    hub         double precision NOT NULL,
+    authority   double precision NOT NULL,
+    computed_at timestamp NOT NULL
+);
+
+CREATE TABLE community_runs (
+    id          integer PRIMARY KEY,
+    algorithm   text NOT NULL,
+    modularity  double precision NOT NULL,
+    communities integer NOT NULL,
+    created_at  timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+-- top_members is a JSON array of person ids.
+CREATE TABLE communities (
+    run_id       integer NOT NULL REFERENCES community_runs (id) ON DELETE CASCADE,
+    id           integer NOT NULL,
+    size         integer NOT NULL,
+    top_members  text NOT NULL,
+    follows      integer NOT NULL,
+    reciprocated integer NOT NULL,
+    PRIMARY KEY (run_id, id)
+);
+
+CREATE TABLE community_members (
+    run_id       integer NOT NULL REFERENCES community_runs (id) ON DELETE CASCADE,
+    person_id    integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    community_id integer NOT NULL,
+    PRIMARY KEY (run_id, person_id)
+);
+
+CREATE TABLE layout_runs (
+    id          integer PRIMARY KEY,
+    nodes       integer NOT NULL,
+    iterations  integer NOT NULL,
+    incremental boolean NOT NULL,
+    created_at  timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE layout_positions (
+    run_id    integer NOT NULL REFERENCES layout_runs (id) ON DELETE CASCADE,
+    person_id integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    x         double precision NOT NULL,
+    y         double precision NOT NULL,
+    PRIMARY KEY (run_id, person_id)
+);
@@ -0,0 +1,3 @@
+DROP TABLE artist_cooccurrences;
+DROP TABLE artist_similarities;
+DROP TABLE edges;
@@ -0,0 +1,35 @@
+-- edges holds engagement between people. Follows live in their own table.
+CREATE TABLE edges (
+    source_id  integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    target_id  integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    kind       text NOT NULL,
+    weight     double precision NOT NULL DEFAULT 1,
+    first_at   timestamp,
+    last_at    timestamp,
+    updated_at timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (source_id, target_id, kind)
+);
+
+CREATE INDEX edges_target_id_idx ON edges (target_id, kind);
+
+-- The similarity and playlist layers are read from these tables, and built from the tracks
+-- and playlists ingested later. They are created here so that the server answers before then.
+CREATE TABLE artist_similarities (
+    source_id  integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    target_id  integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    weight     double precision NOT NULL,
+    updated_at timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (source_id, target_id)
+);
+
+CREATE INDEX artist_similarities_target_id_idx ON artist_similarities (target_id);
+
+CREATE TABLE artist_cooccurrences (
+    source_id  integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    target_id  integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    weight     double precision NOT NULL,
+    updated_at timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (source_id, target_id)
+);
+
+CREATE INDEX artist_cooccurrences_target_id_idx ON artist_cooccurrences (target_id);
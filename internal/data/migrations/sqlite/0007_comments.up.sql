@@ -0,0 +1,10 @@
+CREATE TABLE comments (
+    urn          text PRIMARY KEY,
+    track_urn    text NOT NULL,
+    commenter_id integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    artist_id    integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    body         text NOT NULL DEFAULT '',
+    created_at   timestamp NOT NULL
+);
+
+CREATE INDEX comments_artist_id_idx ON comments (artist_id, commenter_id);
@@ -0,0 +1,2 @@
+DROP TABLE related_tracks;
+DROP TABLE tracks;
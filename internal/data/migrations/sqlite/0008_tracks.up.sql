@@ -0,0 +1,19 @@
+-- artist_similarities was created with the edges, so that the server reads it before any
+-- tracks are ingested.
+CREATE TABLE tracks (
+    urn           text PRIMARY KEY,
+    artist_id     integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    title         text NOT NULL DEFAULT '',
+    genre         text NOT NULL DEFAULT '',
+    permalink_url text NOT NULL DEFAULT '',
+    artwork_url   text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX tracks_artist_id_idx ON tracks (artist_id);
+
+CREATE TABLE related_tracks (
+    track_urn   text NOT NULL REFERENCES tracks (urn) ON DELETE CASCADE,
+    related_urn text NOT NULL REFERENCES tracks (urn) ON DELETE CASCADE,
+    rank        integer NOT NULL,
+    PRIMARY KEY (track_urn, related_urn)
+);
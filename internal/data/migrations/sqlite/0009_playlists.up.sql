@@ -0,0 +1,18 @@
+-- artist_cooccurrences was created with the edges, so that the server reads it before any
+-- playlists are ingested.
+CREATE TABLE playlists (
+    urn           text PRIMARY KEY,
+    owner_id      integer NOT NULL REFERENCES people (id) ON DELETE CASCADE,
+    title         text NOT NULL DEFAULT '',
+    permalink_url text NOT NULL DEFAULT '',
+    updated_at    timestamp NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE playlist_tracks (
+    playlist_urn text NOT NULL REFERENCES playlists (urn) ON DELETE CASCADE,
+    track_urn    text NOT NULL REFERENCES tracks (urn) ON DELETE CASCADE,
+    position     integer NOT NULL,
+    PRIMARY KEY (playlist_urn, track_urn)
+);
+
+CREATE INDEX playlist_tracks_track_urn_idx ON playlist_tracks (track_urn);
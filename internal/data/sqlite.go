@@ -0,0 +1,84 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect is the SQL dialect spoken by a database.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// DialectOf returns the dialect of the database db was opened on.
+func DialectOf(db *sql.DB) Dialect {
+	if db == nil {
+		return DialectPostgres
+	}
+	if _, ok := db.Driver().(*sqlite.Driver); ok {
+		return DialectSQLite
+	}
+	return DialectPostgres
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation, in any dialect.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+// NewSQLiteDB opens the SQLite database file at path, creating it if needed, so that the
+// server and scraper run self-contained without a database server. The driver is written
+// in pure Go, so binaries built with CGO_ENABLED=0 still support SQLite.
+//
+// Foreign keys are enforced as in Postgres, and writers wait on each other rather than
+// failing while another process holds the file.
+func NewSQLiteDB(path string) (db *sql.DB, err error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	// Transactions take the write lock when they begin, so that two of them never deadlock
+	// upgrading their read locks
+	params.Set("_txlock", "immediate")
+
+	db, err = sql.Open("sqlite", fmt.Sprintf("file:%s?%s", path, params.Encode()))
+	if err != nil {
+		slog.Error("failed to open database", "error", err)
+		return nil, err
+	}
+
+	slog.Info("Database connection established", "path", path)
+
+	return db, nil
+}
+
+// NewDB opens the database selected by driver: postgres at postgresURI, or sqlite in the
+// file at sqlitePath.
+func NewDB(driver, postgresURI, sqlitePath string) (*sql.DB, error) {
+	switch Dialect(driver) {
+	case DialectPostgres:
+		return NewPostgresDB(postgresURI)
+	case DialectSQLite:
+		return NewSQLiteDB(sqlitePath)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q, want postgres or sqlite", driver)
+	}
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// csvEncoder writes a zip archive holding a nodes.csv list followed by an edges.csv list,
+// as read by Gephi's and Cytoscape's spreadsheet importers. Unknown values are left empty.
+type csvEncoder struct {
+	zw *zip.Writer
+	w  *csv.Writer
+	// inEdges is set once edges.csv is started
+	inEdges bool
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{zw: zip.NewWriter(w)}
+}
+
+// begin starts a file of the archive with a header row.
+func (e *csvEncoder) begin(name string, header []string) error {
+	if e.w != nil {
+		e.w.Flush()
+		if err := e.w.Error(); err != nil {
+			return err
+		}
+	}
+
+	f, err := e.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	e.w = csv.NewWriter(f)
+	return e.w.Write(header)
+}
+
+func (e *csvEncoder) beginNodes() error {
+	header := []string{"id", "label"}
+	for _, a := range nodeAttributes {
+		header = append(header, a.name)
+	}
+	return e.begin("nodes.csv", header)
+}
+
+func (e *csvEncoder) beginEdges() error {
+	if e.w == nil {
+		if err := e.beginNodes(); err != nil {
+			return err
+		}
+	}
+
+	header := []string{"source", "target"}
+	for _, a := range edgeAttributes {
+		header = append(header, a.name)
+	}
+	e.inEdges = true
+	return e.begin("edges.csv", header)
+}
+
+func (e *csvEncoder) WriteNode(p repo.Person) error {
+	if e.inEdges {
+		return errNodeAfterEdge
+	}
+	if e.w == nil {
+		if err := e.beginNodes(); err != nil {
+			return err
+		}
+	}
+
+	record := []string{strconv.FormatInt(p.Id, 10), label(p)}
+	for _, a := range nodeAttributes {
+		var value string
+		if v := a.value(p); v != nil {
+			value = formatValue(v)
+		}
+		record = append(record, value)
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) WriteEdge(edge repo.Edge) error {
+	if !e.inEdges {
+		if err := e.beginEdges(); err != nil {
+			return err
+		}
+	}
+
+	record := []string{strconv.FormatInt(edge.SourceID, 10), strconv.FormatInt(edge.TargetID, 10)}
+	for _, a := range edgeAttributes {
+		record = append(record, formatValue(a.value(edge)))
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Close() error {
+	if !e.inEdges {
+		if err := e.beginEdges(); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// dotQuoter escapes the characters that end or break a quoted DOT string.
+var dotQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotEncoder writes a Graphviz digraph, with attributes as node and edge attributes.
+type dotEncoder struct {
+	w *bufio.Writer
+}
+
+func newDOTEncoder(w io.Writer) *dotEncoder {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph sonimulus {\n")
+	return &dotEncoder{w: bw}
+}
+
+func (e *dotEncoder) WriteNode(p repo.Person) error {
+	fmt.Fprintf(e.w, `  %d [label="%s"`, p.Id, dotQuoter.Replace(label(p)))
+	for _, a := range nodeAttributes {
+		if v := a.value(p); v != nil {
+			fmt.Fprintf(e.w, ` %s="%s"`, a.name, dotQuoter.Replace(formatValue(v)))
+		}
+	}
+	// Graphviz reads positions in points from pos, pinned with a trailing !
+	if p.Position != nil {
+		fmt.Fprintf(e.w, ` pos="%s,%s!"`, formatValue(p.Position.X), formatValue(p.Position.Y))
+	}
+	_, err := e.w.WriteString("];\n")
+	return err
+}
+
+func (e *dotEncoder) WriteEdge(edge repo.Edge) error {
+	fmt.Fprintf(e.w, "  %d -> %d [", edge.SourceID, edge.TargetID)
+	for i, a := range edgeAttributes {
+		if i > 0 {
+			e.w.WriteString(" ")
+		}
+		fmt.Fprintf(e.w, `%s="%s"`, a.name, dotQuoter.Replace(formatValue(a.value(edge))))
+	}
+	_, err := e.w.WriteString("];\n")
+	return err
+}
+
+func (e *dotEncoder) Close() error {
+	e.w.WriteString("}\n")
+	return e.w.Flush()
+}
@@ -0,0 +1,199 @@
+// Package export writes people graphs in file formats read by graph analysis tools such as
+// Gephi, Cytoscape, Graphviz and NetworkX.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// Format is a graph file format.
+type Format string
+
+const (
+	// FormatGEXF is Gephi's XML format.
+	FormatGEXF Format = "gexf"
+	// FormatGraphML is the XML format read by Cytoscape, yEd and NetworkX.
+	FormatGraphML Format = "graphml"
+	// FormatDOT is the Graphviz language.
+	FormatDOT Format = "dot"
+	// FormatJSON is the node-link JSON read by d3 and NetworkX.
+	FormatJSON Format = "json"
+	// FormatCSV is a zip archive of a nodes.csv and an edges.csv list.
+	FormatCSV Format = "csv"
+)
+
+// Valid reports whether f is a known format.
+func (f Format) Valid() bool {
+	switch f {
+	case FormatGEXF, FormatGraphML, FormatDOT, FormatJSON, FormatCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentType returns the media type of files in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatGEXF:
+		return "application/gexf+xml"
+	case FormatGraphML:
+		return "application/graphml+xml"
+	case FormatDOT:
+		return "text/vnd.graphviz"
+	case FormatJSON:
+		return "application/json"
+	case FormatCSV:
+		return "application/zip"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Extension returns the file name extension of files in the format, without the dot.
+func (f Format) Extension() string {
+	if f == FormatCSV {
+		return "zip"
+	}
+	return string(f)
+}
+
+// errNodeAfterEdge is returned when a node is written after edges, which no format allows
+// without buffering the whole graph.
+var errNodeAfterEdge = errors.New("nodes must be written before edges")
+
+// Encoder writes a graph, every node first and then every edge.
+type Encoder interface {
+	WriteNode(p repo.Person) error
+	WriteEdge(e repo.Edge) error
+	// Close finishes the document, without closing the underlying writer.
+	Close() error
+}
+
+// NewEncoder creates an Encoder writing to w in a format.
+func NewEncoder(w io.Writer, format Format) (Encoder, error) {
+	switch format {
+	case FormatGEXF:
+		return newGEXFEncoder(w), nil
+	case FormatGraphML:
+		return newGraphMLEncoder(w), nil
+	case FormatDOT:
+		return newDOTEncoder(w), nil
+	case FormatJSON:
+		return newJSONEncoder(w), nil
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	default:
+		return nil, fmt.Errorf("invalid export format %q", format)
+	}
+}
+
+// attributeType is the type of an attribute's values.
+type attributeType int
+
+const (
+	attributeString attributeType = iota
+	attributeInt
+	attributeDouble
+	attributeBool
+)
+
+// attribute is a property of people or edges, written by every format.
+type attribute[T any] struct {
+	name string
+	typ  attributeType
+	// value returns the attribute's value, or nil when it is unknown
+	value func(T) any
+}
+
+// nodeAttributes are the attributes written for every person, including their profile and
+// the analysis attached to them.
+var nodeAttributes = []attribute[repo.Person]{
+	{"username", attributeString, func(p repo.Person) any { return p.Username }},
+	{"urn", attributeString, func(p repo.Person) any { return nonEmpty(p.Urn) }},
+	{"name", attributeString, func(p repo.Person) any { return p.Name }},
+	{"imageUrl", attributeString, func(p repo.Person) any { return nonEmpty(p.ImageUrl) }},
+	{"verified", attributeBool, func(p repo.Person) any { return p.Verified }},
+	{"plan", attributeString, func(p repo.Person) any { return string(p.Plan) }},
+	{"trackCount", attributeInt, func(p repo.Person) any { return p.TrackCount }},
+	{"city", attributeString, func(p repo.Person) any { return profile(p, func(pr *repo.Profile) any { return nonEmpty(pr.City) }) }},
+	{"country", attributeString, func(p repo.Person) any { return profile(p, func(pr *repo.Profile) any { return nonEmpty(pr.Country) }) }},
+	{"inDegree", attributeInt, func(p repo.Person) any { return metric(p, func(m *repo.PersonMetrics) any { return m.InDegree }) }},
+	{"outDegree", attributeInt, func(p repo.Person) any { return metric(p, func(m *repo.PersonMetrics) any { return m.OutDegree }) }},
+	{"pageRank", attributeDouble, func(p repo.Person) any { return metric(p, func(m *repo.PersonMetrics) any { return m.PageRank }) }},
+	{"hub", attributeDouble, func(p repo.Person) any { return metric(p, func(m *repo.PersonMetrics) any { return m.Hub }) }},
+	{"authority", attributeDouble, func(p repo.Person) any { return metric(p, func(m *repo.PersonMetrics) any { return m.Authority }) }},
+	{"community", attributeInt, func(p repo.Person) any {
+		if p.Community == nil {
+			return nil
+		}
+		return *p.Community
+	}},
+	{"x", attributeDouble, func(p repo.Person) any { return position(p, func(pos *repo.Position) any { return pos.X }) }},
+	{"y", attributeDouble, func(p repo.Person) any { return position(p, func(pos *repo.Position) any { return pos.Y }) }},
+}
+
+// edgeAttributes are the attributes written for every edge.
+var edgeAttributes = []attribute[repo.Edge]{
+	{"kind", attributeString, func(e repo.Edge) any { return string(e.Kind) }},
+	{"weight", attributeDouble, func(e repo.Edge) any { return e.Weight }},
+}
+
+func nonEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func profile(p repo.Person, fn func(*repo.Profile) any) any {
+	if p.Profile == nil {
+		return nil
+	}
+	return fn(p.Profile)
+}
+
+func metric(p repo.Person, fn func(*repo.PersonMetrics) any) any {
+	if p.Metrics == nil {
+		return nil
+	}
+	return fn(p.Metrics)
+}
+
+func position(p repo.Person, fn func(*repo.Position) any) any {
+	if p.Position == nil {
+		return nil
+	}
+	return fn(p.Position)
+}
+
+// label returns what tools display for a person.
+func label(p repo.Person) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Username
+}
+
+// formatValue formats an attribute value for text formats.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
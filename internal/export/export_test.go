@@ -0,0 +1,245 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"lopa.to/sonimulus/internal/export"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// testGraph is two people following each other, one of them with a name needing escapes
+// and the analysis of the other.
+func testGraph() ([]repo.Person, []repo.Edge) {
+	community := 3
+	people := []repo.Person{
+		{Id: 1, Username: "ada", Name: `Ada "&" <Lovelace>`, Plan: "Free"},
+		{
+			Id: 2, Username: "bob", Name: "Bob", Plan: "Pro",
+			Profile:   &repo.Profile{City: "Berlin"},
+			Metrics:   &repo.PersonMetrics{InDegree: 1, OutDegree: 1, PageRank: 0.5},
+			Community: &community,
+			Position:  &repo.Position{PersonID: 2, X: 1.5, Y: -2},
+		},
+	}
+	edges := []repo.Edge{
+		{SourceID: 1, TargetID: 2, Kind: repo.EdgeKindFollows, Weight: 1},
+		{SourceID: 2, TargetID: 1, Kind: repo.EdgeKindFollows, Weight: 1},
+	}
+	return people, edges
+}
+
+func encode(t *testing.T, format export.Format) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc, err := export.NewEncoder(&buf, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	people, edges := testGraph()
+	for _, p := range people {
+		if err := enc.WriteNode(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range edges {
+		if err := enc.WriteEdge(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodeGEXF(t *testing.T) {
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID        string `xml:"id,attr"`
+				Label     string `xml:"label,attr"`
+				AttValues []struct {
+					For   string `xml:"for,attr"`
+					Value string `xml:"value,attr"`
+				} `xml:"attvalues>attvalue"`
+			} `xml:"nodes>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edges>edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(encode(t, export.FormatGEXF), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("got %d nodes and %d edges, want 2 and 2", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if got := doc.Graph.Nodes[0].Label; got != `Ada "&" <Lovelace>` {
+		t.Errorf("got label %q", got)
+	}
+	// Unknown values are left out
+	if n := len(doc.Graph.Nodes[1].AttValues); n <= len(doc.Graph.Nodes[0].AttValues) {
+		t.Errorf("got %d values for the analyzed person, want more than %d", n, len(doc.Graph.Nodes[0].AttValues))
+	}
+}
+
+func TestEncodeGraphML(t *testing.T) {
+	var doc struct {
+		Keys []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"key"`
+		Graph struct {
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []struct {
+				ID   string `xml:"id,attr"`
+				Data []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(encode(t, export.FormatGraphML), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Graph.EdgeDefault != "directed" || len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("got %+v", doc.Graph)
+	}
+	keys := make(map[string]bool)
+	for _, k := range doc.Keys {
+		keys[k.ID] = true
+	}
+	for _, d := range doc.Graph.Nodes[1].Data {
+		if !keys[d.Key] {
+			t.Errorf("got data for undeclared key %q", d.Key)
+		}
+	}
+}
+
+func TestEncodeDOT(t *testing.T) {
+	got := string(encode(t, export.FormatDOT))
+
+	for _, want := range []string{
+		"digraph sonimulus {\n",
+		`1 [label="Ada \"&\" <Lovelace>"`,
+		`pos="1.5,-2!"`,
+		"1 -> 2 [",
+		"2 -> 1 [",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "}\n") {
+		t.Errorf("got unterminated graph\n%s", got)
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	var doc struct {
+		Directed bool             `json:"directed"`
+		Nodes    []map[string]any `json:"nodes"`
+		Links    []struct {
+			Source int64  `json:"source"`
+			Target int64  `json:"target"`
+			Kind   string `json:"kind"`
+		} `json:"links"`
+	}
+	if err := json.Unmarshal(encode(t, export.FormatJSON), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !doc.Directed || len(doc.Nodes) != 2 || len(doc.Links) != 2 {
+		t.Fatalf("got %+v", doc)
+	}
+	if doc.Nodes[1]["pageRank"] != 0.5 || doc.Nodes[1]["community"] != 3.0 {
+		t.Errorf("got %v, want the person's analysis", doc.Nodes[1])
+	}
+	if _, ok := doc.Nodes[0]["pageRank"]; ok {
+		t.Errorf("got %v, want unknown metrics left out", doc.Nodes[0])
+	}
+	if doc.Links[0].Source != 1 || doc.Links[0].Target != 2 || doc.Links[0].Kind != "follows" {
+		t.Errorf("got %+v", doc.Links[0])
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	b := encode(t, export.FormatCSV)
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := make(map[string][][]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(r).ReadAll()
+		r.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", f.Name, err)
+		}
+		rows[f.Name] = records
+	}
+
+	if len(rows["nodes.csv"]) != 3 || len(rows["edges.csv"]) != 3 {
+		t.Fatalf("got %v, want a header and two rows in each list", rows)
+	}
+	if got := rows["nodes.csv"][1][1]; got != `Ada "&" <Lovelace>` {
+		t.Errorf("got label %q", got)
+	}
+	if got := rows["edges.csv"][0][:2]; got[0] != "source" || got[1] != "target" {
+		t.Errorf("got header %v", got)
+	}
+}
+
+func TestEncodeEmpty(t *testing.T) {
+	for _, format := range []export.Format{export.FormatGEXF, export.FormatGraphML, export.FormatDOT, export.FormatJSON, export.FormatCSV} {
+		var buf bytes.Buffer
+		enc, err := export.NewEncoder(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Close(); err != nil || buf.Len() == 0 {
+			t.Errorf("%s: got %d bytes and %v, want an empty graph", format, buf.Len(), err)
+		}
+	}
+}
+
+func TestNodeAfterEdge(t *testing.T) {
+	people, edges := testGraph()
+	for _, format := range []export.Format{export.FormatGEXF, export.FormatJSON, export.FormatCSV} {
+		var buf bytes.Buffer
+		enc, err := export.NewEncoder(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.WriteEdge(edges[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.WriteNode(people[0]); err == nil {
+			t.Errorf("%s: got no error writing a node after an edge", format)
+		}
+	}
+}
+
+func TestNewEncoderInvalidFormat(t *testing.T) {
+	if _, err := export.NewEncoder(&bytes.Buffer{}, "xlsx"); err == nil {
+		t.Error("got no error for an unknown format")
+	}
+}
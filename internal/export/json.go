@@ -0,0 +1,71 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// jsonEncoder writes node-link JSON, as read by d3 and NetworkX's node_link_graph.
+type jsonEncoder struct {
+	w       *bufio.Writer
+	inEdges bool
+	written bool
+}
+
+func newJSONEncoder(w io.Writer) *jsonEncoder {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`{"directed":true,"multigraph":false,"graph":{"name":"sonimulus"},"nodes":[`)
+	return &jsonEncoder{w: bw}
+}
+
+// writeObject writes an object of the current array, comma separated from the previous one.
+func (e *jsonEncoder) writeObject(v map[string]any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if e.written {
+		e.w.WriteByte(',')
+	}
+	e.written = true
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonEncoder) WriteNode(p repo.Person) error {
+	if e.inEdges {
+		return errNodeAfterEdge
+	}
+
+	node := map[string]any{"id": p.Id, "label": label(p)}
+	for _, a := range nodeAttributes {
+		if v := a.value(p); v != nil {
+			node[a.name] = v
+		}
+	}
+	return e.writeObject(node)
+}
+
+func (e *jsonEncoder) WriteEdge(edge repo.Edge) error {
+	if !e.inEdges {
+		e.w.WriteString(`],"links":[`)
+		e.inEdges, e.written = true, false
+	}
+
+	link := map[string]any{"source": edge.SourceID, "target": edge.TargetID}
+	for _, a := range edgeAttributes {
+		link[a.name] = a.value(edge)
+	}
+	return e.writeObject(link)
+}
+
+func (e *jsonEncoder) Close() error {
+	if !e.inEdges {
+		e.w.WriteString(`],"links":[`)
+	}
+	e.w.WriteString("]}\n")
+	return e.w.Flush()
+}
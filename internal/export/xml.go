@@ -0,0 +1,150 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// escapeXML escapes s for use in XML text and attribute values.
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// gexfTypes names attribute types in GEXF.
+var gexfTypes = map[attributeType]string{
+	attributeString: "string",
+	attributeInt:    "long",
+	attributeDouble: "double",
+	attributeBool:   "boolean",
+}
+
+// gexfEncoder writes GEXF 1.3, with layout positions as viz:position elements.
+type gexfEncoder struct {
+	w       *bufio.Writer
+	inEdges bool
+	edges   int
+}
+
+func newGEXFEncoder(w io.Writer) *gexfEncoder {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	bw.WriteString(`<gexf xmlns="http://gexf.net/1.3" xmlns:viz="http://gexf.net/1.3/viz" version="1.3">` + "\n")
+	bw.WriteString("  <meta><creator>sonimulus</creator></meta>\n")
+	bw.WriteString(`  <graph defaultedgetype="directed" mode="static">` + "\n")
+
+	bw.WriteString(`    <attributes class="node">` + "\n")
+	for _, a := range nodeAttributes {
+		fmt.Fprintf(bw, `      <attribute id="%s" title="%s" type="%s"/>`+"\n", a.name, a.name, gexfTypes[a.typ])
+	}
+	bw.WriteString("    </attributes>\n")
+	// Weights are native to GEXF edges, so only the kind is an attribute
+	bw.WriteString(`    <attributes class="edge">` + "\n")
+	bw.WriteString(`      <attribute id="kind" title="kind" type="string"/>` + "\n")
+	bw.WriteString("    </attributes>\n")
+
+	bw.WriteString("    <nodes>\n")
+	return &gexfEncoder{w: bw}
+}
+
+func (e *gexfEncoder) WriteNode(p repo.Person) error {
+	if e.inEdges {
+		return errNodeAfterEdge
+	}
+
+	fmt.Fprintf(e.w, `      <node id="%d" label="%s">`+"\n", p.Id, escapeXML(label(p)))
+	e.w.WriteString("        <attvalues>\n")
+	for _, a := range nodeAttributes {
+		if v := a.value(p); v != nil {
+			fmt.Fprintf(e.w, `          <attvalue for="%s" value="%s"/>`+"\n", a.name, escapeXML(formatValue(v)))
+		}
+	}
+	e.w.WriteString("        </attvalues>\n")
+	if p.Position != nil {
+		fmt.Fprintf(e.w, `        <viz:position x="%s" y="%s" z="0"/>`+"\n", formatValue(p.Position.X), formatValue(p.Position.Y))
+	}
+	_, err := e.w.WriteString("      </node>\n")
+	return err
+}
+
+func (e *gexfEncoder) WriteEdge(edge repo.Edge) error {
+	if !e.inEdges {
+		e.w.WriteString("    </nodes>\n    <edges>\n")
+		e.inEdges = true
+	}
+
+	_, err := fmt.Fprintf(e.w,
+		`      <edge id="%d" source="%d" target="%d" weight="%s"><attvalues><attvalue for="kind" value="%s"/></attvalues></edge>`+"\n",
+		e.edges, edge.SourceID, edge.TargetID, formatValue(edge.Weight), escapeXML(string(edge.Kind)),
+	)
+	e.edges++
+	return err
+}
+
+func (e *gexfEncoder) Close() error {
+	if !e.inEdges {
+		e.w.WriteString("    </nodes>\n    <edges>\n")
+	}
+	e.w.WriteString("    </edges>\n  </graph>\n</gexf>\n")
+	return e.w.Flush()
+}
+
+// graphMLTypes names attribute types in GraphML.
+var graphMLTypes = map[attributeType]string{
+	attributeString: "string",
+	attributeInt:    "long",
+	attributeDouble: "double",
+	attributeBool:   "boolean",
+}
+
+// graphMLEncoder writes GraphML, with every attribute declared as a key.
+type graphMLEncoder struct {
+	w *bufio.Writer
+}
+
+func newGraphMLEncoder(w io.Writer) *graphMLEncoder {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	bw.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	bw.WriteString(`  <key id="label" for="node" attr.name="label" attr.type="string"/>` + "\n")
+	for _, a := range nodeAttributes {
+		fmt.Fprintf(bw, `  <key id="%s" for="node" attr.name="%s" attr.type="%s"/>`+"\n", a.name, a.name, graphMLTypes[a.typ])
+	}
+	for _, a := range edgeAttributes {
+		fmt.Fprintf(bw, `  <key id="%s" for="edge" attr.name="%s" attr.type="%s"/>`+"\n", a.name, a.name, graphMLTypes[a.typ])
+	}
+	bw.WriteString(`  <graph id="sonimulus" edgedefault="directed">` + "\n")
+	return &graphMLEncoder{w: bw}
+}
+
+func (e *graphMLEncoder) WriteNode(p repo.Person) error {
+	fmt.Fprintf(e.w, `    <node id="%d">`+"\n", p.Id)
+	fmt.Fprintf(e.w, `      <data key="label">%s</data>`+"\n", escapeXML(label(p)))
+	for _, a := range nodeAttributes {
+		if v := a.value(p); v != nil {
+			fmt.Fprintf(e.w, `      <data key="%s">%s</data>`+"\n", a.name, escapeXML(formatValue(v)))
+		}
+	}
+	_, err := e.w.WriteString("    </node>\n")
+	return err
+}
+
+func (e *graphMLEncoder) WriteEdge(edge repo.Edge) error {
+	fmt.Fprintf(e.w, `    <edge source="%d" target="%d">`, edge.SourceID, edge.TargetID)
+	for _, a := range edgeAttributes {
+		fmt.Fprintf(e.w, `<data key="%s">%s</data>`, a.name, escapeXML(formatValue(a.value(edge))))
+	}
+	_, err := e.w.WriteString("</edge>\n")
+	return err
+}
+
+func (e *graphMLEncoder) Close() error {
+	e.w.WriteString("  </graph>\n</graphml>\n")
+	return e.w.Flush()
+}
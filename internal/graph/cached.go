@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+
+	"lopa.to/sonimulus/internal/cache"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// CachedGraphController answers the queries repeated most, ego networks, people with their
+// metrics, search and rankings, from a cache, and every other query from its GraphController.
+type CachedGraphController struct {
+	*GraphController
+	cache *cache.Cache
+}
+
+// NewCachedGraphController creates a new instance of CachedGraphController.
+func NewCachedGraphController(gc *GraphController, c *cache.Cache) *CachedGraphController {
+	return &CachedGraphController{GraphController: gc, cache: c}
+}
+
+// cachedPerson records whether a person was found, as people who are not are cached too.
+type cachedPerson struct {
+	Person repo.Person
+	Found  bool
+}
+
+// Person returns a person together with their profile and analysis, if cached.
+func (cc *CachedGraphController) Person(ctx context.Context, personID int64) (repo.Person, bool, error) {
+	cached, err := cache.Fetch(ctx, cc.cache, "person", []any{personID}, func() (cachedPerson, error) {
+		person, found, err := cc.GraphController.Person(ctx, personID)
+		return cachedPerson{Person: person, Found: found}, err
+	})
+	return cached.Person, cached.Found, err
+}
+
+// EgoNetwork returns an ego network, if cached.
+func (cc *CachedGraphController) EgoNetwork(ctx context.Context, personID int64, radius int, direction repo.Direction, maxNodes int) (EgoNetwork, error) {
+	return cache.Fetch(ctx, cc.cache, "ego", []any{personID, radius, direction, maxNodes}, func() (EgoNetwork, error) {
+		return cc.GraphController.EgoNetwork(ctx, personID, radius, direction, maxNodes)
+	})
+}
+
+// SearchPeople returns the people matching a query, if cached.
+func (cc *CachedGraphController) SearchPeople(ctx context.Context, query string, limit, offset int) ([]repo.Person, error) {
+	return cache.Fetch(ctx, cc.cache, "search", []any{query, limit, offset}, func() ([]repo.Person, error) {
+		return cc.GraphController.SearchPeople(ctx, query, limit, offset)
+	})
+}
+
+// TopPeople returns a page of the people ranking highest by a metric, if cached.
+func (cc *CachedGraphController) TopPeople(ctx context.Context, key repo.MetricKey, limit, offset int) ([]repo.Person, error) {
+	return cache.Fetch(ctx, cc.cache, "top", []any{key, limit, offset}, func() ([]repo.Person, error) {
+		return cc.GraphController.TopPeople(ctx, key, limit, offset)
+	})
+}
+
+// CacheStats returns the hits and misses of every cached query.
+func (cc *CachedGraphController) CacheStats(ctx context.Context) (generation int64, stats []cache.QueryStats, err error) {
+	generation, err = cc.cache.Generation(ctx)
+	return generation, cc.cache.Stats(), err
+}
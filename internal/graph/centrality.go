@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"math"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// CentralityOptions tunes the iterative centrality computations.
+type CentralityOptions struct {
+	// Damping is the probability that a PageRank random walk keeps following follows.
+	Damping float64
+	// MaxIterations bounds the iterations of PageRank and HITS.
+	MaxIterations int
+	// Tolerance stops iterating once scores change by less than it, summed over everyone.
+	Tolerance float64
+}
+
+// DefaultCentralityOptions are the customary PageRank and HITS settings.
+var DefaultCentralityOptions = CentralityOptions{
+	Damping:       0.85,
+	MaxIterations: 100,
+	Tolerance:     1e-9,
+}
+
+// Centrality computes the degrees, PageRank and HITS hub and authority scores of
+// everyone in the follow graph. A follow is read as the follower endorsing the followee.
+func Centrality(c *CSR, opts CentralityOptions) []repo.PersonMetrics {
+	n := len(c.ids)
+	pagerank := c.pageRank(opts)
+	hubs, authorities := c.hits(opts)
+
+	metrics := make([]repo.PersonMetrics, n)
+	for i, id := range c.ids {
+		metrics[i] = repo.PersonMetrics{
+			PersonID:  id,
+			InDegree:  int(c.inOffsets[i+1] - c.inOffsets[i]),
+			OutDegree: int(c.outOffsets[i+1] - c.outOffsets[i]),
+			PageRank:  pagerank[i],
+			Hub:       hubs[i],
+			Authority: authorities[i],
+		}
+	}
+	return metrics
+}
+
+// pageRank returns the PageRank of everyone, summing to 1. The rank of people who
+// follow nobody is spread evenly over everyone.
+func (c *CSR) pageRank(opts CentralityOptions) []float64 {
+	n := len(c.ids)
+	if n == 0 {
+		return nil
+	}
+
+	rank := make([]float64, n)
+	next := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	for range opts.MaxIterations {
+		var dangling float64
+		for i := range n {
+			if c.outOffsets[i+1] == c.outOffsets[i] {
+				dangling += rank[i]
+			}
+		}
+
+		base := (1-opts.Damping)/float64(n) + opts.Damping*dangling/float64(n)
+		var delta float64
+		for j := range n {
+			// Pull rank from followers, each sharing theirs evenly among their followings
+			sum := 0.0
+			for _, i := range c.in[c.inOffsets[j]:c.inOffsets[j+1]] {
+				sum += rank[i] / float64(c.outOffsets[i+1]-c.outOffsets[i])
+			}
+			next[j] = base + opts.Damping*sum
+			delta += math.Abs(next[j] - rank[j])
+		}
+
+		rank, next = next, rank
+		if delta < opts.Tolerance {
+			break
+		}
+	}
+	return rank
+}
+
+// hits returns the HITS hub and authority scores of everyone, each normalized to unit
+// length. Good hubs follow good authorities, and good authorities are followed by good hubs.
+func (c *CSR) hits(opts CentralityOptions) (hubs, authorities []float64) {
+	n := len(c.ids)
+	hubs = make([]float64, n)
+	authorities = make([]float64, n)
+	if n == 0 {
+		return hubs, authorities
+	}
+
+	for i := range hubs {
+		hubs[i] = 1 / math.Sqrt(float64(n))
+	}
+
+	nextHubs := make([]float64, n)
+	for range opts.MaxIterations {
+		for j := range n {
+			sum := 0.0
+			for _, i := range c.in[c.inOffsets[j]:c.inOffsets[j+1]] {
+				sum += hubs[i]
+			}
+			authorities[j] = sum
+		}
+		normalize(authorities)
+
+		for i := range n {
+			sum := 0.0
+			for _, j := range c.out[c.outOffsets[i]:c.outOffsets[i+1]] {
+				sum += authorities[j]
+			}
+			nextHubs[i] = sum
+		}
+		normalize(nextHubs)
+
+		var delta float64
+		for i := range n {
+			delta += math.Abs(nextHubs[i] - hubs[i])
+		}
+		hubs, nextHubs = nextHubs, hubs
+		if delta < opts.Tolerance {
+			break
+		}
+	}
+	return hubs, authorities
+}
+
+// normalize scales v to unit length, unless it is all zeros.
+func normalize(v []float64) {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] /= norm
+	}
+}
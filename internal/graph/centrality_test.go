@@ -0,0 +1,58 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestCentrality(t *testing.T) {
+	// 1 and 2 follow everyone else, 3 is followed by everyone, and 5 follows nobody
+	g := loadEngine(t,
+		[2]int64{1, 2}, [2]int64{1, 3}, [2]int64{1, 4},
+		[2]int64{2, 3}, [2]int64{2, 4},
+		[2]int64{4, 3}, [2]int64{5, 3},
+		[2]int64{3, 5},
+	).Graph()
+
+	metrics := graph.Centrality(g, graph.DefaultCentralityOptions)
+	if len(metrics) != 5 {
+		t.Fatalf("got %d metrics, want 5", len(metrics))
+	}
+
+	byID := make(map[int64]repo.PersonMetrics, len(metrics))
+	var total float64
+	for _, m := range metrics {
+		byID[m.PersonID] = m
+		total += m.PageRank
+	}
+	if math.Abs(total-1) > 1e-6 {
+		t.Errorf("pageranks sum to %v, want 1", total)
+	}
+
+	if m := byID[3]; m.InDegree != 4 || m.OutDegree != 1 {
+		t.Errorf("degrees of 3: got in %d out %d", m.InDegree, m.OutDegree)
+	}
+	for _, id := range []int64{1, 2, 4, 5} {
+		if byID[id].PageRank >= byID[3].PageRank {
+			t.Errorf("pagerank of %d (%v) not below 3 (%v)", id, byID[id].PageRank, byID[3].PageRank)
+		}
+		if byID[id].Authority >= byID[3].Authority {
+			t.Errorf("authority of %d (%v) not below 3 (%v)", id, byID[id].Authority, byID[3].Authority)
+		}
+	}
+	if byID[1].Hub <= byID[5].Hub {
+		t.Errorf("hub of 1 (%v) not above 5 (%v)", byID[1].Hub, byID[5].Hub)
+	}
+	if byID[1].Authority != 0 {
+		t.Errorf("unfollowed person has authority %v", byID[1].Authority)
+	}
+}
+
+func TestCentralityEmpty(t *testing.T) {
+	if got := graph.Centrality(loadEngine(t).Graph(), graph.DefaultCentralityOptions); len(got) != 0 {
+		t.Errorf("got %v", got)
+	}
+}
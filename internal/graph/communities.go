@@ -0,0 +1,435 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+const (
+	// communityTopMembers is how many of the most followed members are kept per community.
+	communityTopMembers = 10
+	// louvainTolerance is the least gain in modularity for which Louvain makes another pass.
+	louvainTolerance = 1e-6
+)
+
+// Communities is a partition of the follow graph into communities.
+type Communities struct {
+	// Membership maps everyone in the follow graph to their community, numbered from 0
+	// by decreasing size.
+	Membership map[int64]int
+	// Sizes counts the members of each community.
+	Sizes []int
+	// Modularity measures how much denser follows are within communities than expected
+	// by chance, from -0.5 to 1.
+	Modularity float64
+}
+
+// Louvain partitions the follow graph into communities by greedily maximizing modularity,
+// moving people between communities and then merging communities into single nodes until
+// no move improves it. Follows are read regardless of direction.
+func Louvain(c *CSR) Communities {
+	base := c.undirected()
+	g := base
+
+	// labels maps each person to a node of the current, possibly merged, graph
+	labels := make([]int32, len(c.ids))
+	for i := range labels {
+		labels[i] = int32(i)
+	}
+
+	for {
+		community, count, moved := g.localMoving()
+		if !moved {
+			break
+		}
+		for i, l := range labels {
+			labels[i] = community[l]
+		}
+		g = g.aggregate(community, count)
+	}
+	return c.communities(base, labels)
+}
+
+// LabelPropagation partitions the follow graph into communities by repeatedly giving each
+// person the community most of their follows are with, for at most maxIterations passes.
+// Follows are read regardless of direction, and mutual follows count twice.
+func LabelPropagation(c *CSR, maxIterations int) Communities {
+	g := c.undirected()
+	n := len(g.degrees)
+
+	labels := make([]int32, n)
+	for i := range labels {
+		labels[i] = int32(i)
+	}
+
+	weightTo := make([]float64, n)
+	var touched []int32
+	for range maxIterations {
+		changed := false
+		for i := range n {
+			touched = touched[:0]
+			for e := g.offsets[i]; e < g.offsets[i+1]; e++ {
+				l := labels[g.adj[e]]
+				if weightTo[l] == 0 {
+					touched = append(touched, l)
+				}
+				weightTo[l] += g.weights[e]
+			}
+
+			// Keep the current label when it is among the heaviest, so that passes settle,
+			// and otherwise take the smallest heaviest label, so that results are stable
+			best := labels[i]
+			for _, l := range touched {
+				switch {
+				case weightTo[l] > weightTo[best]:
+					best = l
+				case weightTo[l] == weightTo[best] && best != labels[i] && l < best:
+					best = l
+				}
+			}
+
+			if best != labels[i] {
+				labels[i], changed = best, true
+			}
+			for _, l := range touched {
+				weightTo[l] = 0
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return c.communities(g, labels)
+}
+
+// Summaries describes each community, with its most followed members and the reciprocity
+// of the follows within it.
+func (cs Communities) Summaries(c *CSR) []repo.Community {
+	members := make([][]int64, len(cs.Sizes))
+	for id, community := range cs.Membership {
+		members[community] = append(members[community], id)
+	}
+	reciprocity := GroupReciprocity(c, cs.Membership)
+
+	summaries := make([]repo.Community, len(cs.Sizes))
+	for community, ids := range members {
+		slices.SortFunc(ids, func(a, b int64) int {
+			if d := c.Degree(b, repo.DirectionIn) - c.Degree(a, repo.DirectionIn); d != 0 {
+				return d
+			}
+			return cmp.Compare(a, b)
+		})
+		summaries[community] = repo.Community{
+			Id:           community,
+			Size:         cs.Sizes[community],
+			TopMembers:   ids[:min(communityTopMembers, len(ids))],
+			Follows:      reciprocity[community].Follows,
+			Reciprocated: reciprocity[community].Reciprocated,
+		}
+	}
+	return summaries
+}
+
+// communities numbers the communities given by labels, one per person by dense index,
+// from 0 by decreasing size, and scores them over g, the undirected follow graph.
+func (c *CSR) communities(g *undirected, labels []int32) Communities {
+	sizes := make(map[int32]int)
+	for _, l := range labels {
+		sizes[l]++
+	}
+	order := make([]int32, 0, len(sizes))
+	for l := range sizes {
+		order = append(order, l)
+	}
+	slices.SortFunc(order, func(a, b int32) int {
+		if sizes[a] != sizes[b] {
+			return sizes[b] - sizes[a]
+		}
+		return cmp.Compare(a, b)
+	})
+
+	ids := make(map[int32]int, len(order))
+	cs := Communities{
+		Membership: make(map[int64]int, len(labels)),
+		Sizes:      make([]int, len(order)),
+	}
+	for id, l := range order {
+		ids[l] = id
+		cs.Sizes[id] = sizes[l]
+	}
+	dense := make([]int32, len(labels))
+	for i, l := range labels {
+		dense[i] = int32(ids[l])
+		cs.Membership[c.ids[i]] = ids[l]
+	}
+	cs.Modularity = g.modularity(dense, len(order))
+	return cs
+}
+
+// undirected is a weighted undirected graph over dense indexes, as community detection
+// reads follows regardless of direction. Self loops are kept apart from adjacency, and
+// count twice towards degrees.
+type undirected struct {
+	offsets []int32
+	adj     []int32
+	weights []float64
+	loops   []float64
+	degrees []float64
+	// total sums the degrees, which is twice the total edge weight
+	total float64
+}
+
+// undirected returns the follow graph with each pair of people who follow each other in
+// either direction joined once, weighing 2 when they follow each other both ways.
+func (c *CSR) undirected() *undirected {
+	n := len(c.ids)
+	g := &undirected{
+		offsets: make([]int32, n+1),
+		loops:   make([]float64, n),
+		degrees: make([]float64, n),
+	}
+	for i := range n {
+		// Both rows are sorted, so they are merged into one
+		out := c.out[c.outOffsets[i]:c.outOffsets[i+1]]
+		in := c.in[c.inOffsets[i]:c.inOffsets[i+1]]
+		for len(out) > 0 || len(in) > 0 {
+			var j int32
+			w := 1.0
+			switch {
+			case len(in) == 0 || (len(out) > 0 && out[0] < in[0]):
+				j, out = out[0], out[1:]
+			case len(out) == 0 || in[0] < out[0]:
+				j, in = in[0], in[1:]
+			default:
+				j, w = out[0], 2
+				out, in = out[1:], in[1:]
+			}
+
+			g.degrees[i] += w
+			if j == int32(i) {
+				g.loops[i] += w
+				continue
+			}
+			g.adj = append(g.adj, j)
+			g.weights = append(g.weights, w)
+		}
+		g.offsets[i+1] = int32(len(g.adj))
+		g.total += g.degrees[i]
+	}
+	return g
+}
+
+// localMoving moves each node to the neighboring community that most improves modularity,
+// until no move does. It returns the community of each node, numbered densely, how many
+// communities there are, and whether any node moved.
+func (g *undirected) localMoving() (community []int32, count int, moved bool) {
+	n := len(g.degrees)
+	community = make([]int32, n)
+	totals := make([]float64, n)
+	for i := range n {
+		community[i] = int32(i)
+		totals[i] = g.degrees[i]
+	}
+	if g.total == 0 {
+		return community, n, false
+	}
+
+	weightTo := make([]float64, n)
+	var touched []int32
+	// Passes stop once they barely improve modularity, as late passes only shuffle a few nodes
+	for improved := true; improved; {
+		improved = false
+		var passGain float64
+		for i := range n {
+			ci := community[i]
+			touched = touched[:0]
+			for e := g.offsets[i]; e < g.offsets[i+1]; e++ {
+				cj := community[g.adj[e]]
+				if weightTo[cj] == 0 {
+					touched = append(touched, cj)
+				}
+				weightTo[cj] += g.weights[e]
+			}
+
+			// The modularity gain of joining a community, up to terms shared by every community
+			totals[ci] -= g.degrees[i]
+			gain := func(cj int32) float64 {
+				return weightTo[cj] - totals[cj]*g.degrees[i]/g.total
+			}
+			best, bestGain := ci, gain(ci)
+			for _, cj := range touched {
+				// Only strict improvements move, so that passes settle
+				if cg := gain(cj); cg > bestGain+1e-12 {
+					best, bestGain = cj, cg
+				}
+			}
+			totals[best] += g.degrees[i]
+
+			if best != ci {
+				community[i] = best
+				passGain += bestGain - gain(ci)
+				moved = true
+			}
+			for _, cj := range touched {
+				weightTo[cj] = 0
+			}
+		}
+		improved = 2*passGain/g.total > louvainTolerance
+	}
+
+	dense := make(map[int32]int32)
+	for i, ci := range community {
+		d, ok := dense[ci]
+		if !ok {
+			d = int32(len(dense))
+			dense[ci] = d
+		}
+		community[i] = d
+	}
+	return community, len(dense), moved
+}
+
+// aggregate merges the nodes of each community into a single node, keeping the weight
+// within a community as a self loop.
+func (g *undirected) aggregate(community []int32, count int) *undirected {
+	members := make([][]int32, count)
+	for i, c := range community {
+		members[c] = append(members[c], int32(i))
+	}
+
+	agg := &undirected{
+		offsets: make([]int32, count+1),
+		loops:   make([]float64, count),
+		degrees: make([]float64, count),
+		total:   g.total,
+	}
+	weightTo := make([]float64, count)
+	var touched []int32
+	for c, nodes := range members {
+		touched = touched[:0]
+		for _, i := range nodes {
+			agg.loops[c] += g.loops[i]
+			agg.degrees[c] += g.degrees[i]
+			for e := g.offsets[i]; e < g.offsets[i+1]; e++ {
+				d := community[g.adj[e]]
+				if d == int32(c) {
+					// Seen from both ends, so counted twice like every self loop
+					agg.loops[c] += g.weights[e]
+					continue
+				}
+				if weightTo[d] == 0 {
+					touched = append(touched, d)
+				}
+				weightTo[d] += g.weights[e]
+			}
+		}
+
+		slices.Sort(touched)
+		for _, d := range touched {
+			agg.adj = append(agg.adj, d)
+			agg.weights = append(agg.weights, weightTo[d])
+			weightTo[d] = 0
+		}
+		agg.offsets[c+1] = int32(len(agg.adj))
+	}
+	return agg
+}
+
+// modularity returns the modularity of a partition of the nodes into count communities.
+func (g *undirected) modularity(community []int32, count int) float64 {
+	if g.total == 0 {
+		return 0
+	}
+
+	internal := make([]float64, count)
+	totals := make([]float64, count)
+	for i, c := range community {
+		internal[c] += g.loops[i]
+		totals[c] += g.degrees[i]
+		for e := g.offsets[i]; e < g.offsets[i+1]; e++ {
+			if community[g.adj[e]] == c {
+				internal[c] += g.weights[e]
+			}
+		}
+	}
+
+	var q float64
+	for c := range count {
+		q += internal[c]/g.total - (totals[c]/g.total)*(totals[c]/g.total)
+	}
+	return q
+}
+
+// Communities returns the latest run of a community detection algorithm, and a page of
+// its communities, largest first. found is false when the algorithm has not run yet.
+func (gc *GraphController) Communities(ctx context.Context, algorithm repo.CommunityAlgorithm, limit, offset int) (run repo.CommunityRun, communities []repo.Community, found bool, err error) {
+	if !algorithm.Valid() {
+		return repo.CommunityRun{}, nil, false, fmt.Errorf("invalid community algorithm %q", algorithm)
+	}
+
+	run, found, err = gc.communities.LatestRun(ctx, algorithm)
+	if err != nil || !found {
+		return repo.CommunityRun{}, nil, false, err
+	}
+
+	communities, err = gc.communities.ListCommunities(ctx, run.Id, limit, offset)
+	if err != nil {
+		return repo.CommunityRun{}, nil, false, err
+	}
+	return run, communities, true, nil
+}
+
+// CommunityMembers returns a page of the members of a community in the latest run of an
+// algorithm, and how many members it has. found is false when there is no such community.
+func (gc *GraphController) CommunityMembers(ctx context.Context, algorithm repo.CommunityAlgorithm, communityID, limit, offset int) (people []repo.Person, total int, found bool, err error) {
+	if !algorithm.Valid() {
+		return nil, 0, false, fmt.Errorf("invalid community algorithm %q", algorithm)
+	}
+
+	run, found, err := gc.communities.LatestRun(ctx, algorithm)
+	if err != nil || !found {
+		return nil, 0, false, err
+	}
+	community, found, err := gc.communities.FindCommunity(ctx, run.Id, communityID)
+	if err != nil || !found {
+		return nil, 0, false, err
+	}
+
+	ids, err := gc.communities.ListMembers(ctx, run.Id, communityID, limit, offset)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if len(ids) == 0 {
+		return nil, community.Size, true, nil
+	}
+
+	people, err = gc.peopleWithProfiles(ctx, ids)
+	return people, community.Size, true, err
+}
+
+// attachCommunities sets the community of every person belonging to one in the latest
+// Louvain run, if there is one.
+func (gc *GraphController) attachCommunities(ctx context.Context, people []repo.Person) error {
+	run, found, err := gc.communities.LatestRun(ctx, repo.CommunityAlgorithmLouvain)
+	if err != nil || !found {
+		return err
+	}
+
+	memberships, err := gc.communities.FindMemberships(ctx, run.Id, personIDs(people))
+	if err != nil {
+		slog.Error("Finding communities", "error", err)
+		return err
+	}
+
+	for i, p := range people {
+		if community, ok := memberships[p.Id]; ok {
+			people[i].Community = &community
+		}
+	}
+	return nil
+}
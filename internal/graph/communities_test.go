@@ -0,0 +1,94 @@
+package graph_test
+
+import (
+	"slices"
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+)
+
+// cliques returns the follows of two groups of four people who all follow each other,
+// joined by a single follow from 4 to 5.
+func cliques() [][2]int64 {
+	var pairs [][2]int64
+	for _, group := range [][]int64{{1, 2, 3, 4}, {5, 6, 7, 8}} {
+		for _, a := range group {
+			for _, b := range group {
+				if a != b {
+					pairs = append(pairs, [2]int64{a, b})
+				}
+			}
+		}
+	}
+	return append(pairs, [2]int64{4, 5})
+}
+
+func TestCommunities(t *testing.T) {
+	g := loadEngine(t, cliques()...).Graph()
+
+	detectors := map[string]func() graph.Communities{
+		"louvain":           func() graph.Communities { return graph.Louvain(g) },
+		"label propagation": func() graph.Communities { return graph.LabelPropagation(g, 100) },
+	}
+	for name, detect := range detectors {
+		cs := detect()
+		if !slices.Equal(cs.Sizes, []int{4, 4}) {
+			t.Fatalf("%s: got sizes %v, want [4 4]", name, cs.Sizes)
+		}
+		for _, group := range [][]int64{{1, 2, 3, 4}, {5, 6, 7, 8}} {
+			for _, id := range group[1:] {
+				if cs.Membership[id] != cs.Membership[group[0]] {
+					t.Errorf("%s: %d and %d are in different communities", name, id, group[0])
+				}
+			}
+		}
+		if cs.Membership[1] == cs.Membership[5] {
+			t.Errorf("%s: the cliques were merged", name)
+		}
+		if cs.Modularity < 0.4 {
+			t.Errorf("%s: modularity %v, want at least 0.4", name, cs.Modularity)
+		}
+	}
+}
+
+func TestCommunitySummaries(t *testing.T) {
+	g := loadEngine(t, cliques()...).Graph()
+	cs := graph.Louvain(g)
+
+	summaries := cs.Summaries(g)
+	if len(summaries) != 2 {
+		t.Fatalf("got %d summaries, want 2", len(summaries))
+	}
+
+	second := summaries[cs.Membership[5]]
+	// 5 is followed from the other clique too
+	if second.Size != 4 || second.TopMembers[0] != 5 {
+		t.Errorf("got %+v", second)
+	}
+	if second.Follows != 12 || second.Reciprocated != 12 {
+		t.Errorf("got %d follows with %d reciprocated, want 12 and 12", second.Follows, second.Reciprocated)
+	}
+}
+
+func TestCommunitiesEmpty(t *testing.T) {
+	g := loadEngine(t).Graph()
+	if cs := graph.Louvain(g); len(cs.Sizes) != 0 || cs.Modularity != 0 {
+		t.Errorf("got %+v", cs)
+	}
+}
+
+func BenchmarkLouvain(b *testing.B) {
+	g := benchGraph(b)
+	b.ResetTimer()
+	for range b.N {
+		graph.Louvain(g)
+	}
+}
+
+func BenchmarkLabelPropagation(b *testing.B) {
+	g := benchGraph(b)
+	b.ResetTimer()
+	for range b.N {
+		graph.LabelPropagation(g, 100)
+	}
+}
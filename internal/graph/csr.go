@@ -0,0 +1,419 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// refreshOverlap is how far before the watermark a refresh looks for new follows.
+// A follow is stamped when its transaction starts, so one that commits late can
+// carry a created_at older than follows already loaded.
+const refreshOverlap = time.Minute
+
+// FollowScanner streams the follows created after a point in time.
+type FollowScanner interface {
+	ScanFollows(ctx context.Context, since time.Time, fn func(followerID, followeeID int64, createdAt time.Time) error) error
+}
+
+// follow is a follow between two people, by person id.
+type follow struct {
+	from, to int64
+}
+
+// CSR is an immutable follow graph in compressed sparse row form.
+//
+// People are numbered densely in id order. The followings of the person numbered i
+// are out[outOffsets[i]:outOffsets[i+1]], and their followers are stored likewise
+// in in, both sorted. Only people with at least one follow are part of the graph.
+type CSR struct {
+	ids        []int64
+	index      map[int64]int32
+	outOffsets []int32
+	out        []int32
+	inOffsets  []int32
+	in         []int32
+}
+
+// Hop is a person reached by a traversal, and the number of follows it took to reach them.
+type Hop struct {
+	ID    int64
+	Depth int
+}
+
+// newCSR builds a CSR from follows sorted by follower then followee, without duplicates.
+func newCSR(follows []follow) *CSR {
+	var ids []int64
+	for _, f := range follows {
+		ids = append(ids, f.from, f.to)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	c := &CSR{
+		ids:        ids,
+		index:      make(map[int64]int32, len(ids)),
+		outOffsets: make([]int32, len(ids)+1),
+		out:        make([]int32, len(follows)),
+		inOffsets:  make([]int32, len(ids)+1),
+		in:         make([]int32, len(follows)),
+	}
+	for i, id := range ids {
+		c.index[id] = int32(i)
+	}
+
+	for _, f := range follows {
+		c.outOffsets[c.index[f.from]+1]++
+		c.inOffsets[c.index[f.to]+1]++
+	}
+	for i := range ids {
+		c.outOffsets[i+1] += c.outOffsets[i]
+		c.inOffsets[i+1] += c.inOffsets[i]
+	}
+
+	// Follows are sorted, so filling rows in order keeps every row sorted
+	outNext := slices.Clone(c.outOffsets[:len(ids)])
+	inNext := slices.Clone(c.inOffsets[:len(ids)])
+	for _, f := range follows {
+		from, to := c.index[f.from], c.index[f.to]
+		c.out[outNext[from]] = to
+		outNext[from]++
+		c.in[inNext[to]] = from
+		inNext[to]++
+	}
+
+	return c
+}
+
+// hasFollow reports whether a follow is part of the graph.
+func (c *CSR) hasFollow(f follow) bool {
+	from, ok := c.index[f.from]
+	if !ok {
+		return false
+	}
+	to, ok := c.index[f.to]
+	if !ok {
+		return false
+	}
+	_, found := slices.BinarySearch(c.out[c.outOffsets[from]:c.outOffsets[from+1]], to)
+	return found
+}
+
+// withFollows returns a copy of the graph with follows added, which must be sorted by
+// follower then followee and absent from the graph.
+//
+// The rows of the graph are copied once and the added follows merged into them, so that
+// a refresh neither sorts nor renumbers the whole graph. People are only renumbered when
+// the added follows bring new ones.
+func (c *CSR) withFollows(added []follow) *CSR {
+	var newIDs []int64
+	for _, f := range added {
+		if !c.Has(f.from) {
+			newIDs = append(newIDs, f.from)
+		}
+		if !c.Has(f.to) {
+			newIDs = append(newIDs, f.to)
+		}
+	}
+
+	next := &CSR{ids: c.ids, index: c.index}
+	var remap []int32
+	if len(newIDs) > 0 {
+		slices.Sort(newIDs)
+		newIDs = slices.Compact(newIDs)
+
+		// Merging the sorted ids keeps the numbering in id order, so renumbered rows stay sorted
+		next.ids = make([]int64, 0, len(c.ids)+len(newIDs))
+		remap = make([]int32, len(c.ids))
+		for i, j := 0, 0; i < len(c.ids) || j < len(newIDs); {
+			if j == len(newIDs) || (i < len(c.ids) && c.ids[i] < newIDs[j]) {
+				remap[i] = int32(len(next.ids))
+				next.ids = append(next.ids, c.ids[i])
+				i++
+			} else {
+				next.ids = append(next.ids, newIDs[j])
+				j++
+			}
+		}
+		next.index = make(map[int64]int32, len(next.ids))
+		for i, id := range next.ids {
+			next.index[id] = int32(i)
+		}
+	}
+
+	outAdded := make([][2]int32, len(added))
+	inAdded := make([][2]int32, len(added))
+	for k, f := range added {
+		from, to := next.index[f.from], next.index[f.to]
+		outAdded[k] = [2]int32{from, to}
+		inAdded[k] = [2]int32{to, from}
+	}
+	slices.SortFunc(inAdded, func(a, b [2]int32) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	next.outOffsets, next.out = mergeRows(len(next.ids), c.outOffsets, c.out, remap, outAdded)
+	next.inOffsets, next.in = mergeRows(len(next.ids), c.inOffsets, c.in, remap, inAdded)
+	return next
+}
+
+// mergeRows returns the offsets and rows of an adjacency of n people, made of the rows
+// given by offsets and rows, renumbered through remap unless it is nil, and the pairs of
+// row and column in added, sorted and absent from the rows.
+func mergeRows(n int, offsets, rows, remap []int32, added [][2]int32) ([]int32, []int32) {
+	renumber := func(i int32) int32 {
+		if remap == nil {
+			return i
+		}
+		return remap[i]
+	}
+
+	mergedOffsets := make([]int32, n+1)
+	merged := make([]int32, 0, len(rows)+len(added))
+	old := int32(0)
+	for r := range int32(n) {
+		var row []int32
+		if int(old) < len(offsets)-1 && renumber(old) == r {
+			row = rows[offsets[old]:offsets[old+1]]
+			old++
+		}
+		for len(row) > 0 || (len(added) > 0 && added[0][0] == r) {
+			if len(row) > 0 && (len(added) == 0 || added[0][0] != r || renumber(row[0]) < added[0][1]) {
+				merged = append(merged, renumber(row[0]))
+				row = row[1:]
+			} else {
+				merged = append(merged, added[0][1])
+				added = added[1:]
+			}
+		}
+		mergedOffsets[r+1] = int32(len(merged))
+	}
+	return mergedOffsets, merged
+}
+
+// NodeCount returns how many people have at least one follow.
+func (c *CSR) NodeCount() int {
+	return len(c.ids)
+}
+
+// EdgeCount returns how many follows are in the graph.
+func (c *CSR) EdgeCount() int {
+	return len(c.out)
+}
+
+// Has reports whether a person is part of the graph.
+func (c *CSR) Has(personID int64) bool {
+	_, ok := c.index[personID]
+	return ok
+}
+
+// Degree returns how many follows touch a person in the given direction.
+// With DirectionBoth a mutual follow counts twice.
+func (c *CSR) Degree(personID int64, direction repo.Direction) int {
+	i, ok := c.index[personID]
+	if !ok {
+		return 0
+	}
+
+	var degree int32
+	if direction != repo.DirectionIn {
+		degree += c.outOffsets[i+1] - c.outOffsets[i]
+	}
+	if direction != repo.DirectionOut {
+		degree += c.inOffsets[i+1] - c.inOffsets[i]
+	}
+	return int(degree)
+}
+
+// Neighbors returns the people a person follows, is followed by, or both, sorted by id.
+func (c *CSR) Neighbors(personID int64, direction repo.Direction) []int64 {
+	i, ok := c.index[personID]
+	if !ok {
+		return nil
+	}
+
+	var neighbors []int64
+	c.eachNeighbor(i, direction, func(j int32) {
+		neighbors = append(neighbors, c.ids[j])
+	})
+	if direction == repo.DirectionBoth {
+		slices.Sort(neighbors)
+		neighbors = slices.Compact(neighbors)
+	}
+	return neighbors
+}
+
+// KHop returns every person within k follows of a person, breadth first, starting with
+// the person themselves at depth 0. When limit is positive, at most limit people are returned.
+func (c *CSR) KHop(personID int64, k int, direction repo.Direction, limit int) []Hop {
+	start, ok := c.index[personID]
+	if !ok {
+		return nil
+	}
+
+	depths := map[int32]int{start: 0}
+	hops := []Hop{{ID: personID, Depth: 0}}
+	frontier := []int32{start}
+	for depth := 1; depth <= k && len(frontier) > 0; depth++ {
+		var next []int32
+		for _, i := range frontier {
+			c.eachNeighbor(i, direction, func(j int32) {
+				if _, seen := depths[j]; seen || (limit > 0 && len(hops) >= limit) {
+					return
+				}
+				depths[j] = depth
+				hops = append(hops, Hop{ID: c.ids[j], Depth: depth})
+				next = append(next, j)
+			})
+		}
+		frontier = next
+	}
+	return hops
+}
+
+// eachNeighbor calls fn with the dense index of every neighbor of the person numbered i.
+// With DirectionBoth a mutual follow is visited twice.
+func (c *CSR) eachNeighbor(i int32, direction repo.Direction, fn func(j int32)) {
+	if direction != repo.DirectionIn {
+		for _, j := range c.out[c.outOffsets[i]:c.outOffsets[i+1]] {
+			fn(j)
+		}
+	}
+	if direction != repo.DirectionOut {
+		for _, j := range c.in[c.inOffsets[i]:c.inOffsets[i+1]] {
+			fn(j)
+		}
+	}
+}
+
+// Engine keeps the follow graph in memory, so that neighbor, k-hop and path queries
+// never touch the database.
+//
+// Queries run against an immutable CSR snapshot, swapped atomically on every refresh.
+// On a graph of a million follows between a hundred thousand people, neighbor lookups
+// take under a microsecond, two-hop expansions tens of microseconds, shortest paths
+// under a millisecond and a full load under a second; see the benchmarks.
+type Engine struct {
+	scanner   FollowScanner
+	graph     atomic.Pointer[CSR]
+	mu        sync.Mutex
+	watermark time.Time
+}
+
+// NewEngine creates an Engine with an empty graph. Load must be called to fill it.
+func NewEngine(scanner FollowScanner) *Engine {
+	e := &Engine{scanner: scanner}
+	e.graph.Store(newCSR(nil))
+	return e
+}
+
+// Graph returns the current snapshot of the follow graph.
+func (e *Engine) Graph() *CSR {
+	return e.graph.Load()
+}
+
+// ScanFollows streams the follows created after since straight from the database, for
+// selections the snapshot does not keep track of.
+func (e *Engine) ScanFollows(ctx context.Context, since time.Time, fn func(followerID, followeeID int64, createdAt time.Time) error) error {
+	return e.scanner.ScanFollows(ctx, since, fn)
+}
+
+// Load replaces the graph with every follow in the database.
+func (e *Engine) Load(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := time.Now()
+	follows, watermark, err := e.scan(ctx, time.Time{})
+	if err != nil {
+		slog.Error("failed to load follow graph", "error", err)
+		return err
+	}
+
+	slices.SortFunc(follows, compareFollows)
+	graph := newCSR(slices.Compact(follows))
+	e.graph.Store(graph)
+	e.watermark = watermark
+
+	slog.Info("Loaded follow graph", "people", graph.NodeCount(), "follows", graph.EdgeCount(), "duration", time.Since(start))
+	return nil
+}
+
+// Refresh merges the follows created since the last load or refresh into the graph.
+// Removed follows are only dropped by a full Load.
+func (e *Engine) Refresh(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := time.Now()
+	added, watermark, err := e.scan(ctx, e.watermark.Add(-refreshOverlap))
+	if err != nil {
+		slog.Error("failed to refresh follow graph", "error", err)
+		return err
+	}
+	if watermark.After(e.watermark) {
+		e.watermark = watermark
+	}
+
+	old := e.graph.Load()
+	slices.SortFunc(added, compareFollows)
+	added = slices.DeleteFunc(slices.Compact(added), old.hasFollow)
+	if len(added) == 0 {
+		return nil
+	}
+
+	graph := old.withFollows(added)
+	e.graph.Store(graph)
+
+	slog.Info("Refreshed follow graph", "added", graph.EdgeCount()-old.EdgeCount(), "follows", graph.EdgeCount(), "duration", time.Since(start))
+	return nil
+}
+
+// Run loads the graph, then refreshes it every refreshInterval and fully reloads it
+// every reloadInterval, until ctx is done.
+func (e *Engine) Run(ctx context.Context, refreshInterval, reloadInterval time.Duration) {
+	if err := e.Load(ctx); err != nil {
+		slog.Error("initial follow graph load failed", "error", err)
+	}
+
+	refresh := time.NewTicker(refreshInterval)
+	defer refresh.Stop()
+	reload := time.NewTicker(reloadInterval)
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh.C:
+			e.Refresh(ctx)
+		case <-reload.C:
+			e.Load(ctx)
+		}
+	}
+}
+
+// scan reads the follows created after since, and the latest creation time among them.
+func (e *Engine) scan(ctx context.Context, since time.Time) (follows []follow, watermark time.Time, err error) {
+	err = e.scanner.ScanFollows(ctx, since, func(followerID, followeeID int64, createdAt time.Time) error {
+		follows = append(follows, follow{from: followerID, to: followeeID})
+		if createdAt.After(watermark) {
+			watermark = createdAt
+		}
+		return nil
+	})
+	return follows, watermark, err
+}
+
+func compareFollows(a, b follow) int {
+	if a.from != b.from {
+		return cmp.Compare(a.from, b.from)
+	}
+	return cmp.Compare(a.to, b.to)
+}
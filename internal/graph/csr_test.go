@@ -0,0 +1,275 @@
+package graph_test
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+type scannedFollow struct {
+	from, to  int64
+	createdAt time.Time
+}
+
+// fakeScanner serves follows from memory, like the follows table.
+type fakeScanner struct {
+	mu      sync.Mutex
+	follows []scannedFollow
+}
+
+func (fs *fakeScanner) add(createdAt time.Time, pairs ...[2]int64) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for _, p := range pairs {
+		fs.follows = append(fs.follows, scannedFollow{from: p[0], to: p[1], createdAt: createdAt})
+	}
+}
+
+func (fs *fakeScanner) ScanFollows(_ context.Context, since time.Time, fn func(followerID, followeeID int64, createdAt time.Time) error) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for _, f := range fs.follows {
+		if f.createdAt.After(since) {
+			if err := fn(f.from, f.to, f.createdAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newTestEngine loads an engine over the graph 1 -> 2 -> 3 -> 4, 1 -> 5, 5 -> 1, and 6 -> 7.
+func newTestEngine(t *testing.T) (*graph.Engine, *fakeScanner) {
+	t.Helper()
+
+	scanner := &fakeScanner{}
+	scanner.add(time.Now().Add(-time.Hour), [2]int64{1, 2}, [2]int64{2, 3}, [2]int64{3, 4}, [2]int64{1, 5}, [2]int64{5, 1}, [2]int64{6, 7}, [2]int64{1, 2})
+
+	engine := graph.NewEngine(scanner)
+	if err := engine.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return engine, scanner
+}
+
+func TestEngineNeighbors(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	g := engine.Graph()
+
+	if g.NodeCount() != 7 || g.EdgeCount() != 6 {
+		t.Fatalf("got %d people and %d follows, want 7 and 6", g.NodeCount(), g.EdgeCount())
+	}
+
+	tests := []struct {
+		direction repo.Direction
+		want      []int64
+		degree    int
+	}{
+		{repo.DirectionOut, []int64{2, 5}, 2},
+		{repo.DirectionIn, []int64{5}, 1},
+		{repo.DirectionBoth, []int64{2, 5}, 3},
+	}
+	for _, tt := range tests {
+		if got := g.Neighbors(1, tt.direction); !slices.Equal(got, tt.want) {
+			t.Errorf("neighbors %s: got %v, want %v", tt.direction, got, tt.want)
+		}
+		if got := g.Degree(1, tt.direction); got != tt.degree {
+			t.Errorf("degree %s: got %d, want %d", tt.direction, got, tt.degree)
+		}
+	}
+
+	if g.Has(42) || g.Neighbors(42, repo.DirectionOut) != nil {
+		t.Error("unknown person is part of the graph")
+	}
+}
+
+func TestEngineKHop(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	g := engine.Graph()
+
+	got := g.KHop(1, 2, repo.DirectionOut, 0)
+	want := []graph.Hop{{ID: 1, Depth: 0}, {ID: 2, Depth: 1}, {ID: 5, Depth: 1}, {ID: 3, Depth: 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := g.KHop(1, 2, repo.DirectionOut, 2); len(got) != 2 {
+		t.Errorf("limit 2: got %v", got)
+	}
+}
+
+func TestEngineShortestPath(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	g := engine.Graph()
+
+	tests := []struct {
+		from, to  int64
+		direction repo.Direction
+		want      []int64
+		found     bool
+	}{
+		{1, 4, repo.DirectionOut, []int64{1, 2, 3, 4}, true},
+		{4, 1, repo.DirectionOut, nil, false},
+		{4, 1, repo.DirectionIn, []int64{4, 3, 2, 1}, true},
+		{5, 3, repo.DirectionOut, []int64{5, 1, 2, 3}, true},
+		{1, 6, repo.DirectionBoth, nil, false},
+		{1, 1, repo.DirectionOut, []int64{1}, true},
+	}
+	for _, tt := range tests {
+		got, found := g.ShortestPath(tt.from, tt.to, tt.direction)
+		if found != tt.found || !slices.Equal(got, tt.want) {
+			t.Errorf("%d to %d %s: got %v (%v), want %v (%v)", tt.from, tt.to, tt.direction, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestEngineRefresh(t *testing.T) {
+	engine, scanner := newTestEngine(t)
+	before := engine.Graph()
+
+	scanner.add(time.Now(), [2]int64{4, 8}, [2]int64{2, 3})
+	if err := engine.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	after := engine.Graph()
+	if after.EdgeCount() != 7 || after.NodeCount() != 8 {
+		t.Errorf("got %d people and %d follows, want 8 and 7", after.NodeCount(), after.EdgeCount())
+	}
+	if path, found := after.ShortestPath(1, 8, repo.DirectionOut); !found || len(path) != 5 {
+		t.Errorf("path to new person: got %v (%v)", path, found)
+	}
+	// Snapshots taken before the refresh are left untouched
+	if before.Has(8) {
+		t.Error("refresh modified an earlier snapshot")
+	}
+}
+
+// TestEngineRefreshMatchesLoad checks that merging follows into the graph gives the same
+// graph as loading every follow from scratch.
+func TestEngineRefreshMatchesLoad(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomPairs := func(n int, people int64) [][2]int64 {
+		pairs := make([][2]int64, n)
+		for i := range pairs {
+			pairs[i] = [2]int64{rng.Int63n(people) + 1, rng.Int63n(people) + 1}
+		}
+		return pairs
+	}
+
+	scanner := &fakeScanner{}
+	scanner.add(time.Now().Add(-time.Hour), randomPairs(200, 50)...)
+	engine := graph.NewEngine(scanner)
+	if err := engine.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Later follows reach people beyond the first fifty, and repeat some earlier ones
+	for round := range 5 {
+		scanner.add(time.Now(), randomPairs(40, int64(60+10*round))...)
+		if err := engine.Refresh(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded := graph.NewEngine(scanner)
+		if err := loaded.Load(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		got, want := engine.Graph(), loaded.Graph()
+		if got.NodeCount() != want.NodeCount() || got.EdgeCount() != want.EdgeCount() {
+			t.Fatalf("round %d: got %d people and %d follows, want %d and %d", round, got.NodeCount(), got.EdgeCount(), want.NodeCount(), want.EdgeCount())
+		}
+		for id := range int64(100) {
+			for _, direction := range []repo.Direction{repo.DirectionOut, repo.DirectionIn} {
+				if g, w := got.Neighbors(id, direction), want.Neighbors(id, direction); !slices.Equal(g, w) {
+					t.Errorf("round %d: %s neighbors of %d: got %v, want %v", round, direction, id, g, w)
+				}
+			}
+		}
+	}
+}
+
+var (
+	benchOnce   sync.Once
+	benchEngine *graph.Engine
+)
+
+const (
+	benchPeople  = 100_000
+	benchFollows = 1_000_000
+)
+
+// benchGraph loads a random graph of benchFollows follows between benchPeople people,
+// where popular people attract more followers, as on SoundCloud.
+func benchGraph(b *testing.B) *graph.CSR {
+	benchOnce.Do(func() {
+		rng := rand.New(rand.NewSource(1))
+		scanner := &fakeScanner{}
+		now := time.Now()
+		pairs := make([][2]int64, 0, benchFollows)
+		for range benchFollows {
+			from := rng.Int63n(benchPeople) + 1
+			// Squaring skews followees towards low ids
+			r := rng.Float64()
+			to := int64(r*r*benchPeople) + 1
+			pairs = append(pairs, [2]int64{from, to})
+		}
+		scanner.add(now, pairs...)
+
+		benchEngine = graph.NewEngine(scanner)
+		if err := benchEngine.Load(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	})
+	return benchEngine.Graph()
+}
+
+func BenchmarkEngineNeighbors(b *testing.B) {
+	g := benchGraph(b)
+	b.ResetTimer()
+	for i := range b.N {
+		g.Neighbors(int64(i%benchPeople)+1, repo.DirectionOut)
+	}
+}
+
+func BenchmarkEngineKHop2(b *testing.B) {
+	g := benchGraph(b)
+	b.ResetTimer()
+	for i := range b.N {
+		g.KHop(int64(i%benchPeople)+1, 2, repo.DirectionOut, 0)
+	}
+}
+
+func BenchmarkEngineShortestPath(b *testing.B) {
+	g := benchGraph(b)
+	rng := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for range b.N {
+		g.ShortestPath(rng.Int63n(benchPeople)+1, rng.Int63n(benchPeople)+1, repo.DirectionOut)
+	}
+}
+
+func BenchmarkEngineLoad(b *testing.B) {
+	benchGraph(b)
+	b.ResetTimer()
+	for range b.N {
+		if err := benchEngine.Load(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEngineKShortestPaths(b *testing.B) {
+	g := benchGraph(b)
+	rng := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for range b.N {
+		g.KShortestPaths(rng.Int63n(benchPeople)+1, rng.Int63n(benchPeople)+1, repo.DirectionOut, 3)
+	}
+}
@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// EgoNetwork is the people around a person and the follows between them.
+type EgoNetwork struct {
+	Graph
+	// Total is how many people are within the radius, before truncating to the node cap.
+	Total int
+}
+
+// Truncated reports whether people within the radius were left out.
+func (en EgoNetwork) Truncated() bool {
+	return en.Total > len(en.Nodes)
+}
+
+// Ego returns the person and everyone within radius follows of them in the given direction.
+// When more than maxNodes people are within the radius, the person is kept along with
+// the maxNodes-1 best connected others, by follow degree. total counts everyone within radius.
+func (c *CSR) Ego(personID int64, radius int, direction repo.Direction, maxNodes int) (ids []int64, total int) {
+	hops := c.KHop(personID, radius, direction, 0)
+	if len(hops) == 0 {
+		return nil, 0
+	}
+
+	total = len(hops)
+	if maxNodes > 0 && len(hops) > maxNodes {
+		type ranked struct {
+			Hop
+			degree int
+		}
+		// The person is always the first hop, and always kept
+		others := make([]ranked, 0, len(hops)-1)
+		for _, h := range hops[1:] {
+			others = append(others, ranked{Hop: h, degree: c.Degree(h.ID, repo.DirectionBoth)})
+		}
+		slices.SortStableFunc(others, func(a, b ranked) int {
+			if a.degree != b.degree {
+				return b.degree - a.degree
+			}
+			return a.Depth - b.Depth
+		})
+
+		hops = hops[:1]
+		for _, r := range others[:maxNodes-1] {
+			hops = append(hops, r.Hop)
+		}
+	}
+
+	ids = make([]int64, 0, len(hops))
+	for _, h := range hops {
+		ids = append(ids, h.ID)
+	}
+	return ids, total
+}
+
+// InducedFollows returns every follow between the given people.
+func (c *CSR) InducedFollows(ids []int64) []repo.Edge {
+	members := make(map[int32]bool, len(ids))
+	for _, id := range ids {
+		if i, ok := c.index[id]; ok {
+			members[i] = true
+		}
+	}
+
+	var edges []repo.Edge
+	for i := range members {
+		for _, j := range c.out[c.outOffsets[i]:c.outOffsets[i+1]] {
+			if members[j] {
+				edges = append(edges, repo.Edge{SourceID: c.ids[i], TargetID: c.ids[j], Kind: repo.EdgeKindFollows, Weight: 1})
+			}
+		}
+	}
+	return edges
+}
+
+// EgoNetwork returns the ego network of a person over the in-memory follow graph,
+// with every follow among the returned people.
+func (gc *GraphController) EgoNetwork(ctx context.Context, personID int64, radius int, direction repo.Direction, maxNodes int) (EgoNetwork, error) {
+	switch direction {
+	case repo.DirectionOut, repo.DirectionIn, repo.DirectionBoth:
+	default:
+		return EgoNetwork{}, fmt.Errorf("invalid edge direction %q", direction)
+	}
+
+	g := gc.follows.Graph()
+	ids, total := g.Ego(personID, radius, direction, maxNodes)
+	if len(ids) == 0 {
+		// People without follows are not part of the follow graph, but are their own ego network
+		ids, total = []int64{personID}, 1
+	}
+
+	network, err := gc.withNodes(ctx, ids, g.InducedFollows(ids))
+	if err != nil {
+		return EgoNetwork{}, err
+	}
+	return EgoNetwork{Graph: network, Total: total}, nil
+}
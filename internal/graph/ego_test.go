@@ -0,0 +1,43 @@
+package graph_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestEgo(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	g := engine.Graph()
+
+	ids, total := g.Ego(2, 2, repo.DirectionBoth, 0)
+	slices.Sort(ids)
+	if want := []int64{1, 2, 3, 4, 5}; !slices.Equal(ids, want) || total != 5 {
+		t.Errorf("got %v (%d total), want %v", ids, total, want)
+	}
+
+	// 1 has the highest degree, so it is kept over 3 and 5 when truncating
+	ids, total = g.Ego(2, 2, repo.DirectionBoth, 2)
+	if want := []int64{2, 1}; !slices.Equal(ids, want) || total != 5 {
+		t.Errorf("truncated: got %v (%d total), want %v", ids, total, want)
+	}
+
+	if ids, total := g.Ego(42, 2, repo.DirectionBoth, 0); ids != nil || total != 0 {
+		t.Errorf("unknown person: got %v (%d total)", ids, total)
+	}
+}
+
+func TestInducedFollows(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	var got []string
+	for _, e := range engine.Graph().InducedFollows([]int64{1, 2, 5, 7}) {
+		got = append(got, fmt.Sprintf("%d->%d", e.SourceID, e.TargetID))
+	}
+	slices.Sort(got)
+	if want := []string{"1->2", "1->5", "5->1"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,217 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// exportBatchSize is how many people are loaded at a time while exporting.
+const exportBatchSize = 1000
+
+// ExportScope selects which part of the graph is exported.
+type ExportScope string
+
+const (
+	// ExportScopeAll exports everyone and every follow.
+	ExportScopeAll ExportScope = "all"
+	// ExportScopeEgo exports the ego network of a person.
+	ExportScopeEgo ExportScope = "ego"
+	// ExportScopeCommunity exports the members of a community and the follows between them.
+	ExportScopeCommunity ExportScope = "community"
+	// ExportScopeCrawl exports the follows crawled within a time window, and the people they connect.
+	ExportScopeCrawl ExportScope = "crawl"
+)
+
+// Valid reports whether s is a known export scope.
+func (s ExportScope) Valid() bool {
+	switch s {
+	case ExportScopeAll, ExportScopeEgo, ExportScopeCommunity, ExportScopeCrawl:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExportSelection selects the subgraph to export. Only the fields of its scope are read.
+type ExportSelection struct {
+	Scope ExportScope
+
+	// PersonID, Radius and Direction select an ego network.
+	PersonID  int64
+	Radius    int
+	Direction repo.Direction
+
+	// Algorithm and CommunityID select a community of the latest run of the algorithm.
+	Algorithm   repo.CommunityAlgorithm
+	CommunityID int
+
+	// Since and Until bound when crawled follows were found, Since excluded. A zero Until
+	// has no upper bound.
+	Since time.Time
+	Until time.Time
+}
+
+// GraphWriter receives an exported graph, every node first and then every edge.
+type GraphWriter interface {
+	WriteNode(p repo.Person) error
+	WriteEdge(e repo.Edge) error
+}
+
+// Export writes the selected subgraph of the follow graph to w, with the profiles and
+// analysis of its people. found is false when the selected person or community is unknown.
+func (gc *GraphController) Export(ctx context.Context, sel ExportSelection, w GraphWriter) (found bool, err error) {
+	switch sel.Scope {
+	case ExportScopeAll:
+		return true, gc.exportAll(ctx, w)
+	case ExportScopeEgo:
+		return gc.exportEgo(ctx, sel, w)
+	case ExportScopeCommunity:
+		return gc.exportCommunity(ctx, sel, w)
+	case ExportScopeCrawl:
+		return true, gc.exportCrawl(ctx, sel, w)
+	default:
+		return false, fmt.Errorf("invalid export scope %q", sel.Scope)
+	}
+}
+
+// exportAll pages through everyone, then writes the follows of the in-memory graph.
+func (gc *GraphController) exportAll(ctx context.Context, w GraphWriter) error {
+	g := gc.follows.Graph()
+
+	var afterID int64
+	for {
+		people, err := gc.people.List(ctx, afterID, exportBatchSize)
+		if err != nil {
+			slog.Error("Listing people", "error", err)
+			return err
+		}
+		if len(people) == 0 {
+			break
+		}
+		if err := gc.attachProfiles(ctx, people); err != nil {
+			return err
+		}
+		if err := gc.attachAnalysis(ctx, people); err != nil {
+			return err
+		}
+		for _, p := range people {
+			if err := w.WriteNode(p); err != nil {
+				return err
+			}
+		}
+		afterID = people[len(people)-1].Id
+	}
+
+	for i := range g.ids {
+		for _, j := range g.out[g.outOffsets[i]:g.outOffsets[i+1]] {
+			if err := w.WriteEdge(repo.Edge{SourceID: g.ids[i], TargetID: g.ids[j], Kind: repo.EdgeKindFollows, Weight: 1}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (gc *GraphController) exportEgo(ctx context.Context, sel ExportSelection, w GraphWriter) (bool, error) {
+	network, err := gc.EgoNetwork(ctx, sel.PersonID, sel.Radius, sel.Direction, 0)
+	if err != nil {
+		return false, err
+	}
+	if len(network.Nodes) == 0 {
+		return false, nil
+	}
+	if err := gc.attachProfiles(ctx, network.Nodes); err != nil {
+		return false, err
+	}
+	return true, writeGraph(w, network.Nodes, network.Edges)
+}
+
+func (gc *GraphController) exportCommunity(ctx context.Context, sel ExportSelection, w GraphWriter) (bool, error) {
+	run, found, err := gc.communities.LatestRun(ctx, sel.Algorithm)
+	if err != nil || !found {
+		return false, err
+	}
+	community, found, err := gc.communities.FindCommunity(ctx, run.Id, sel.CommunityID)
+	if err != nil || !found {
+		return false, err
+	}
+
+	ids, err := gc.communities.ListMembers(ctx, run.Id, community.Id, community.Size, 0)
+	if err != nil {
+		return false, err
+	}
+	if err := gc.writePeople(ctx, w, ids); err != nil {
+		return false, err
+	}
+	return true, writeGraph(w, nil, gc.follows.Graph().InducedFollows(ids))
+}
+
+// exportCrawl scans the follows found within the selected window, then writes the people
+// they connect before the follows themselves.
+func (gc *GraphController) exportCrawl(ctx context.Context, sel ExportSelection, w GraphWriter) error {
+	var follows []repo.Edge
+	seen := make(map[int64]bool)
+	err := gc.follows.ScanFollows(ctx, sel.Since, func(followerID, followeeID int64, createdAt time.Time) error {
+		if !sel.Until.IsZero() && createdAt.After(sel.Until) {
+			return nil
+		}
+		follows = append(follows, repo.Edge{SourceID: followerID, TargetID: followeeID, Kind: repo.EdgeKindFollows, Weight: 1})
+		seen[followerID], seen[followeeID] = true, true
+		return nil
+	})
+	if err != nil {
+		slog.Error("Scanning follows", "error", err)
+		return err
+	}
+
+	ids := make([]int64, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	if err := gc.writePeople(ctx, w, ids); err != nil {
+		return err
+	}
+
+	slices.SortFunc(follows, func(a, b repo.Edge) int {
+		if a.SourceID != b.SourceID {
+			return cmp.Compare(a.SourceID, b.SourceID)
+		}
+		return cmp.Compare(a.TargetID, b.TargetID)
+	})
+	return writeGraph(w, nil, follows)
+}
+
+// writePeople loads people by id in batches, with their profiles and analysis, and writes them.
+func (gc *GraphController) writePeople(ctx context.Context, w GraphWriter, ids []int64) error {
+	for batch := range slices.Chunk(ids, exportBatchSize) {
+		people, err := gc.peopleWithProfiles(ctx, batch)
+		if err != nil {
+			return err
+		}
+		if err := writeGraph(w, people, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGraph(w GraphWriter, people []repo.Person, edges []repo.Edge) error {
+	for _, p := range people {
+		if err := w.WriteNode(p); err != nil {
+			return err
+		}
+	}
+	for _, e := range edges {
+		if err := w.WriteEdge(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
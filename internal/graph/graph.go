@@ -0,0 +1,280 @@
+// Package graph serves the SoundCloud social graph built by the scraper and ingesters.
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+type PeopleProvider interface {
+	FindPeopleByIDs(ctx context.Context, ids []int64) (people []repo.Person, err error)
+	List(ctx context.Context, afterID int64, limit int) (people []repo.Person, err error)
+	Search(ctx context.Context, query string, limit, offset int) (people []repo.Person, err error)
+}
+
+type EdgeProvider interface {
+	FindSimilarEdges(ctx context.Context, personID int64, direction repo.Direction) (edges []repo.Edge, err error)
+	FindCoPlaylistEdges(ctx context.Context, personID int64, direction repo.Direction) (edges []repo.Edge, err error)
+}
+
+type ProfileProvider interface {
+	FindByPersonIDs(ctx context.Context, ids []int64) (profiles map[int64]repo.Profile, err error)
+	Search(ctx context.Context, filter repo.ProfileFilter, limit, offset int) (ids []int64, err error)
+}
+
+type MetricsProvider interface {
+	FindByPersonIDs(ctx context.Context, ids []int64) (metrics map[int64]repo.PersonMetrics, err error)
+	TopPeople(ctx context.Context, key repo.MetricKey, limit, offset int) (ids []int64, err error)
+}
+
+type CommunityProvider interface {
+	LatestRun(ctx context.Context, algorithm repo.CommunityAlgorithm) (run repo.CommunityRun, found bool, err error)
+	ListCommunities(ctx context.Context, runID int64, limit, offset int) (communities []repo.Community, err error)
+	FindCommunity(ctx context.Context, runID int64, communityID int) (community repo.Community, found bool, err error)
+	FindMemberships(ctx context.Context, runID int64, ids []int64) (memberships map[int64]int, err error)
+	ListMemberships(ctx context.Context, runID int64) (memberships map[int64]int, err error)
+	ListMembers(ctx context.Context, runID int64, communityID int, limit, offset int) (ids []int64, err error)
+}
+
+type LayoutProvider interface {
+	LatestRun(ctx context.Context) (run repo.LayoutRun, found bool, err error)
+	FindPositions(ctx context.Context, runID int64, ids []int64) (positions map[int64]repo.Position, err error)
+	ListPositions(ctx context.Context, runID int64, limit, offset int) (positions []repo.Position, err error)
+}
+
+// FollowGraph provides the latest in-memory snapshot of the follow graph, and streams
+// follows by when they were found.
+type FollowGraph interface {
+	Graph() *CSR
+	FollowScanner
+}
+
+// Graph is a set of people and the edges between them.
+type Graph struct {
+	Nodes []repo.Person
+	Edges []repo.Edge
+}
+
+// GraphController handles queries over the people graph.
+type GraphController struct {
+	store       GraphStore
+	follows     FollowGraph
+	people      PeopleProvider
+	edges       EdgeProvider
+	profiles    ProfileProvider
+	metrics     MetricsProvider
+	communities CommunityProvider
+	layouts     LayoutProvider
+
+	// tiles is built from the latest layout on demand, guarded by tilesMu
+	tilesMu sync.Mutex
+	tiles   *TileIndex
+}
+
+// NewGraphController creates a new instance of GraphController.
+func NewGraphController(store GraphStore, follows FollowGraph, peopleRepo PeopleProvider, edgesRepo EdgeProvider, profilesRepo ProfileProvider, metricsRepo MetricsProvider, communitiesRepo CommunityProvider, layoutsRepo LayoutProvider) *GraphController {
+	return &GraphController{
+		store:       store,
+		follows:     follows,
+		people:      peopleRepo,
+		edges:       edgesRepo,
+		profiles:    profilesRepo,
+		metrics:     metricsRepo,
+		communities: communitiesRepo,
+		layouts:     layoutsRepo,
+	}
+}
+
+// Person returns a person together with their profile, if one was ingested.
+func (gc *GraphController) Person(ctx context.Context, personID int64) (person repo.Person, found bool, err error) {
+	people, err := gc.peopleWithProfiles(ctx, []int64{personID})
+	if err != nil || len(people) == 0 {
+		return person, false, err
+	}
+	return people[0], true, nil
+}
+
+// SearchProfiles returns the people whose profile matches filter, with their profiles.
+func (gc *GraphController) SearchProfiles(ctx context.Context, filter repo.ProfileFilter, limit, offset int) ([]repo.Person, error) {
+	ids, err := gc.profiles.Search(ctx, filter, limit, offset)
+	if err != nil {
+		slog.Error("Searching profiles", "error", err)
+		return nil, err
+	}
+	return gc.peopleWithProfiles(ctx, ids)
+}
+
+// SearchPeople returns the people whose handle or display name matches query, best matches
+// first, with their profiles and analysis.
+func (gc *GraphController) SearchPeople(ctx context.Context, query string, limit, offset int) ([]repo.Person, error) {
+	people, err := gc.people.Search(ctx, query, limit, offset)
+	if err != nil {
+		slog.Error("Searching people", "error", err)
+		return nil, err
+	}
+
+	if err := gc.attachProfiles(ctx, people); err != nil {
+		return nil, err
+	}
+	if err := gc.attachAnalysis(ctx, people); err != nil {
+		return nil, err
+	}
+	return people, nil
+}
+
+// TopPeople returns a page of the people ranking highest by a metric, with their profiles.
+func (gc *GraphController) TopPeople(ctx context.Context, key repo.MetricKey, limit, offset int) ([]repo.Person, error) {
+	ids, err := gc.metrics.TopPeople(ctx, key, limit, offset)
+	if err != nil {
+		slog.Error("Ranking people", "error", err)
+		return nil, err
+	}
+	return gc.peopleWithProfiles(ctx, ids)
+}
+
+// peopleWithProfiles loads people by id, in the order of ids, and attaches their profiles
+// and analysis.
+func (gc *GraphController) peopleWithProfiles(ctx context.Context, ids []int64) ([]repo.Person, error) {
+	people, err := gc.people.FindPeopleByIDs(ctx, ids)
+	if err != nil {
+		slog.Error("Finding people", "error", err)
+		return nil, err
+	}
+
+	byID := make(map[int64]repo.Person, len(people))
+	for _, p := range people {
+		byID[p.Id] = p
+	}
+
+	ordered := make([]repo.Person, 0, len(people))
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			ordered = append(ordered, p)
+		}
+	}
+
+	if err := gc.attachProfiles(ctx, ordered); err != nil {
+		return nil, err
+	}
+	if err := gc.attachAnalysis(ctx, ordered); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// attachProfiles sets the profile of every person who has one.
+func (gc *GraphController) attachProfiles(ctx context.Context, people []repo.Person) error {
+	profiles, err := gc.profiles.FindByPersonIDs(ctx, personIDs(people))
+	if err != nil {
+		slog.Error("Finding profiles", "error", err)
+		return err
+	}
+
+	for i, p := range people {
+		if profile, ok := profiles[p.Id]; ok {
+			people[i].Profile = &profile
+		}
+	}
+	return nil
+}
+
+// attachAnalysis sets the metrics, community and position computed for every person by
+// the latest analysis and layout runs.
+func (gc *GraphController) attachAnalysis(ctx context.Context, people []repo.Person) error {
+	if err := gc.attachMetrics(ctx, people); err != nil {
+		return err
+	}
+	if err := gc.attachCommunities(ctx, people); err != nil {
+		return err
+	}
+	return gc.attachPositions(ctx, people)
+}
+
+// attachMetrics sets the centrality metrics of every person who has them.
+func (gc *GraphController) attachMetrics(ctx context.Context, people []repo.Person) error {
+	metrics, err := gc.metrics.FindByPersonIDs(ctx, personIDs(people))
+	if err != nil {
+		slog.Error("Finding metrics", "error", err)
+		return err
+	}
+
+	for i, p := range people {
+		if m, ok := metrics[p.Id]; ok {
+			people[i].Metrics = &m
+		}
+	}
+	return nil
+}
+
+func personIDs(people []repo.Person) []int64 {
+	ids := make([]int64, 0, len(people))
+	for _, p := range people {
+		ids = append(ids, p.Id)
+	}
+	return ids
+}
+
+// PersonEdges returns the edges of a person in a layer, together with every person they touch.
+// In the social layer only edges of the given kinds are returned, or edges of every kind if none are given.
+func (gc *GraphController) PersonEdges(ctx context.Context, personID int64, layer repo.Layer, direction repo.Direction, kinds []repo.EdgeKind) (Graph, error) {
+	var (
+		edges []repo.Edge
+		err   error
+	)
+	switch layer {
+	case repo.LayerSocial:
+		if len(kinds) == 0 {
+			kinds = repo.EdgeKinds
+		}
+		edges, err = gc.store.Neighbors(ctx, personID, direction, kinds)
+	case repo.LayerSimilarity:
+		edges, err = gc.edges.FindSimilarEdges(ctx, personID, direction)
+	case repo.LayerPlaylists:
+		edges, err = gc.edges.FindCoPlaylistEdges(ctx, personID, direction)
+	default:
+		err = fmt.Errorf("unknown layer %q", layer)
+	}
+	if err != nil {
+		slog.Error("Finding edges", "error", err)
+		return Graph{}, err
+	}
+
+	return gc.withNodes(ctx, []int64{personID}, edges)
+}
+
+// withNodes builds a Graph from edges, loading every person referenced by them or by ids
+// with their analysis, and flags reciprocal edges.
+func (gc *GraphController) withNodes(ctx context.Context, ids []int64, edges []repo.Edge) (Graph, error) {
+	seen := make(map[int64]bool, len(edges)+len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, e := range edges {
+		seen[e.SourceID] = true
+		seen[e.TargetID] = true
+	}
+
+	all := make([]int64, 0, len(seen))
+	for id := range seen {
+		all = append(all, id)
+	}
+
+	people, err := gc.people.FindPeopleByIDs(ctx, all)
+	if err != nil {
+		slog.Error("Finding people", "error", err)
+		return Graph{}, err
+	}
+
+	// Nodes carry their metrics, communities and positions, so that they can be sized by
+	// importance, colored by community and drawn where the layout placed them
+	if err := gc.attachAnalysis(ctx, people); err != nil {
+		return Graph{}, err
+	}
+
+	gc.markReciprocal(edges)
+	return Graph{Nodes: people, Edges: edges}, nil
+}
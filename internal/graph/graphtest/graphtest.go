@@ -0,0 +1,237 @@
+// Package graphtest checks that graph.GraphStore implementations behave alike.
+package graphtest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// TestGraphStore runs every GraphStore test against stores made by newStore,
+// which must return an empty store for each call.
+func TestGraphStore(t *testing.T, newStore func(t *testing.T) graph.GraphStore) {
+	t.Run("UpsertNodes", func(t *testing.T) { testUpsertNodes(t, newStore(t)) })
+	t.Run("UpsertEdges", func(t *testing.T) { testUpsertEdges(t, newStore(t)) })
+	t.Run("Neighbors", func(t *testing.T) { testNeighbors(t, newStore(t)) })
+	t.Run("Degree", func(t *testing.T) { testDegree(t, newStore(t)) })
+	t.Run("Subgraph", func(t *testing.T) { testSubgraph(t, newStore(t)) })
+}
+
+// seed stores people named by handles, and returns their ids by handle.
+func seed(t *testing.T, store graph.GraphStore, handles ...string) map[string]int64 {
+	t.Helper()
+
+	people := make([]repo.Person, 0, len(handles))
+	for _, h := range handles {
+		people = append(people, repo.Person{Username: h})
+	}
+	stored, err := store.UpsertNodes(context.Background(), people)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make(map[string]int64, len(stored))
+	for _, p := range stored {
+		ids[p.Username] = p.Id
+	}
+	return ids
+}
+
+// describe renders edges without their timestamps, sorted, for comparison.
+func describe(edges []repo.Edge, names map[int64]string) []string {
+	s := make([]string, 0, len(edges))
+	for _, e := range edges {
+		s = append(s, fmt.Sprintf("%s -%s-> %s (%g)", names[e.SourceID], e.Kind, names[e.TargetID], e.Weight))
+	}
+	slices.Sort(s)
+	return s
+}
+
+func invert(ids map[string]int64) map[int64]string {
+	names := make(map[int64]string, len(ids))
+	for name, id := range ids {
+		names[id] = name
+	}
+	return names
+}
+
+func testUpsertNodes(t *testing.T, store graph.GraphStore) {
+	ctx := context.Background()
+
+	stored, err := store.UpsertNodes(ctx, []repo.Person{
+		{Username: "alice", Name: "Alice", Plan: repo.PlanArtist, TrackCount: 3},
+		{Username: "bob"},
+		{Username: "bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("got %d stored people, want 2", len(stored))
+	}
+	if stored[0].Id == stored[1].Id {
+		t.Fatal("people share an id")
+	}
+	// Rows may be returned in any order
+	original := stored[0]
+	if original.Username != "alice" {
+		original = stored[1]
+	}
+
+	// A partial update leaves stored fields untouched
+	updated, err := store.UpsertNodes(ctx, []repo.Person{{Username: "alice", Verified: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := updated[0]
+	if alice.Id != original.Id || alice.Name != "Alice" || alice.Plan != repo.PlanArtist || alice.TrackCount != 3 || !alice.Verified {
+		t.Errorf("got %+v after a partial update", alice)
+	}
+}
+
+func testUpsertEdges(t *testing.T, store graph.GraphStore) {
+	ctx := context.Background()
+	ids := seed(t, store, "alice", "bob")
+
+	for _, weight := range []float64{3, 2} {
+		err := store.UpsertEdges(ctx, []repo.Edge{
+			{SourceID: ids["alice"], TargetID: ids["bob"], Kind: repo.EdgeKindFollows, Weight: 5},
+			{SourceID: ids["alice"], TargetID: ids["bob"], Kind: repo.EdgeKindLikedTrackOf, Weight: weight},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	edges, err := store.Neighbors(ctx, ids["alice"], repo.DirectionOut, repo.EdgeKinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"alice -follows-> bob (1)",
+		"alice -liked_track_of-> bob (3)",
+	}
+	if got := describe(edges, invert(ids)); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	err = store.UpsertEdges(ctx, []repo.Edge{{SourceID: ids["alice"], TargetID: ids["bob"], Kind: repo.EdgeKindSoundsLike, Weight: 1}})
+	if err == nil {
+		t.Error("expected an error for an edge outside the social layer")
+	}
+}
+
+func testNeighbors(t *testing.T, store graph.GraphStore) {
+	ctx := context.Background()
+	ids := seed(t, store, "alice", "bob", "carol")
+
+	err := store.UpsertEdges(ctx, []repo.Edge{
+		{SourceID: ids["alice"], TargetID: ids["bob"], Kind: repo.EdgeKindFollows},
+		{SourceID: ids["carol"], TargetID: ids["alice"], Kind: repo.EdgeKindFollows},
+		{SourceID: ids["carol"], TargetID: ids["alice"], Kind: repo.EdgeKindCommentedOn, Weight: 4},
+		{SourceID: ids["bob"], TargetID: ids["carol"], Kind: repo.EdgeKindFollows},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		direction repo.Direction
+		kinds     []repo.EdgeKind
+		want      []string
+	}{
+		{"out", repo.DirectionOut, repo.EdgeKinds, []string{"alice -follows-> bob (1)"}},
+		{"in", repo.DirectionIn, repo.EdgeKinds, []string{"carol -commented_on-> alice (4)", "carol -follows-> alice (1)"}},
+		{"both follows", repo.DirectionBoth, []repo.EdgeKind{repo.EdgeKindFollows}, []string{"alice -follows-> bob (1)", "carol -follows-> alice (1)"}},
+	}
+	names := invert(ids)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edges, err := store.Neighbors(ctx, ids["alice"], tt.direction, tt.kinds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describe(edges, names); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	edges, err := store.Neighbors(ctx, ids["alice"], repo.DirectionIn, repo.EdgeKinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) > 0 && edges[0].Kind != repo.EdgeKindCommentedOn {
+		t.Errorf("heaviest edge is not first: %v", edges)
+	}
+
+	if _, err := store.Neighbors(ctx, ids["alice"], repo.Direction("sideways"), repo.EdgeKinds); err == nil {
+		t.Error("expected an error for an invalid direction")
+	}
+}
+
+func testDegree(t *testing.T, store graph.GraphStore) {
+	ctx := context.Background()
+	ids := seed(t, store, "alice", "bob", "carol")
+
+	err := store.UpsertEdges(ctx, []repo.Edge{
+		{SourceID: ids["alice"], TargetID: ids["bob"], Kind: repo.EdgeKindFollows},
+		{SourceID: ids["alice"], TargetID: ids["carol"], Kind: repo.EdgeKindFollows},
+		{SourceID: ids["bob"], TargetID: ids["alice"], Kind: repo.EdgeKindFollows},
+		{SourceID: ids["bob"], TargetID: ids["alice"], Kind: repo.EdgeKindRepostedTrackOf, Weight: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		direction repo.Direction
+		kinds     []repo.EdgeKind
+		want      int
+	}{
+		{repo.DirectionOut, repo.EdgeKinds, 2},
+		{repo.DirectionIn, repo.EdgeKinds, 2},
+		{repo.DirectionIn, []repo.EdgeKind{repo.EdgeKindFollows}, 1},
+		{repo.DirectionBoth, repo.EdgeKinds, 4},
+	}
+	for _, tt := range tests {
+		got, err := store.Degree(ctx, ids["alice"], tt.direction, tt.kinds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("degree %s %v: got %d, want %d", tt.direction, tt.kinds, got, tt.want)
+		}
+	}
+}
+
+func testSubgraph(t *testing.T, store graph.GraphStore) {
+	ctx := context.Background()
+	ids := seed(t, store, "alice", "bob", "carol")
+
+	err := store.UpsertEdges(ctx, []repo.Edge{
+		{SourceID: ids["alice"], TargetID: ids["bob"], Kind: repo.EdgeKindFollows},
+		{SourceID: ids["bob"], TargetID: ids["alice"], Kind: repo.EdgeKindLikedTrackOf, Weight: 2},
+		{SourceID: ids["bob"], TargetID: ids["carol"], Kind: repo.EdgeKindFollows},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	people, edges, err := store.Subgraph(ctx, []int64{ids["alice"], ids["bob"], -1}, []repo.EdgeKind{repo.EdgeKindFollows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 2 {
+		t.Errorf("got %d people, want 2", len(people))
+	}
+	want := []string{"alice -follows-> bob (1)"}
+	if got := describe(edges, invert(ids)); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,312 @@
+package graph
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// LayoutOptions tunes the ForceAtlas2 layout.
+type LayoutOptions struct {
+	Iterations int
+	// ScalingRatio scales how strongly people repel each other, spreading the layout out.
+	ScalingRatio float64
+	// Gravity pulls everyone towards the origin, keeping disconnected groups close.
+	Gravity float64
+	// Theta trades accuracy for speed: groups of people whose cell is smaller than Theta
+	// times their distance repel as one.
+	Theta float64
+	// Seed seeds the random initial placement, so that layouts are reproducible.
+	Seed uint64
+	// Initial places people where a previous layout left them. Others start near their
+	// placed neighbors.
+	Initial map[int64]repo.Position
+	// Pinned keeps the people with an initial position in place, so that only newcomers move.
+	Pinned bool
+}
+
+// DefaultLayoutOptions are the customary ForceAtlas2 settings for large graphs.
+var DefaultLayoutOptions = LayoutOptions{
+	Iterations:   200,
+	ScalingRatio: 10,
+	Gravity:      1,
+	Theta:        1.2,
+	Seed:         1,
+}
+
+const (
+	// layoutJitterTolerance is how much swinging is tolerated before the layout slows down.
+	layoutJitterTolerance = 1.0
+	// layoutMinSpeedEfficiency bounds how far the layout slows down when swinging.
+	layoutMinSpeedEfficiency = 0.05
+)
+
+// Layout places everyone in the follow graph in the plane with ForceAtlas2: people repel
+// each other, approximated with a Barnes–Hut quadtree, and follows pull them together.
+// Follows are read regardless of direction, and mutual follows pull twice as hard.
+func Layout(c *CSR, opts LayoutOptions) []repo.Position {
+	l := newLayout(c.undirected(), opts)
+	l.place(c)
+	for range opts.Iterations {
+		l.step()
+	}
+
+	positions := make([]repo.Position, len(c.ids))
+	for i, id := range c.ids {
+		positions[i] = repo.Position{PersonID: id, X: l.xs[i], Y: l.ys[i]}
+	}
+	return positions
+}
+
+// layout is the state of a ForceAtlas2 layout in progress, by dense index.
+type layout struct {
+	g    *undirected
+	opts LayoutOptions
+
+	xs, ys []float64
+	// masses grow with degrees, so that hubs push others further away
+	masses []float64
+	fixed  []bool
+
+	fxs, fys        []float64
+	oldFxs, oldFys  []float64
+	speed           float64
+	speedEfficiency float64
+}
+
+func newLayout(g *undirected, opts LayoutOptions) *layout {
+	n := len(g.degrees)
+	l := &layout{
+		g:               g,
+		opts:            opts,
+		xs:              make([]float64, n),
+		ys:              make([]float64, n),
+		masses:          make([]float64, n),
+		fixed:           make([]bool, n),
+		fxs:             make([]float64, n),
+		fys:             make([]float64, n),
+		oldFxs:          make([]float64, n),
+		oldFys:          make([]float64, n),
+		speed:           1,
+		speedEfficiency: 1,
+	}
+	for i := range n {
+		l.masses[i] = float64(g.offsets[i+1]-g.offsets[i]) + 1
+	}
+	return l
+}
+
+// place sets the initial positions: people placed by a previous layout start there, people
+// with placed neighbors start around them, and everyone else starts at random.
+func (l *layout) place(c *CSR) {
+	opts := l.opts
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	n := len(l.xs)
+
+	placed := make([]bool, n)
+	for i, id := range c.ids {
+		if p, ok := opts.Initial[id]; ok {
+			l.xs[i], l.ys[i] = p.X, p.Y
+			placed[i] = true
+			l.fixed[i] = opts.Pinned
+		}
+	}
+
+	// Random placement spans roughly the area a layout of this size settles into
+	spread := math.Sqrt(float64(n)) * 10
+	for i := range n {
+		if placed[i] {
+			continue
+		}
+
+		var x, y, count float64
+		for e := l.g.offsets[i]; e < l.g.offsets[i+1]; e++ {
+			if j := l.g.adj[e]; placed[j] {
+				x, y, count = x+l.xs[j], y+l.ys[j], count+1
+			}
+		}
+		if count > 0 {
+			// Jitter keeps newcomers sharing neighbors from starting on top of each other
+			l.xs[i] = x/count + rng.NormFloat64()
+			l.ys[i] = y/count + rng.NormFloat64()
+		} else {
+			l.xs[i] = (rng.Float64() - 0.5) * spread
+			l.ys[i] = (rng.Float64() - 0.5) * spread
+		}
+	}
+}
+
+// step runs one ForceAtlas2 iteration, computing forces and moving everyone along them.
+func (l *layout) step() {
+	n := len(l.xs)
+	l.fxs, l.oldFxs = l.oldFxs, l.fxs
+	l.fys, l.oldFys = l.oldFys, l.fys
+
+	qt := newQuadtree(l.xs, l.ys, l.masses, 1)
+
+	// Forces on each person only depend on positions, so people are split among workers
+	workers := runtime.GOMAXPROCS(0)
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			var stack []int32
+			for i := start; i < end; i++ {
+				stack = l.force(qt, int32(i), stack)
+			}
+		}(start, min(start+chunk, n))
+	}
+	wg.Wait()
+
+	l.move()
+}
+
+// force computes the force on a person into fxs and fys, reusing stack to walk the quadtree.
+func (l *layout) force(qt *quadtree, i int32, stack []int32) []int32 {
+	x, y, m := l.xs[i], l.ys[i], l.masses[i]
+	kr := l.opts.ScalingRatio
+	var fx, fy float64
+
+	// Repulsion from everyone, kr·mi·mj/d along the line between them
+	repel := func(ox, oy, om float64) {
+		dx, dy := x-ox, y-oy
+		if d2 := dx*dx + dy*dy; d2 > 0 {
+			f := kr * m * om / d2
+			fx, fy = fx+dx*f, fy+dy*f
+		}
+	}
+	theta2 := l.opts.Theta * l.opts.Theta
+	stack = append(stack[:0], 0)
+	for len(stack) > 0 {
+		c := &qt.cells[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if c.mass == 0 {
+			continue
+		}
+		if c.leaf() {
+			for _, j := range c.points {
+				if j != i {
+					repel(l.xs[j], l.ys[j], l.masses[j])
+				}
+			}
+			continue
+		}
+
+		cx, cy := c.center()
+		dx, dy := x-cx, y-cy
+		if c.size*c.size < theta2*(dx*dx+dy*dy) {
+			repel(cx, cy, c.mass)
+			continue
+		}
+		stack = append(stack, c.children[:]...)
+	}
+
+	// Attraction along follows, proportional to distance
+	for e := l.g.offsets[i]; e < l.g.offsets[i+1]; e++ {
+		j := l.g.adj[e]
+		w := l.g.weights[e]
+		fx -= (x - l.xs[j]) * w
+		fy -= (y - l.ys[j]) * w
+	}
+
+	// Gravity towards the origin, the same strength at any distance
+	if d := math.Hypot(x, y); d > 0 {
+		f := l.opts.Gravity * m / d
+		fx, fy = fx-x*f, fy-y*f
+	}
+
+	l.fxs[i], l.fys[i] = fx, fy
+	return stack
+}
+
+// move adapts the global speed to how much people swing back and forth, then moves each
+// person along their force, slowing down those who swing the most.
+func (l *layout) move() {
+	n := len(l.xs)
+
+	var swinging, traction float64
+	for i := range n {
+		if l.fixed[i] {
+			continue
+		}
+		swinging += l.masses[i] * math.Hypot(l.oldFxs[i]-l.fxs[i], l.oldFys[i]-l.fys[i])
+		traction += l.masses[i] * math.Hypot(l.oldFxs[i]+l.fxs[i], l.oldFys[i]+l.fys[i]) / 2
+	}
+	if swinging == 0 || traction == 0 {
+		return
+	}
+
+	estimatedJitter := 0.05 * math.Sqrt(float64(n))
+	jitter := layoutJitterTolerance * max(math.Sqrt(estimatedJitter), min(10, estimatedJitter*traction/float64(n*n)))
+	if swinging/traction > 2 {
+		if l.speedEfficiency > layoutMinSpeedEfficiency {
+			l.speedEfficiency *= 0.5
+		}
+		jitter = max(jitter, layoutJitterTolerance)
+	}
+
+	targetSpeed := jitter * l.speedEfficiency * traction / swinging
+	if swinging > jitter*traction {
+		if l.speedEfficiency > layoutMinSpeedEfficiency {
+			l.speedEfficiency *= 0.7
+		}
+	} else if l.speed < 1000 {
+		l.speedEfficiency *= 1.3
+	}
+	// Speed up by at most half at a time, but slow down at once
+	l.speed += min(targetSpeed-l.speed, 0.5*l.speed)
+
+	for i := range n {
+		if l.fixed[i] {
+			continue
+		}
+		swing := l.masses[i] * math.Hypot(l.oldFxs[i]-l.fxs[i], l.oldFys[i]-l.fys[i])
+		factor := l.speed / (1 + math.Sqrt(l.speed*swing))
+		l.xs[i] += l.fxs[i] * factor
+		l.ys[i] += l.fys[i] * factor
+	}
+}
+
+// Positions returns the latest layout run and the positions it placed for the given page of
+// people, ordered by person id. found is false when no layout has run yet.
+func (gc *GraphController) Positions(ctx context.Context, limit, offset int) (run repo.LayoutRun, positions []repo.Position, found bool, err error) {
+	run, found, err = gc.layouts.LatestRun(ctx)
+	if err != nil || !found {
+		return repo.LayoutRun{}, nil, false, err
+	}
+
+	positions, err = gc.layouts.ListPositions(ctx, run.Id, limit, offset)
+	if err != nil {
+		return repo.LayoutRun{}, nil, false, err
+	}
+	return run, positions, true, nil
+}
+
+// attachPositions sets the position of every person placed by the latest layout run, if
+// there is one.
+func (gc *GraphController) attachPositions(ctx context.Context, people []repo.Person) error {
+	run, found, err := gc.layouts.LatestRun(ctx)
+	if err != nil || !found {
+		return err
+	}
+
+	positions, err := gc.layouts.FindPositions(ctx, run.Id, personIDs(people))
+	if err != nil {
+		slog.Error("Finding positions", "error", err)
+		return err
+	}
+
+	for i, p := range people {
+		if pos, ok := positions[p.Id]; ok {
+			people[i].Position = &pos
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func distance(a, b repo.Position) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+func TestLayout(t *testing.T) {
+	g := loadEngine(t, cliques()...).Graph()
+
+	positions := graph.Layout(g, graph.DefaultLayoutOptions)
+	if len(positions) != 8 {
+		t.Fatalf("got %d positions, want 8", len(positions))
+	}
+	byID := make(map[int64]repo.Position, len(positions))
+	for _, p := range positions {
+		if math.IsNaN(p.X) || math.IsNaN(p.Y) {
+			t.Fatalf("got position %+v", p)
+		}
+		byID[p.PersonID] = p
+	}
+
+	// People in the same clique end up closer to each other than to the other clique
+	within := distance(byID[1], byID[2])
+	across := distance(byID[1], byID[7])
+	if within >= across {
+		t.Errorf("distance within a clique %v is not below the distance across %v", within, across)
+	}
+
+	again := graph.Layout(g, graph.DefaultLayoutOptions)
+	for i := range positions {
+		if positions[i] != again[i] {
+			t.Fatalf("layouts with the same seed differ: %+v and %+v", positions[i], again[i])
+		}
+	}
+}
+
+func TestLayoutIncremental(t *testing.T) {
+	g := loadEngine(t, cliques()...).Graph()
+
+	// 8 is new, and starts next to its placed neighbors while everyone else stays put
+	initial := make(map[int64]repo.Position)
+	for _, p := range graph.Layout(g, graph.DefaultLayoutOptions) {
+		if p.PersonID != 8 {
+			initial[p.PersonID] = p
+		}
+	}
+	opts := graph.DefaultLayoutOptions
+	opts.Initial, opts.Pinned, opts.Iterations = initial, true, 20
+
+	for _, p := range graph.Layout(g, opts) {
+		if p.PersonID == 8 {
+			if distance(p, initial[5]) >= distance(p, initial[1]) {
+				t.Errorf("newcomer %+v placed nearer the other clique", p)
+			}
+			continue
+		}
+		if p != initial[p.PersonID] {
+			t.Errorf("pinned person moved from %+v to %+v", initial[p.PersonID], p)
+		}
+	}
+}
+
+func BenchmarkLayoutStep(b *testing.B) {
+	g := benchGraph(b)
+	opts := graph.DefaultLayoutOptions
+	opts.Iterations = 1
+	b.ResetTimer()
+	for range b.N {
+		graph.Layout(g, opts)
+	}
+}
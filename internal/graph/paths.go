@@ -0,0 +1,256 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// PathSet is a set of paths between two people, with the people and follows along them.
+type PathSet struct {
+	Graph
+	// Paths lists the person ids along each path, shortest first.
+	Paths [][]int64
+}
+
+// hopEdge is a step from one person to the next along a path, by dense index.
+type hopEdge struct {
+	from, to int32
+}
+
+// ShortestPath returns a shortest chain of follows from one person to another, including
+// both ends. found is false when no such chain exists.
+//
+// With DirectionOut each person in the chain follows the next, with DirectionIn each
+// is followed by the next, and with DirectionBoth either will do.
+func (c *CSR) ShortestPath(fromID, toID int64, direction repo.Direction) (path []int64, found bool) {
+	paths := c.KShortestPaths(fromID, toID, direction, 1)
+	if len(paths) == 0 {
+		return nil, false
+	}
+	return paths[0], true
+}
+
+// KShortestPaths returns up to k loopless chains of follows from one person to another,
+// shortest first, using Yen's algorithm over bidirectional breadth-first searches.
+func (c *CSR) KShortestPaths(fromID, toID int64, direction repo.Direction, k int) [][]int64 {
+	from, ok := c.index[fromID]
+	if !ok {
+		return nil
+	}
+	to, ok := c.index[toID]
+	if !ok {
+		return nil
+	}
+
+	first := c.bidirectionalBFS(from, to, direction, nil, nil)
+	if first == nil {
+		return nil
+	}
+
+	found := [][]int32{first}
+	var candidates [][]int32
+	for len(found) < k {
+		last := found[len(found)-1]
+		for i := 0; i < len(last)-1; i++ {
+			spur, root := last[i], last[:i+1]
+
+			// Leave the root untouched, and never repeat a found path's next step from it
+			bannedEdges := make(map[hopEdge]bool)
+			for _, p := range found {
+				if len(p) > i+1 && slices.Equal(p[:i+1], root) {
+					bannedEdges[hopEdge{from: p[i], to: p[i+1]}] = true
+				}
+			}
+			bannedNodes := make(map[int32]bool, i)
+			for _, n := range root[:i] {
+				bannedNodes[n] = true
+			}
+
+			spurPath := c.bidirectionalBFS(spur, to, direction, bannedNodes, bannedEdges)
+			if spurPath == nil {
+				continue
+			}
+
+			candidate := append(slices.Clone(root[:i]), spurPath...)
+			if !slices.ContainsFunc(candidates, func(p []int32) bool { return slices.Equal(p, candidate) }) &&
+				!slices.ContainsFunc(found, func(p []int32) bool { return slices.Equal(p, candidate) }) {
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+		// Shortest candidates first, then by ids so that results are stable
+		slices.SortFunc(candidates, func(a, b []int32) int {
+			if len(a) != len(b) {
+				return len(a) - len(b)
+			}
+			return slices.Compare(a, b)
+		})
+		found = append(found, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	paths := make([][]int64, 0, len(found))
+	for _, p := range found {
+		path := make([]int64, 0, len(p))
+		for _, i := range p {
+			path = append(path, c.ids[i])
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Follows reports whether one person follows another.
+func (c *CSR) Follows(followerID, followeeID int64) bool {
+	i, ok := c.index[followerID]
+	if !ok {
+		return false
+	}
+	j, ok := c.index[followeeID]
+	if !ok {
+		return false
+	}
+	_, found := slices.BinarySearch(c.out[c.outOffsets[i]:c.outOffsets[i+1]], j)
+	return found
+}
+
+// bidirectionalBFS finds a shortest path from one person to another by searching forward
+// from the source and backward from the target until the searches meet, always expanding
+// the smaller frontier. Banned people and steps are never used.
+func (c *CSR) bidirectionalBFS(from, to int32, direction repo.Direction, bannedNodes map[int32]bool, bannedEdges map[hopEdge]bool) []int32 {
+	if bannedNodes[from] || bannedNodes[to] {
+		return nil
+	}
+	if from == to {
+		return []int32{from}
+	}
+
+	// Searching backward follows every step in reverse
+	backward := direction
+	switch direction {
+	case repo.DirectionOut:
+		backward = repo.DirectionIn
+	case repo.DirectionIn:
+		backward = repo.DirectionOut
+	}
+
+	// Parents are indexed densely, with -1 marking people not reached yet
+	parentsFwd := make([]int32, len(c.ids))
+	parentsBwd := make([]int32, len(c.ids))
+	distFwd := make([]int32, len(c.ids))
+	distBwd := make([]int32, len(c.ids))
+	for i := range parentsFwd {
+		parentsFwd[i], parentsBwd[i] = -1, -1
+	}
+	parentsFwd[from], parentsBwd[to] = from, to
+
+	frontierFwd, frontierBwd := []int32{from}, []int32{to}
+	for len(frontierFwd) > 0 && len(frontierBwd) > 0 {
+		var (
+			next       []int32
+			best       = int32(-1)
+			bestLength = int32(len(c.ids) + 1)
+		)
+		// meet records v as the best meeting point so far, once both searches reached it
+		meet := func(v int32) {
+			if parentsFwd[v] >= 0 && parentsBwd[v] >= 0 && distFwd[v]+distBwd[v] < bestLength {
+				best, bestLength = v, distFwd[v]+distBwd[v]
+			}
+		}
+
+		// Whole levels are expanded, so the best meeting point of a level is a shortest path
+		if len(frontierFwd) <= len(frontierBwd) {
+			for _, u := range frontierFwd {
+				c.eachNeighbor(u, direction, func(v int32) {
+					if bannedNodes[v] || bannedEdges[hopEdge{from: u, to: v}] || parentsFwd[v] >= 0 {
+						return
+					}
+					parentsFwd[v], distFwd[v] = u, distFwd[u]+1
+					next = append(next, v)
+					meet(v)
+				})
+			}
+			frontierFwd = next
+		} else {
+			for _, v := range frontierBwd {
+				c.eachNeighbor(v, backward, func(u int32) {
+					if bannedNodes[u] || bannedEdges[hopEdge{from: u, to: v}] || parentsBwd[u] >= 0 {
+						return
+					}
+					parentsBwd[u], distBwd[u] = v, distBwd[v]+1
+					next = append(next, u)
+					meet(u)
+				})
+			}
+			frontierBwd = next
+		}
+
+		if best >= 0 {
+			var path []int32
+			for i := best; i != from; i = parentsFwd[i] {
+				path = append(path, i)
+			}
+			path = append(path, from)
+			slices.Reverse(path)
+			for i := best; i != to; {
+				i = parentsBwd[i]
+				path = append(path, i)
+			}
+			return path
+		}
+	}
+	return nil
+}
+
+// Paths returns up to k shortest chains of follows between two people over the in-memory
+// follow graph, with the people and follows along them.
+func (gc *GraphController) Paths(ctx context.Context, fromID, toID int64, direction repo.Direction, k int) (PathSet, error) {
+	switch direction {
+	case repo.DirectionOut, repo.DirectionIn, repo.DirectionBoth:
+	default:
+		return PathSet{}, fmt.Errorf("invalid edge direction %q", direction)
+	}
+
+	g := gc.follows.Graph()
+	paths := g.KShortestPaths(fromID, toID, direction, k)
+
+	var (
+		ids   []int64
+		edges []repo.Edge
+		seen  = make(map[repo.Edge]bool)
+	)
+	for _, path := range paths {
+		ids = append(ids, path...)
+		for i := 0; i < len(path)-1; i++ {
+			// Report every step as the follow it was taken along
+			a, b := path[i], path[i+1]
+			var steps []repo.Edge
+			if direction != repo.DirectionIn && g.Follows(a, b) {
+				steps = append(steps, repo.Edge{SourceID: a, TargetID: b, Kind: repo.EdgeKindFollows, Weight: 1})
+			}
+			if direction != repo.DirectionOut && g.Follows(b, a) {
+				steps = append(steps, repo.Edge{SourceID: b, TargetID: a, Kind: repo.EdgeKindFollows, Weight: 1})
+			}
+			for _, e := range steps {
+				if !seen[e] {
+					seen[e] = true
+					edges = append(edges, e)
+				}
+			}
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	network, err := gc.withNodes(ctx, ids, edges)
+	if err != nil {
+		return PathSet{}, err
+	}
+	return PathSet{Graph: network, Paths: paths}, nil
+}
@@ -0,0 +1,87 @@
+package graph_test
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func loadEngine(t *testing.T, pairs ...[2]int64) *graph.Engine {
+	t.Helper()
+
+	scanner := &fakeScanner{}
+	scanner.add(time.Now(), pairs...)
+	engine := graph.NewEngine(scanner)
+	if err := engine.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func TestKShortestPaths(t *testing.T) {
+	// Two routes of length 2 from 1 to 4, and a longer one through 5 and 6
+	engine := loadEngine(t,
+		[2]int64{1, 2}, [2]int64{2, 4},
+		[2]int64{1, 3}, [2]int64{3, 4},
+		[2]int64{1, 5}, [2]int64{5, 6}, [2]int64{6, 4},
+	)
+	g := engine.Graph()
+
+	got := g.KShortestPaths(1, 4, repo.DirectionOut, 5)
+	want := [][]int64{{1, 2, 4}, {1, 3, 4}, {1, 5, 6, 4}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := g.KShortestPaths(1, 4, repo.DirectionOut, 1); len(got) != 1 || len(got[0]) != 3 {
+		t.Errorf("k = 1: got %v", got)
+	}
+	if got := g.KShortestPaths(4, 1, repo.DirectionOut, 3); got != nil {
+		t.Errorf("against follows: got %v", got)
+	}
+	if got := g.KShortestPaths(4, 1, repo.DirectionIn, 1); !slices.EqualFunc(got, [][]int64{{4, 2, 1}}, slices.Equal) {
+		t.Errorf("along followers: got %v", got)
+	}
+}
+
+func TestShortestPathMatchesBFS(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	var pairs [][2]int64
+	for range 3000 {
+		pairs = append(pairs, [2]int64{rng.Int63n(500) + 1, rng.Int63n(500) + 1})
+	}
+	g := loadEngine(t, pairs...).Graph()
+
+	for _, direction := range []repo.Direction{repo.DirectionOut, repo.DirectionIn, repo.DirectionBoth} {
+		for range 200 {
+			from, to := rng.Int63n(500)+1, rng.Int63n(500)+1
+			if !g.Has(from) || !g.Has(to) {
+				continue
+			}
+
+			depth := -1
+			for _, hop := range g.KHop(from, 500, direction, 0) {
+				if hop.ID == to {
+					depth = hop.Depth
+				}
+			}
+
+			path, found := g.ShortestPath(from, to, direction)
+			if found != (depth >= 0) || (found && len(path)-1 != depth) {
+				t.Fatalf("%d to %d %s: got %v, want %d steps", from, to, direction, path, depth)
+			}
+			for i := 0; found && i < len(path)-1; i++ {
+				a, b := path[i], path[i+1]
+				ok := (direction != repo.DirectionIn && g.Follows(a, b)) || (direction != repo.DirectionOut && g.Follows(b, a))
+				if !ok {
+					t.Fatalf("%d to %d %s: %v steps from %d to %d without a follow", from, to, direction, path, a, b)
+				}
+			}
+		}
+	}
+}
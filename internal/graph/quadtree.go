@@ -0,0 +1,144 @@
+package graph
+
+// quadtreeMaxDepth bounds how often cells are split, so that coincident points end up
+// sharing a leaf instead of splitting forever.
+const quadtreeMaxDepth = 32
+
+// quadtree partitions weighted points in the plane into square cells, each split into four
+// quadrants once it holds more than a leaf's worth of points, and records the mass and
+// center of mass of every cell.
+type quadtree struct {
+	cells  []quadCell
+	xs, ys []float64
+	masses []float64
+}
+
+type quadCell struct {
+	// x and y are the cell's lower left corner, and size the length of its sides
+	x, y, size float64
+	// mass sums the masses of the cell's points, and mx and my their mass-weighted coordinates
+	mass, mx, my float64
+	// children index the cell's quadrants, and are all 0 for leaves
+	children [4]int32
+	// points lists the points of a leaf
+	points []int32
+}
+
+// newQuadtree indexes the points at xs and ys with the given masses, splitting cells once
+// they hold more than leafSize points.
+func newQuadtree(xs, ys, masses []float64, leafSize int) *quadtree {
+	qt := &quadtree{xs: xs, ys: ys, masses: masses}
+	if len(xs) == 0 {
+		qt.cells = []quadCell{{size: 1}}
+		return qt
+	}
+
+	minX, minY, maxX, maxY := xs[0], ys[0], xs[0], ys[0]
+	for i := range xs {
+		minX, maxX = min(minX, xs[i]), max(maxX, xs[i])
+		minY, maxY = min(minY, ys[i]), max(maxY, ys[i])
+	}
+	// Grow the root slightly, so that points on its upper edges fall inside it
+	size := max(maxX-minX, maxY-minY, 1e-9) * (1 + 1e-9)
+	qt.cells = []quadCell{{x: minX, y: minY, size: size}}
+
+	for i := range xs {
+		qt.insert(int32(i), leafSize)
+	}
+	return qt
+}
+
+// center returns the center of mass of a cell.
+func (c *quadCell) center() (x, y float64) {
+	return c.mx / c.mass, c.my / c.mass
+}
+
+// leaf reports whether a cell has no quadrants.
+func (c *quadCell) leaf() bool {
+	return c.children[0] == 0
+}
+
+func (qt *quadtree) insert(p int32, leafSize int) {
+	var cell int32
+	for depth := 0; ; depth++ {
+		qt.add(cell, p)
+		c := &qt.cells[cell]
+		if c.leaf() {
+			if len(c.points) < leafSize || depth >= quadtreeMaxDepth {
+				c.points = append(c.points, p)
+				return
+			}
+			qt.split(cell)
+		}
+		cell = qt.quadrant(cell, qt.xs[p], qt.ys[p])
+	}
+}
+
+// add accounts for the mass of a point in a cell.
+func (qt *quadtree) add(cell, p int32) {
+	c := &qt.cells[cell]
+	m := qt.masses[p]
+	c.mass += m
+	c.mx += m * qt.xs[p]
+	c.my += m * qt.ys[p]
+}
+
+// split turns a leaf into four quadrants, moving its points down into them.
+func (qt *quadtree) split(cell int32) {
+	c := qt.cells[cell]
+	half := c.size / 2
+	for q := range 4 {
+		child := quadCell{x: c.x, y: c.y, size: half}
+		if q&1 != 0 {
+			child.x += half
+		}
+		if q&2 != 0 {
+			child.y += half
+		}
+		qt.cells[cell].children[q] = int32(len(qt.cells))
+		qt.cells = append(qt.cells, child)
+	}
+
+	for _, p := range c.points {
+		child := qt.quadrant(cell, qt.xs[p], qt.ys[p])
+		qt.add(child, p)
+		qt.cells[child].points = append(qt.cells[child].points, p)
+	}
+	qt.cells[cell].points = nil
+}
+
+// quadrant returns the quadrant of a split cell holding a point.
+func (qt *quadtree) quadrant(cell int32, x, y float64) int32 {
+	c := &qt.cells[cell]
+	half := c.size / 2
+	q := 0
+	if x >= c.x+half {
+		q |= 1
+	}
+	if y >= c.y+half {
+		q |= 2
+	}
+	return c.children[q]
+}
+
+// within calls fn with every point inside the box from minX, minY to maxX, maxY, inclusive,
+// skipping the cells outside of it.
+func (qt *quadtree) within(minX, minY, maxX, maxY float64, fn func(p int32)) {
+	stack := []int32{0}
+	for len(stack) > 0 {
+		c := &qt.cells[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if c.mass == 0 || c.x > maxX || c.y > maxY || c.x+c.size < minX || c.y+c.size < minY {
+			continue
+		}
+		if !c.leaf() {
+			stack = append(stack, c.children[:]...)
+			continue
+		}
+		for _, p := range c.points {
+			if x, y := qt.xs[p], qt.ys[p]; x >= minX && x <= maxX && y >= minY && y <= maxY {
+				fn(p)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// Reciprocity summarizes how many follows are returned.
+type Reciprocity struct {
+	// Follows counts the follows considered.
+	Follows int
+	// Reciprocated counts the follows whose reverse follow exists too.
+	Reciprocated int
+}
+
+// Ratio returns the share of follows that are reciprocated, or 0 without follows.
+func (r Reciprocity) Ratio() float64 {
+	if r.Follows == 0 {
+		return 0
+	}
+	return float64(r.Reciprocated) / float64(r.Follows)
+}
+
+// PersonReciprocity is the reciprocity of the follows touching a person.
+type PersonReciprocity struct {
+	Reciprocity
+	Following int
+	Followers int
+	Mutuals   int
+}
+
+// Mutuals returns the people who both follow and are followed by a person, sorted by id.
+func (c *CSR) Mutuals(personID int64) []int64 {
+	i, ok := c.index[personID]
+	if !ok {
+		return nil
+	}
+
+	// Both rows are sorted, so mutuals are found by merging them
+	var (
+		mutuals []int64
+		out     = c.out[c.outOffsets[i]:c.outOffsets[i+1]]
+		in      = c.in[c.inOffsets[i]:c.inOffsets[i+1]]
+	)
+	for len(out) > 0 && len(in) > 0 {
+		switch {
+		case out[0] < in[0]:
+			out = out[1:]
+		case out[0] > in[0]:
+			in = in[1:]
+		default:
+			mutuals = append(mutuals, c.ids[out[0]])
+			out, in = out[1:], in[1:]
+		}
+	}
+	return mutuals
+}
+
+// PersonReciprocity returns how many of the follows touching a person, in either
+// direction, are reciprocated.
+func (c *CSR) PersonReciprocity(personID int64) PersonReciprocity {
+	following, followers := c.Degree(personID, repo.DirectionOut), c.Degree(personID, repo.DirectionIn)
+	mutuals := len(c.Mutuals(personID))
+	return PersonReciprocity{
+		Reciprocity: Reciprocity{
+			Follows: following + followers,
+			// Each mutual connection is a follow out and a follow in, both reciprocated
+			Reciprocated: 2 * mutuals,
+		},
+		Following: following,
+		Followers: followers,
+		Mutuals:   mutuals,
+	}
+}
+
+// GroupReciprocity returns the reciprocity of the follows within each group, where groups
+// maps person ids to a group, such as a community. Follows between groups are ignored.
+func GroupReciprocity[G comparable](c *CSR, groups map[int64]G) map[G]Reciprocity {
+	byGroup := make(map[G]Reciprocity)
+	for id, group := range groups {
+		i, ok := c.index[id]
+		if !ok {
+			continue
+		}
+
+		r := byGroup[group]
+		for _, j := range c.out[c.outOffsets[i]:c.outOffsets[i+1]] {
+			if g, ok := groups[c.ids[j]]; !ok || g != group {
+				continue
+			}
+			r.Follows++
+			if c.Follows(c.ids[j], id) {
+				r.Reciprocated++
+			}
+		}
+		byGroup[group] = r
+	}
+	return byGroup
+}
+
+// Mutuals returns a page of the people who both follow and are followed by a person,
+// and how many such people there are.
+func (gc *GraphController) Mutuals(ctx context.Context, personID int64, limit, offset int) (people []repo.Person, total int, err error) {
+	ids := gc.follows.Graph().Mutuals(personID)
+	total = len(ids)
+
+	ids = ids[min(offset, len(ids)):]
+	ids = ids[:min(limit, len(ids))]
+	if len(ids) == 0 {
+		return nil, total, nil
+	}
+
+	people, err = gc.peopleWithProfiles(ctx, ids)
+	return people, total, err
+}
+
+// PersonReciprocity returns how many of a person's follows are reciprocated.
+func (gc *GraphController) PersonReciprocity(personID int64) PersonReciprocity {
+	return gc.follows.Graph().PersonReciprocity(personID)
+}
+
+// markReciprocal flags every edge whose reverse edge of the same kind exists.
+//
+// Follows are checked against the in-memory follow graph, and co_playlisted edges are
+// symmetric by construction. Other kinds are only checked against the given edges.
+func (gc *GraphController) markReciprocal(edges []repo.Edge) {
+	type key struct {
+		source, target int64
+		kind           repo.EdgeKind
+	}
+	present := make(map[key]bool, len(edges))
+	for _, e := range edges {
+		present[key{e.SourceID, e.TargetID, e.Kind}] = true
+	}
+
+	g := gc.follows.Graph()
+	for i, e := range edges {
+		switch e.Kind {
+		case repo.EdgeKindFollows:
+			edges[i].Reciprocal = g.Follows(e.TargetID, e.SourceID)
+		case repo.EdgeKindCoPlaylisted:
+			edges[i].Reciprocal = true
+		default:
+			edges[i].Reciprocal = present[key{e.TargetID, e.SourceID, e.Kind}]
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package graph_test
+
+import (
+	"slices"
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+)
+
+func TestMutuals(t *testing.T) {
+	g := loadEngine(t,
+		[2]int64{1, 2}, [2]int64{2, 1},
+		[2]int64{1, 3}, [2]int64{3, 1},
+		[2]int64{1, 4},
+		[2]int64{5, 1},
+	).Graph()
+
+	if got, want := g.Mutuals(1), []int64{2, 3}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := g.Mutuals(4); got != nil {
+		t.Errorf("one-way follow: got %v", got)
+	}
+
+	rec := g.PersonReciprocity(1)
+	if rec.Following != 3 || rec.Followers != 3 || rec.Mutuals != 2 {
+		t.Errorf("got %+v", rec)
+	}
+	if got, want := rec.Ratio(), 4.0/6.0; got != want {
+		t.Errorf("ratio: got %v, want %v", got, want)
+	}
+	if got := g.PersonReciprocity(42).Ratio(); got != 0 {
+		t.Errorf("unknown person ratio: got %v", got)
+	}
+}
+
+func TestGroupReciprocity(t *testing.T) {
+	g := loadEngine(t,
+		[2]int64{1, 2}, [2]int64{2, 1}, [2]int64{2, 3},
+		[2]int64{4, 5},
+		// Follows between groups are ignored
+		[2]int64{3, 4}, [2]int64{4, 3},
+	).Graph()
+
+	got := graph.GroupReciprocity(g, map[int64]string{1: "a", 2: "a", 3: "a", 4: "b", 5: "b"})
+	want := map[string]graph.Reciprocity{
+		"a": {Follows: 3, Reciprocated: 2},
+		"b": {Follows: 1, Reciprocated: 0},
+	}
+	if len(got) != len(want) || got["a"] != want["a"] || got["b"] != want["b"] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
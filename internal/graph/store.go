@@ -0,0 +1,247 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// GraphStore reads and writes the social layer of the people graph.
+//
+// repo.GraphRepository implements it over Postgres, and MemoryStore in memory,
+// so that analytics and tests can run without a database.
+type GraphStore interface {
+	// UpsertNodes creates or updates people keyed by username, and returns the stored people.
+	// Zero-valued fields leave stored values untouched.
+	UpsertNodes(ctx context.Context, people []repo.Person) (stored []repo.Person, err error)
+	// UpsertEdges stores social edges between stored people. Follows always weigh 1,
+	// and other edges keep the larger weight when they already exist.
+	UpsertEdges(ctx context.Context, edges []repo.Edge) error
+	// Neighbors returns the edges of the given kinds touching a person in the given direction, heaviest first.
+	Neighbors(ctx context.Context, personID int64, direction repo.Direction, kinds []repo.EdgeKind) (edges []repo.Edge, err error)
+	// Degree returns how many edges of the given kinds touch a person in the given direction.
+	Degree(ctx context.Context, personID int64, direction repo.Direction, kinds []repo.EdgeKind) (degree int, err error)
+	// Subgraph returns the people with the given ids, and every edge of the given kinds between them.
+	Subgraph(ctx context.Context, ids []int64, kinds []repo.EdgeKind) (people []repo.Person, edges []repo.Edge, err error)
+}
+
+type edgeKey struct {
+	source, target int64
+	kind           repo.EdgeKind
+}
+
+// MemoryStore is a GraphStore held in memory. It is safe for concurrent use.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	nextID     int64
+	people     map[int64]repo.Person
+	byUsername map[string]int64
+	edges      map[edgeKey]repo.Edge
+	out        map[int64]map[edgeKey]struct{}
+	in         map[int64]map[edgeKey]struct{}
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nextID:     1,
+		people:     make(map[int64]repo.Person),
+		byUsername: make(map[string]int64),
+		edges:      make(map[edgeKey]repo.Edge),
+		out:        make(map[int64]map[edgeKey]struct{}),
+		in:         make(map[int64]map[edgeKey]struct{}),
+	}
+}
+
+func (ms *MemoryStore) UpsertNodes(_ context.Context, people []repo.Person) ([]repo.Person, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var stored []repo.Person
+	seen := make(map[string]bool, len(people))
+	for _, p := range people {
+		if seen[p.Username] {
+			continue
+		}
+		seen[p.Username] = true
+
+		id, ok := ms.byUsername[p.Username]
+		if !ok {
+			id = ms.nextID
+			ms.nextID++
+			ms.byUsername[p.Username] = id
+			ms.people[id] = repo.Person{Id: id, Username: p.Username, Plan: repo.PlanNone}
+		}
+
+		person := ms.people[id]
+		if p.Urn != "" {
+			person.Urn = p.Urn
+		}
+		if p.Name != "" {
+			person.Name = p.Name
+		}
+		if p.ImageUrl != "" {
+			person.ImageUrl = p.ImageUrl
+		}
+		person.Verified = person.Verified || p.Verified
+		if p.Plan != "" && p.Plan != repo.PlanNone {
+			person.Plan = p.Plan
+		}
+		if p.TrackCount != 0 {
+			person.TrackCount = p.TrackCount
+		}
+		ms.people[id] = person
+
+		stored = append(stored, person)
+	}
+	return stored, nil
+}
+
+func (ms *MemoryStore) UpsertEdges(_ context.Context, edges []repo.Edge) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	// Validate every edge first, so that a rejected batch stores nothing
+	for _, e := range edges {
+		switch e.Kind {
+		case repo.EdgeKindFollows, repo.EdgeKindLikedTrackOf, repo.EdgeKindRepostedTrackOf, repo.EdgeKindCommentedOn:
+		default:
+			return fmt.Errorf("edge kind %q is not part of the social layer", e.Kind)
+		}
+		if _, ok := ms.people[e.SourceID]; !ok {
+			return fmt.Errorf("unknown person %d", e.SourceID)
+		}
+		if _, ok := ms.people[e.TargetID]; !ok {
+			return fmt.Errorf("unknown person %d", e.TargetID)
+		}
+	}
+
+	now := time.Now()
+	for _, e := range edges {
+		key := edgeKey{source: e.SourceID, target: e.TargetID, kind: e.Kind}
+		stored, exists := ms.edges[key]
+
+		if e.Kind == repo.EdgeKindFollows {
+			if exists {
+				continue
+			}
+			ms.edges[key] = repo.Edge{SourceID: e.SourceID, TargetID: e.TargetID, Kind: e.Kind, Weight: 1, UpdatedAt: now}
+		} else {
+			// Interaction times are only written by the ingesters' materializing queries
+			e.FirstAt, e.LastAt = stored.FirstAt, stored.LastAt
+			if exists {
+				e.Weight = max(e.Weight, stored.Weight)
+			}
+			e.UpdatedAt = now
+			ms.edges[key] = e
+		}
+
+		if ms.out[e.SourceID] == nil {
+			ms.out[e.SourceID] = make(map[edgeKey]struct{})
+		}
+		if ms.in[e.TargetID] == nil {
+			ms.in[e.TargetID] = make(map[edgeKey]struct{})
+		}
+		ms.out[e.SourceID][key] = struct{}{}
+		ms.in[e.TargetID][key] = struct{}{}
+	}
+	return nil
+}
+
+func (ms *MemoryStore) Neighbors(_ context.Context, personID int64, direction repo.Direction, kinds []repo.EdgeKind) ([]repo.Edge, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	edges, err := ms.neighbors(personID, direction, kinds)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Weight != edges[j].Weight {
+			return edges[i].Weight > edges[j].Weight
+		}
+		if edges[i].SourceID != edges[j].SourceID {
+			return edges[i].SourceID < edges[j].SourceID
+		}
+		return edges[i].TargetID < edges[j].TargetID
+	})
+	return edges, nil
+}
+
+func (ms *MemoryStore) Degree(_ context.Context, personID int64, direction repo.Direction, kinds []repo.EdgeKind) (int, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	edges, err := ms.neighbors(personID, direction, kinds)
+	return len(edges), err
+}
+
+func (ms *MemoryStore) Subgraph(_ context.Context, ids []int64, kinds []repo.EdgeKind) ([]repo.Person, []repo.Edge, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	var (
+		people []repo.Person
+		edges  []repo.Edge
+		inSet  = make(map[int64]bool, len(ids))
+	)
+	for _, id := range ids {
+		if p, ok := ms.people[id]; ok && !inSet[id] {
+			inSet[id] = true
+			people = append(people, p)
+		}
+	}
+
+	wanted := kindSet(kinds)
+	for id := range inSet {
+		for key := range ms.out[id] {
+			if inSet[key.target] && wanted[key.kind] {
+				edges = append(edges, ms.edges[key])
+			}
+		}
+	}
+	return people, edges, nil
+}
+
+// neighbors collects the edges touching personID. The read lock must be held.
+func (ms *MemoryStore) neighbors(personID int64, direction repo.Direction, kinds []repo.EdgeKind) ([]repo.Edge, error) {
+	var sides []map[edgeKey]struct{}
+	switch direction {
+	case repo.DirectionOut:
+		sides = append(sides, ms.out[personID])
+	case repo.DirectionIn:
+		sides = append(sides, ms.in[personID])
+	case repo.DirectionBoth:
+		sides = append(sides, ms.out[personID], ms.in[personID])
+	default:
+		return nil, errors.New("invalid edge direction")
+	}
+
+	wanted := kindSet(kinds)
+	var edges []repo.Edge
+	for i, side := range sides {
+		for key := range side {
+			// A self-loop is both an out and an in edge, but is only counted once
+			if i > 0 && key.source == key.target {
+				continue
+			}
+			if wanted[key.kind] {
+				edges = append(edges, ms.edges[key])
+			}
+		}
+	}
+	return edges, nil
+}
+
+func kindSet(kinds []repo.EdgeKind) map[repo.EdgeKind]bool {
+	set := make(map[repo.EdgeKind]bool, len(kinds))
+	for _, k := range kinds {
+		set[k] = true
+	}
+	return set
+}
@@ -0,0 +1,14 @@
+package graph_test
+
+import (
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/graph/graphtest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	graphtest.TestGraphStore(t, func(t *testing.T) graph.GraphStore {
+		return graph.NewMemoryStore()
+	})
+}
@@ -0,0 +1,281 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+const (
+	// tileGrid is how many clusters fit across a tile: at each zoom level, people are merged
+	// with the members of their community in the same 1/tileGrid of a tile.
+	tileGrid = 8
+	// tileLeafSize is how many people a quadtree leaf of a tile index holds.
+	tileLeafSize = 16
+	// MaxTileZoom is the deepest zoom level tiles are served at.
+	MaxTileZoom = 24
+)
+
+// TileIndex indexes where a layout run placed people, along with their communities, to serve
+// the parts of the layout visible in a viewport.
+type TileIndex struct {
+	// LayoutRunID and CommunityRunID are the runs the index was built from, 0 for none.
+	LayoutRunID    int64
+	CommunityRunID int64
+
+	ids         []int64
+	communities []int
+	qt          *quadtree
+}
+
+// NewTileIndex indexes the positions placed by a layout run, with the communities of their
+// people, when known.
+func NewTileIndex(layoutRunID, communityRunID int64, positions []repo.Position, memberships map[int64]int) *TileIndex {
+	ti := &TileIndex{
+		LayoutRunID:    layoutRunID,
+		CommunityRunID: communityRunID,
+		ids:            make([]int64, len(positions)),
+		communities:    make([]int, len(positions)),
+	}
+	xs, ys, masses := make([]float64, len(positions)), make([]float64, len(positions)), make([]float64, len(positions))
+	for i, p := range positions {
+		ti.ids[i] = p.PersonID
+		xs[i], ys[i], masses[i] = p.X, p.Y, 1
+		community, ok := memberships[p.PersonID]
+		if !ok {
+			community = -1
+		}
+		ti.communities[i] = community
+	}
+	ti.qt = newQuadtree(xs, ys, masses, tileLeafSize)
+	return ti
+}
+
+// Viewport is the part of the layout shown on the canvas, at a zoom level. At zoom z, the
+// whole layout spans 2^z by 2^z tiles.
+type Viewport struct {
+	MinX, MinY, MaxX, MaxY float64
+	Zoom                   int
+}
+
+// TileNode is a person, or a cluster of people of the same community close to each other.
+type TileNode struct {
+	// Key identifies the node among those of its zoom level.
+	Key string
+	X   float64
+	Y   float64
+	// Size counts the people in the node, 1 for a single person.
+	Size int
+	// Community is the community of the people in the node, when known.
+	Community *int
+	// PersonID is the person, or the most followed member of a cluster.
+	PersonID int64
+	// Person is only populated for single people, by the GraphController.
+	Person *repo.Person
+}
+
+// TileEdge aggregates the follows from the people of one node to those of another.
+type TileEdge struct {
+	Source string
+	Target string
+	// Weight counts the follows.
+	Weight int
+}
+
+// Tile is what is visible in a viewport.
+type Tile struct {
+	Nodes []TileNode
+	Edges []TileEdge
+	// Total is how many nodes are in the viewport, before truncating to the node cap.
+	Total int
+}
+
+// Truncated reports whether nodes in the viewport were left out.
+func (t Tile) Truncated() bool {
+	return t.Total > len(t.Nodes)
+}
+
+// Tile returns the people in a viewport and the follows between them from the follow graph.
+// People too close to each other to tell apart at the viewport's zoom are merged with the
+// members of their community nearby into clusters. When there are more than maxNodes nodes,
+// the largest are kept, and the people in clusters with the most followers.
+func (ti *TileIndex) Tile(c *CSR, vp Viewport, maxNodes int) Tile {
+	root := &ti.qt.cells[0]
+	cellSize := root.size / math.Exp2(float64(vp.Zoom)) / tileGrid
+
+	type clusterKey struct {
+		community int
+		x, y      int64
+	}
+	type cluster struct {
+		TileNode
+		sumX, sumY float64
+		degree     int
+	}
+	var clusters []cluster
+	byKey := make(map[clusterKey]int32)
+	nodeOf := make(map[int64]int32)
+
+	ti.qt.within(vp.MinX, vp.MinY, vp.MaxX, vp.MaxY, func(p int32) {
+		x, y := ti.qt.xs[p], ti.qt.ys[p]
+		key := clusterKey{
+			community: ti.communities[p],
+			x:         int64(math.Floor((x - root.x) / cellSize)),
+			y:         int64(math.Floor((y - root.y) / cellSize)),
+		}
+		n, ok := byKey[key]
+		if !ok {
+			n = int32(len(clusters))
+			byKey[key] = n
+			clusters = append(clusters, cluster{TileNode: TileNode{Key: fmt.Sprintf("c%d:%d:%d", key.community, key.x, key.y)}, degree: -1})
+		}
+
+		cl := &clusters[n]
+		id := ti.ids[p]
+		cl.Size++
+		cl.sumX, cl.sumY = cl.sumX+x, cl.sumY+y
+		if d := c.Degree(id, repo.DirectionIn); d > cl.degree || (d == cl.degree && id < cl.PersonID) {
+			cl.PersonID, cl.degree = id, d
+		}
+		nodeOf[id] = n
+	})
+
+	for i := range clusters {
+		cl := &clusters[i]
+		cl.X, cl.Y = cl.sumX/float64(cl.Size), cl.sumY/float64(cl.Size)
+		if cl.Size == 1 {
+			cl.Key = fmt.Sprintf("p%d", cl.PersonID)
+		}
+	}
+	for key, n := range byKey {
+		if key.community >= 0 {
+			community := key.community
+			clusters[n].Community = &community
+		}
+	}
+
+	// The largest nodes are kept, then the most followed, then by key so that tiles are stable
+	order := make([]int32, len(clusters))
+	for i := range order {
+		order[i] = int32(i)
+	}
+	slices.SortFunc(order, func(a, b int32) int {
+		ca, cb := &clusters[a], &clusters[b]
+		if ca.Size != cb.Size {
+			return cb.Size - ca.Size
+		}
+		if ca.degree != cb.degree {
+			return cb.degree - ca.degree
+		}
+		return cmp.Compare(ca.Key, cb.Key)
+	})
+	if maxNodes > 0 && len(order) > maxNodes {
+		order = order[:maxNodes]
+	}
+
+	tile := Tile{Nodes: make([]TileNode, 0, len(order)), Total: len(clusters)}
+	kept := make([]bool, len(clusters))
+	for _, n := range order {
+		kept[n] = true
+		tile.Nodes = append(tile.Nodes, clusters[n].TileNode)
+	}
+
+	weights := make(map[[2]int32]int)
+	for id, n := range nodeOf {
+		i, ok := c.index[id]
+		if !ok || !kept[n] {
+			continue
+		}
+		for _, j := range c.out[c.outOffsets[i]:c.outOffsets[i+1]] {
+			if m, ok := nodeOf[c.ids[j]]; ok && m != n && kept[m] {
+				weights[[2]int32{n, m}]++
+			}
+		}
+	}
+	for pair, w := range weights {
+		tile.Edges = append(tile.Edges, TileEdge{Source: clusters[pair[0]].Key, Target: clusters[pair[1]].Key, Weight: w})
+	}
+	slices.SortFunc(tile.Edges, func(a, b TileEdge) int {
+		if a.Source != b.Source {
+			return cmp.Compare(a.Source, b.Source)
+		}
+		return cmp.Compare(a.Target, b.Target)
+	})
+	return tile
+}
+
+// Tile returns what is visible in a viewport of the latest layout, with single people loaded.
+// found is false when no layout has run yet.
+func (gc *GraphController) Tile(ctx context.Context, vp Viewport, maxNodes int) (tile Tile, found bool, err error) {
+	if vp.Zoom < 0 || vp.Zoom > MaxTileZoom {
+		return Tile{}, false, fmt.Errorf("invalid zoom %d", vp.Zoom)
+	}
+
+	index, found, err := gc.tileIndex(ctx)
+	if err != nil || !found {
+		return Tile{}, false, err
+	}
+	tile = index.Tile(gc.follows.Graph(), vp, maxNodes)
+
+	var ids []int64
+	for _, n := range tile.Nodes {
+		if n.Size == 1 {
+			ids = append(ids, n.PersonID)
+		}
+	}
+	if len(ids) == 0 {
+		return tile, true, nil
+	}
+
+	people, err := gc.people.FindPeopleByIDs(ctx, ids)
+	if err != nil {
+		return Tile{}, false, err
+	}
+	byID := make(map[int64]*repo.Person, len(people))
+	for i := range people {
+		byID[people[i].Id] = &people[i]
+	}
+	for i, n := range tile.Nodes {
+		if n.Size == 1 {
+			tile.Nodes[i].Person = byID[n.PersonID]
+		}
+	}
+	return tile, true, nil
+}
+
+// tileIndex returns the tile index of the latest layout and Louvain runs, rebuilding it
+// when either has run since it was built.
+func (gc *GraphController) tileIndex(ctx context.Context) (*TileIndex, bool, error) {
+	layoutRun, found, err := gc.layouts.LatestRun(ctx)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	communityRun, _, err := gc.communities.LatestRun(ctx, repo.CommunityAlgorithmLouvain)
+	if err != nil {
+		return nil, false, err
+	}
+
+	gc.tilesMu.Lock()
+	defer gc.tilesMu.Unlock()
+	if gc.tiles != nil && gc.tiles.LayoutRunID == layoutRun.Id && gc.tiles.CommunityRunID == communityRun.Id {
+		return gc.tiles, true, nil
+	}
+
+	positions, err := gc.layouts.ListPositions(ctx, layoutRun.Id, 0, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	var memberships map[int64]int
+	if communityRun.Id != 0 {
+		if memberships, err = gc.communities.ListMemberships(ctx, communityRun.Id); err != nil {
+			return nil, false, err
+		}
+	}
+
+	gc.tiles = NewTileIndex(layoutRun.Id, communityRun.Id, positions, memberships)
+	return gc.tiles, true, nil
+}
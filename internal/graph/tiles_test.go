@@ -0,0 +1,73 @@
+package graph_test
+
+import (
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// newTestTileIndex places each clique of cliques() in its own corner of a 100 by 100 layout,
+// one person per unit apart, with a community per clique.
+func newTestTileIndex(t *testing.T) (*graph.TileIndex, *graph.CSR) {
+	t.Helper()
+
+	g := loadEngine(t, cliques()...).Graph()
+	var positions []repo.Position
+	memberships := make(map[int64]int)
+	for i := range int64(4) {
+		positions = append(positions,
+			repo.Position{PersonID: 1 + i, X: float64(i), Y: 0},
+			repo.Position{PersonID: 5 + i, X: 100 - float64(i), Y: 100},
+		)
+		memberships[1+i], memberships[5+i] = 0, 1
+	}
+	return graph.NewTileIndex(1, 1, positions, memberships), g
+}
+
+func TestTileClusters(t *testing.T) {
+	index, g := newTestTileIndex(t)
+
+	tile := index.Tile(g, graph.Viewport{MinX: -10, MinY: -10, MaxX: 110, MaxY: 110, Zoom: 0}, 0)
+	if len(tile.Nodes) != 2 || tile.Truncated() {
+		t.Fatalf("got %+v, want a cluster per clique", tile.Nodes)
+	}
+	for _, n := range tile.Nodes {
+		if n.Size != 4 || n.Community == nil {
+			t.Errorf("got %+v", n)
+		}
+	}
+	// 5 is the most followed of its clique, as 4 follows them too
+	if tile.Nodes[0].PersonID != 5 && tile.Nodes[1].PersonID != 5 {
+		t.Errorf("got %+v, want 5 to represent their clique", tile.Nodes)
+	}
+	if len(tile.Edges) != 1 || tile.Edges[0].Weight != 1 {
+		t.Errorf("got edges %+v, want the single follow between the cliques", tile.Edges)
+	}
+}
+
+func TestTilePeople(t *testing.T) {
+	index, g := newTestTileIndex(t)
+
+	tile := index.Tile(g, graph.Viewport{MinX: -10, MinY: -10, MaxX: 110, MaxY: 110, Zoom: 10}, 0)
+	if len(tile.Nodes) != 8 {
+		t.Fatalf("got %d nodes, want everyone", len(tile.Nodes))
+	}
+	if len(tile.Edges) != 25 {
+		t.Errorf("got %d edges, want every follow", len(tile.Edges))
+	}
+
+	// Only the first clique is in view
+	tile = index.Tile(g, graph.Viewport{MinX: -1, MinY: -1, MaxX: 10, MaxY: 10, Zoom: 10}, 0)
+	if len(tile.Nodes) != 4 || len(tile.Edges) != 12 {
+		t.Errorf("got %d nodes and %d edges, want 4 and 12", len(tile.Nodes), len(tile.Edges))
+	}
+
+	tile = index.Tile(g, graph.Viewport{MinX: -1, MinY: -1, MaxX: 10, MaxY: 10, Zoom: 10}, 2)
+	if len(tile.Nodes) != 2 || tile.Total != 4 || !tile.Truncated() {
+		t.Errorf("got %d nodes of %d, want 2 of 4", len(tile.Nodes), tile.Total)
+	}
+	if len(tile.Edges) != 2 {
+		t.Errorf("got %d edges, want the 2 between the kept people", len(tile.Edges))
+	}
+}
@@ -0,0 +1,386 @@
+// Package importer loads people and follows from files shared between deployments, such as
+// those written by the export command, into the people graph.
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+var (
+	// handlePattern matches SoundCloud permalinks, which handles are normalized to.
+	handlePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+	urnPattern    = regexp.MustCompile(`^soundcloud:users:[0-9]+$`)
+	// idPattern matches the ids files give their nodes, which edges may only refer to when
+	// the node is part of the dataset.
+	idPattern = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// importedKinds are the edge kinds that can be imported. The other layers are derived from
+// the social layer by their own jobs.
+var importedKinds = map[repo.EdgeKind]bool{
+	repo.EdgeKindFollows:         true,
+	repo.EdgeKindLikedTrackOf:    true,
+	repo.EdgeKindRepostedTrackOf: true,
+	repo.EdgeKindCommentedOn:     true,
+}
+
+// Problem is a record that was skipped or merged, and why.
+type Problem struct {
+	// Source names the file, and the entry of an archive.
+	Source string
+	// Record is the record's line, or its position in a JSON array, counting from 1, and 0
+	// for problems found against stored people.
+	Record  int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Record == 0 {
+		return fmt.Sprintf("%s: %s", p.Source, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.Source, p.Record, p.Message)
+}
+
+// Edge is an edge between two people, by handle.
+type Edge struct {
+	Source string
+	Target string
+	Kind   repo.EdgeKind
+	Weight float64
+}
+
+// Dataset collects the people and edges read from files, validated and deduplicated.
+//
+// People are the same when they share a URN or a handle, and their records are merged, later
+// records overriding the fields they set. Edges are resolved once every file is read, since
+// they may refer to people by the ids of another file, and to handles no file describes, who
+// are imported as placeholders as the scraper does.
+type Dataset struct {
+	people []repo.Person
+	// byHandle and byURN index people by handle and URN, and byID by the ids files gave them
+	byHandle map[string]int
+	byURN    map[string]int
+	byID     map[string]int
+
+	pending []pendingEdge
+	edges   []Edge
+	// placeholders are the handles only referred to by edges
+	placeholders []string
+	resolved     bool
+
+	report Report
+}
+
+// pendingEdge is an edge as read, before its endpoints are resolved.
+type pendingEdge struct {
+	source         string
+	record         int
+	sourceRef      string
+	targetRef      string
+	kind           repo.EdgeKind
+	weight         float64
+	weightProvided bool
+}
+
+// NewDataset creates an empty Dataset.
+func NewDataset() *Dataset {
+	return &Dataset{
+		byHandle: make(map[string]int),
+		byURN:    make(map[string]int),
+		byID:     make(map[string]int),
+	}
+}
+
+func (ds *Dataset) problem(source string, record int, format string, args ...any) {
+	ds.report.Problems = append(ds.report.Problems, Problem{Source: source, Record: record, Message: fmt.Sprintf(format, args...)})
+}
+
+func (ds *Dataset) invalid(source string, record int, format string, args ...any) {
+	ds.report.Invalid++
+	ds.problem(source, record, format, args...)
+}
+
+// addNode validates a person record, with fields keyed by normalized column names, and
+// merges it with the person sharing its URN or handle.
+func (ds *Dataset) addNode(source string, record int, fields map[string]string) {
+	ds.report.NodeRecords++
+
+	p := repo.Person{
+		Username: normalizeHandle(fields["username"]),
+		Urn:      strings.TrimSpace(fields["urn"]),
+		Name:     strings.TrimSpace(fields["name"]),
+		ImageUrl: strings.TrimSpace(fields["imageurl"]),
+		Plan:     repo.Plan(strings.TrimSpace(fields["plan"])),
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSpace(fields["label"])
+	}
+
+	if p.Username == "" {
+		ds.invalid(source, record, "missing username")
+		return
+	}
+	if !handlePattern.MatchString(p.Username) {
+		ds.invalid(source, record, "invalid username %q", p.Username)
+		return
+	}
+	if p.Urn != "" && !urnPattern.MatchString(p.Urn) {
+		ds.invalid(source, record, "invalid urn %q", p.Urn)
+		return
+	}
+	switch p.Plan {
+	case "":
+		p.Plan = repo.PlanNone
+	case repo.PlanNone, repo.PlanArtist, repo.PlanArtistPro:
+	default:
+		ds.invalid(source, record, "invalid plan %q", p.Plan)
+		return
+	}
+	if v := strings.TrimSpace(fields["verified"]); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			ds.invalid(source, record, "invalid verified %q", v)
+			return
+		}
+		p.Verified = verified
+	}
+	if v := strings.TrimSpace(fields["trackcount"]); v != "" {
+		count, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || count < 0 {
+			ds.invalid(source, record, "invalid trackCount %q", v)
+			return
+		}
+		p.TrackCount = count
+	}
+
+	i, byURN := ds.byURN[p.Urn]
+	j, byHandle := ds.byHandle[p.Username]
+	duplicate := true
+	switch {
+	case p.Urn != "" && byURN && byHandle && i != j:
+		ds.invalid(source, record, "urn %s belongs to @%s, and @%s to another person", p.Urn, ds.people[i].Username, p.Username)
+		return
+	case p.Urn != "" && byURN:
+		if existing := ds.people[i].Username; existing != p.Username {
+			// A person who changed handle keeps the first one, and the other becomes an alias
+			ds.problem(source, record, "urn %s is both @%s and @%s, keeping @%s", p.Urn, existing, p.Username, existing)
+			ds.byHandle[p.Username] = i
+			p.Username = existing
+		}
+	case byHandle:
+		if existing := ds.people[j]; existing.Urn != "" && p.Urn != "" && existing.Urn != p.Urn {
+			ds.invalid(source, record, "@%s has urn %s, not %s", p.Username, existing.Urn, p.Urn)
+			return
+		}
+		i = j
+	default:
+		i = len(ds.people)
+		ds.people = append(ds.people, repo.Person{Username: p.Username, Plan: repo.PlanNone})
+		ds.byHandle[p.Username] = i
+		duplicate = false
+	}
+	if duplicate {
+		ds.report.DuplicatePeople++
+	}
+	ds.people[i] = merge(ds.people[i], p)
+	if p.Urn != "" {
+		ds.byURN[p.Urn] = i
+	}
+
+	if id := strings.TrimSpace(fields["id"]); id != "" {
+		if k, ok := ds.byID[id]; ok && k != i {
+			ds.problem(source, record, "id %s already names @%s, now @%s", id, ds.people[k].Username, ds.people[i].Username)
+		}
+		ds.byID[id] = i
+	}
+}
+
+// addEdge validates an edge record, with fields keyed by normalized column names. Its
+// endpoints are resolved once every file is read.
+func (ds *Dataset) addEdge(source string, record int, fields map[string]string) {
+	ds.report.EdgeRecords++
+
+	e := pendingEdge{
+		source:    source,
+		record:    record,
+		sourceRef: strings.TrimSpace(fields["source"]),
+		targetRef: strings.TrimSpace(fields["target"]),
+		kind:      repo.EdgeKind(strings.TrimSpace(fields["kind"])),
+		weight:    1,
+	}
+	if e.sourceRef == "" || e.targetRef == "" {
+		ds.invalid(source, record, "missing source or target")
+		return
+	}
+	if e.kind == "" {
+		e.kind = repo.EdgeKindFollows
+	}
+	if !importedKinds[e.kind] {
+		ds.invalid(source, record, "edge kind %q cannot be imported", e.kind)
+		return
+	}
+	if v := strings.TrimSpace(fields["weight"]); v != "" {
+		weight, err := strconv.ParseFloat(v, 64)
+		if err != nil || weight <= 0 {
+			ds.invalid(source, record, "invalid weight %q", v)
+			return
+		}
+		e.weight, e.weightProvided = weight, true
+	}
+	ds.pending = append(ds.pending, e)
+}
+
+// resolve resolves the endpoints of the edges read, by the ids files gave people, then by URN,
+// then as handles, and merges duplicate edges.
+func (ds *Dataset) resolve() {
+	if ds.resolved {
+		return
+	}
+	ds.resolved = true
+
+	type key struct {
+		source, target string
+		kind           repo.EdgeKind
+	}
+	seen := make(map[key]int)
+	placeholders := make(map[string]bool)
+	for _, e := range ds.pending {
+		source, ok := ds.endpoint(e, e.sourceRef, placeholders)
+		if !ok {
+			continue
+		}
+		target, ok := ds.endpoint(e, e.targetRef, placeholders)
+		if !ok {
+			continue
+		}
+		if source == target {
+			ds.invalid(e.source, e.record, "@%s has a %s edge to themselves", source, e.kind)
+			continue
+		}
+		if e.kind == repo.EdgeKindFollows {
+			if e.weightProvided && e.weight != 1 {
+				ds.problem(e.source, e.record, "follows weigh 1, ignoring weight %g", e.weight)
+			}
+			e.weight = 1
+		}
+
+		k := key{source, target, e.kind}
+		if i, ok := seen[k]; ok {
+			ds.report.DuplicateEdges++
+			ds.edges[i].Weight = max(ds.edges[i].Weight, e.weight)
+			continue
+		}
+		seen[k] = len(ds.edges)
+		ds.edges = append(ds.edges, Edge{Source: source, Target: target, Kind: e.kind, Weight: e.weight})
+	}
+	ds.pending = nil
+
+	for _, e := range ds.edges {
+		for _, handle := range []string{e.Source, e.Target} {
+			if _, ok := ds.byHandle[handle]; !ok && !placeholders[handle] {
+				placeholders[handle] = true
+				ds.placeholders = append(ds.placeholders, handle)
+			}
+		}
+	}
+}
+
+// endpoint resolves a reference to a person to their handle.
+func (ds *Dataset) endpoint(e pendingEdge, ref string, placeholders map[string]bool) (string, bool) {
+	if i, ok := ds.byID[ref]; ok {
+		return ds.people[i].Username, true
+	}
+	if urnPattern.MatchString(ref) {
+		if i, ok := ds.byURN[ref]; ok {
+			return ds.people[i].Username, true
+		}
+		ds.invalid(e.source, e.record, "unknown urn %s", ref)
+		return "", false
+	}
+	if idPattern.MatchString(ref) {
+		ds.invalid(e.source, e.record, "unknown node id %s", ref)
+		return "", false
+	}
+
+	handle := normalizeHandle(ref)
+	if i, ok := ds.byHandle[handle]; ok {
+		return ds.people[i].Username, true
+	}
+	if !handlePattern.MatchString(handle) {
+		ds.invalid(e.source, e.record, "invalid username %q", ref)
+		return "", false
+	}
+	return handle, true
+}
+
+// People returns the people read, in the order they were first read, followed by
+// placeholders for the handles only edges refer to.
+func (ds *Dataset) People() []repo.Person {
+	ds.resolve()
+	people := make([]repo.Person, 0, len(ds.people)+len(ds.placeholders))
+	people = append(people, ds.people...)
+	for _, handle := range ds.placeholders {
+		people = append(people, repo.Person{Username: handle, Plan: repo.PlanNone})
+	}
+	return people
+}
+
+// Edges returns the edges read, by handle, in the order they were first read.
+func (ds *Dataset) Edges() []Edge {
+	ds.resolve()
+	return ds.edges
+}
+
+// Report summarizes what was read, and every problem found.
+func (ds *Dataset) Report() Report {
+	ds.resolve()
+	r := ds.report
+	r.Problems = slices.Clone(r.Problems)
+	r.People = len(ds.people)
+	r.Placeholders = len(ds.placeholders)
+	for _, e := range ds.edges {
+		if e.Kind == repo.EdgeKindFollows {
+			r.Follows++
+		} else {
+			r.Engagement++
+		}
+	}
+	return r
+}
+
+// merge fills the fields of a person with those set in a later record of them.
+func merge(p, later repo.Person) repo.Person {
+	if later.Urn != "" {
+		p.Urn = later.Urn
+	}
+	if later.Name != "" {
+		p.Name = later.Name
+	}
+	if later.ImageUrl != "" {
+		p.ImageUrl = later.ImageUrl
+	}
+	if later.Plan != repo.PlanNone {
+		p.Plan = later.Plan
+	}
+	if later.TrackCount != 0 {
+		p.TrackCount = later.TrackCount
+	}
+	p.Verified = p.Verified || later.Verified
+	return p
+}
+
+// normalizeHandle turns a handle, or a profile URL or @mention of one, into a permalink.
+func normalizeHandle(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "@")
+	if i := strings.LastIndex(s, "soundcloud.com/"); i >= 0 {
+		s = strings.TrimSuffix(s[i+len("soundcloud.com/"):], "/")
+	}
+	return s
+}
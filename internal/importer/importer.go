@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// importBatchSize is how many people or edges are looked up or stored at a time.
+const importBatchSize = 1000
+
+// storedSource names problems found against the stored people rather than in a file.
+const storedSource = "stored people"
+
+// Store writes people and edges. graph.GraphStore implementations satisfy it.
+type Store interface {
+	UpsertNodes(ctx context.Context, people []repo.Person) (stored []repo.Person, err error)
+	UpsertEdges(ctx context.Context, edges []repo.Edge) error
+}
+
+// PeopleFinder looks up the stored people a dataset may describe.
+type PeopleFinder interface {
+	FindPeopleByUsernamesOrUrns(ctx context.Context, usernames, urns []string) (people []repo.Person, err error)
+}
+
+// Report summarizes an import.
+type Report struct {
+	// NodeRecords and EdgeRecords count the records read.
+	NodeRecords int
+	EdgeRecords int
+	// People counts the people described, and Placeholders the handles only edges refer to.
+	People       int
+	Placeholders int
+	// DuplicatePeople counts the records merged into a person read before.
+	DuplicatePeople int
+	// Follows and Engagement count the edges imported, and DuplicateEdges those read twice.
+	Follows        int
+	Engagement     int
+	DuplicateEdges int
+	// Invalid counts the records skipped.
+	Invalid int
+	// Created and Updated split the people imported into those not stored yet and the others.
+	Created int
+	Updated int
+	// Problems lists every record skipped or merged, in the order they were found.
+	Problems []Problem
+}
+
+// Importer stores datasets into the people graph.
+type Importer struct {
+	store  Store
+	people PeopleFinder
+}
+
+// NewImporter creates a new Importer.
+func NewImporter(store Store, peopleRepo PeopleFinder) *Importer {
+	return &Importer{store: store, people: peopleRepo}
+}
+
+// Import checks the people of a dataset against those stored, then stores them and their
+// edges, unless dryRun is set, in which case the report tells what would be stored.
+//
+// People are upserted by handle, so that fields missing from the dataset keep their stored
+// values. A person whose URN is stored under another handle is imported under the stored
+// handle, and a person whose handle is stored with another URN is skipped with their edges.
+func (im *Importer) Import(ctx context.Context, ds *Dataset, dryRun bool) (Report, error) {
+	report := ds.Report()
+	people, edges := ds.People(), ds.Edges()
+
+	// renamed maps handles to those the same people are stored under, and skipped the
+	// handles of conflicting people
+	renamed := make(map[string]string)
+	skipped := make(map[string]bool)
+	for batch := range slices.Chunk(people, importBatchSize) {
+		usernames, urns := make([]string, 0, len(batch)), make([]string, 0, len(batch))
+		for _, p := range batch {
+			usernames = append(usernames, p.Username)
+			if p.Urn != "" {
+				urns = append(urns, p.Urn)
+			}
+		}
+		stored, err := im.people.FindPeopleByUsernamesOrUrns(ctx, usernames, urns)
+		if err != nil {
+			slog.Error("Finding stored people", "error", err)
+			return report, err
+		}
+		byUsername, byURN := make(map[string]repo.Person, len(stored)), make(map[string]repo.Person, len(stored))
+		for _, p := range stored {
+			byUsername[p.Username] = p
+			if p.Urn != "" {
+				byURN[p.Urn] = p
+			}
+		}
+
+		for i, p := range batch {
+			if s, ok := byURN[p.Urn]; ok && p.Urn != "" {
+				if s.Username != p.Username {
+					report.Problems = append(report.Problems, Problem{
+						Source:  storedSource,
+						Message: fmt.Sprintf("urn %s is stored as @%s, importing @%s as @%s", p.Urn, s.Username, p.Username, s.Username),
+					})
+					renamed[p.Username] = s.Username
+					batch[i].Username = s.Username
+				}
+				report.Updated++
+				continue
+			}
+			if s, ok := byUsername[p.Username]; ok {
+				if s.Urn != "" && p.Urn != "" && s.Urn != p.Urn {
+					report.Problems = append(report.Problems, Problem{
+						Source:  storedSource,
+						Message: fmt.Sprintf("@%s is stored with urn %s, not %s, skipping them", p.Username, s.Urn, p.Urn),
+					})
+					report.Invalid++
+					skipped[p.Username] = true
+					continue
+				}
+				report.Updated++
+				continue
+			}
+			report.Created++
+		}
+	}
+
+	people = slices.DeleteFunc(people, func(p repo.Person) bool { return skipped[p.Username] })
+	edges = slices.DeleteFunc(slices.Clone(edges), func(e Edge) bool {
+		if !skipped[e.Source] && !skipped[e.Target] {
+			return false
+		}
+		report.Invalid++
+		if e.Kind == repo.EdgeKindFollows {
+			report.Follows--
+		} else {
+			report.Engagement--
+		}
+		return true
+	})
+	if dryRun {
+		return report, nil
+	}
+
+	ids := make(map[string]int64, len(people))
+	for batch := range slices.Chunk(people, importBatchSize) {
+		stored, err := im.store.UpsertNodes(ctx, batch)
+		if err != nil {
+			slog.Error("Storing people", "error", err)
+			return report, err
+		}
+		for _, p := range stored {
+			ids[p.Username] = p.Id
+		}
+	}
+
+	id := func(handle string) int64 {
+		if stored, ok := renamed[handle]; ok {
+			handle = stored
+		}
+		return ids[handle]
+	}
+	for batch := range slices.Chunk(edges, importBatchSize) {
+		stored := make([]repo.Edge, 0, len(batch))
+		for _, e := range batch {
+			stored = append(stored, repo.Edge{SourceID: id(e.Source), TargetID: id(e.Target), Kind: e.Kind, Weight: e.Weight})
+		}
+		if err := im.store.UpsertEdges(ctx, stored); err != nil {
+			slog.Error("Storing edges", "error", err)
+			return report, err
+		}
+	}
+	return report, nil
+}
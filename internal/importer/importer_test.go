@@ -0,0 +1,293 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"lopa.to/sonimulus/internal/export"
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/importer"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// fakePeople is a PeopleFinder over a fixed list of stored people.
+type fakePeople []repo.Person
+
+func (fp fakePeople) FindPeopleByUsernamesOrUrns(_ context.Context, usernames, urns []string) ([]repo.Person, error) {
+	var people []repo.Person
+	for _, p := range fp {
+		if slices.Contains(usernames, p.Username) || (p.Urn != "" && slices.Contains(urns, p.Urn)) {
+			people = append(people, p)
+		}
+	}
+	return people, nil
+}
+
+// follows returns the handles a stored person follows, sorted.
+func follows(t *testing.T, store *graph.MemoryStore, handle string) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	stored, err := store.UpsertNodes(ctx, []repo.Person{{Username: handle}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, err := store.Neighbors(ctx, stored[0].Id, repo.DirectionOut, []repo.EdgeKind{repo.EdgeKindFollows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int64, 0, len(edges))
+	for _, e := range edges {
+		ids = append(ids, e.TargetID)
+	}
+	people, _, err := store.Subgraph(ctx, ids, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var handles []string
+	for _, p := range people {
+		handles = append(handles, p.Username)
+	}
+	slices.Sort(handles)
+	return handles
+}
+
+func readCSV(t *testing.T, ds *importer.Dataset, name, content string) {
+	t.Helper()
+	if err := ds.ReadCSV(name, strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	ds := importer.NewDataset()
+	readCSV(t, ds, "nodes.csv", "id,username,urn,name,plan,trackCount,verified\n"+
+		"10,alice,soundcloud:users:1,Alice,Artist,3,true\n"+
+		"11,bob,,Bob,,,\n")
+	readCSV(t, ds, "edges.csv", "source,target\n10,11\n11,10\n10,carol\n")
+
+	store := graph.NewMemoryStore()
+	report, err := importer.NewImporter(store, fakePeople(nil)).Import(context.Background(), ds, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.People != 2 || report.Placeholders != 1 || report.Follows != 3 || report.Created != 3 || report.Invalid != 0 {
+		t.Errorf("got %+v", report)
+	}
+	if got := follows(t, store, "alice"); !slices.Equal(got, []string{"bob", "carol"}) {
+		t.Errorf("alice follows %v, want bob and carol", got)
+	}
+	if got := follows(t, store, "bob"); !slices.Equal(got, []string{"alice"}) {
+		t.Errorf("bob follows %v, want alice", got)
+	}
+
+	stored, _ := store.UpsertNodes(context.Background(), []repo.Person{{Username: "alice"}})
+	if p := stored[0]; p.Urn != "soundcloud:users:1" || p.Plan != repo.PlanArtist || p.TrackCount != 3 || !p.Verified {
+		t.Errorf("got %+v", p)
+	}
+}
+
+func TestImportJSONL(t *testing.T) {
+	ds := importer.NewDataset()
+	err := ds.ReadJSONL("graph.jsonl", strings.NewReader(`{"handle": "alice", "urn": "soundcloud:users:1"}
+{"username": "bob", "track_count": 2}
+
+{"source": "alice", "target": "@Bob"}
+{"type": "edge", "follower": "bob", "followee": "https://soundcloud.com/alice", "kind": "commented_on", "weight": 4}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := ds.Report()
+	if report.NodeRecords != 2 || report.EdgeRecords != 2 || report.Follows != 1 || report.Engagement != 1 || len(report.Problems) != 0 {
+		t.Errorf("got %+v", report)
+	}
+	want := []importer.Edge{
+		{Source: "alice", Target: "bob", Kind: repo.EdgeKindFollows, Weight: 1},
+		{Source: "bob", Target: "alice", Kind: repo.EdgeKindCommentedOn, Weight: 4},
+	}
+	if got := ds.Edges(); !slices.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// TestImportExport reads back the CSV archives and node-link graphs the export command writes.
+func TestImportExport(t *testing.T) {
+	people := []repo.Person{
+		{Id: 1, Username: "alice", Urn: "soundcloud:users:1", Name: "Alice", Plan: repo.PlanArtistPro, Metrics: &repo.PersonMetrics{PageRank: 0.5}},
+		{Id: 2, Username: "bob", Name: "Bob", Plan: repo.PlanNone},
+	}
+	edges := []repo.Edge{{SourceID: 1, TargetID: 2, Kind: repo.EdgeKindFollows, Weight: 1}}
+
+	for _, format := range []export.Format{export.FormatCSV, export.FormatJSON} {
+		var buf bytes.Buffer
+		enc, err := export.NewEncoder(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range people {
+			enc.WriteNode(p)
+		}
+		for _, e := range edges {
+			enc.WriteEdge(e)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ds := importer.NewDataset()
+		if format == export.FormatCSV {
+			err = ds.ReadZip("sonimulus.zip", bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		} else {
+			err = ds.ReadNodeLink("sonimulus.json", &buf)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		report := ds.Report()
+		if report.People != 2 || report.Follows != 1 || report.Invalid != 0 || report.Placeholders != 0 {
+			t.Errorf("%s: got %+v", format, report)
+		}
+		got := ds.People()
+		if len(got) != 2 || got[0].Urn != "soundcloud:users:1" || got[0].Plan != repo.PlanArtistPro || got[1].Name != "Bob" {
+			t.Errorf("%s: got %+v", format, got)
+		}
+	}
+}
+
+func TestDedup(t *testing.T) {
+	ds := importer.NewDataset()
+	readCSV(t, ds, "a.csv", "username,urn,name,trackCount\n"+
+		"alice,soundcloud:users:1,Alice,\n"+
+		// The same person, by handle, filling in their track count
+		"ALICE,,,7\n"+
+		// The same person, by urn, under a handle they changed to
+		"alice2,soundcloud:users:1,,\n")
+	readCSV(t, ds, "b.csv", "source,target,kind,weight\n"+
+		"alice2,bob,,\n"+
+		"alice,bob,,\n"+
+		"alice,bob,liked_track_of,2\n"+
+		"alice,bob,liked_track_of,5\n")
+
+	report := ds.Report()
+	if report.People != 1 || report.DuplicatePeople != 2 || report.DuplicateEdges != 2 || report.Follows != 1 || report.Engagement != 1 {
+		t.Errorf("got %+v", report)
+	}
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0].Message, "keeping @alice") {
+		t.Errorf("got problems %v, want the handle change", report.Problems)
+	}
+
+	people := ds.People()
+	if len(people) != 2 || people[0].Username != "alice" || people[0].Name != "Alice" || people[0].TrackCount != 7 || people[1].Username != "bob" {
+		t.Errorf("got %+v", people)
+	}
+	if e := ds.Edges()[1]; e.Kind != repo.EdgeKindLikedTrackOf || e.Weight != 5 {
+		t.Errorf("got %+v, want the heaviest like", e)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	ds := importer.NewDataset()
+	readCSV(t, ds, "nodes.csv", "id,username,urn,plan,trackCount\n"+
+		"1,,,,\n"+
+		"2,not a handle,,,\n"+
+		"3,alice,urn:1,,\n"+
+		"4,bob,,Gold,\n"+
+		"5,carol,,,-1\n"+
+		"6,dave,,,,\n"+
+		"7,erin,,,\n")
+	readCSV(t, ds, "edges.csv", "source,target,kind,weight\n"+
+		"7,7,,\n"+
+		"7,99,,\n"+
+		"7,frank,sounds_like,\n"+
+		"7,frank,liked_track_of,0\n"+
+		"7,frank,,2\n")
+
+	report := ds.Report()
+	for _, want := range []string{
+		"nodes.csv:2: missing username",
+		`nodes.csv:3: invalid username "not a handle"`,
+		`nodes.csv:4: invalid urn "urn:1"`,
+		`nodes.csv:5: invalid plan "Gold"`,
+		`nodes.csv:6: invalid trackCount "-1"`,
+		"nodes.csv:7: got 6 columns, want 5",
+		"edges.csv:2: @erin has a follows edge to themselves",
+		"edges.csv:3: unknown node id 99",
+		`edges.csv:4: edge kind "sounds_like" cannot be imported`,
+		`edges.csv:5: invalid weight "0"`,
+		"edges.csv:6: follows weigh 1, ignoring weight 2",
+	} {
+		if !slices.ContainsFunc(report.Problems, func(p importer.Problem) bool { return p.String() == want }) {
+			t.Errorf("missing problem %q", want)
+		}
+	}
+	if report.Invalid != 10 || report.People != 1 || report.Follows != 1 {
+		t.Errorf("got %+v", report)
+	}
+}
+
+func TestImportStoredConflicts(t *testing.T) {
+	stored := fakePeople{
+		{Id: 1, Username: "alice-old", Urn: "soundcloud:users:1"},
+		{Id: 2, Username: "bob", Urn: "soundcloud:users:2"},
+		{Id: 3, Username: "carol"},
+	}
+	ds := importer.NewDataset()
+	readCSV(t, ds, "nodes.csv", "username,urn\n"+
+		"alice,soundcloud:users:1\n"+
+		"bob,soundcloud:users:99\n"+
+		"carol,soundcloud:users:3\n"+
+		"dave,\n")
+	readCSV(t, ds, "edges.csv", "source,target\nalice,carol\nbob,carol\ndave,alice\n")
+
+	store := graph.NewMemoryStore()
+	report, err := importer.NewImporter(store, stored).Import(context.Background(), ds, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Created != 1 || report.Updated != 2 || report.Invalid != 2 || report.Follows != 2 {
+		t.Errorf("got %+v", report)
+	}
+	if got := follows(t, store, "alice-old"); !slices.Equal(got, []string{"carol"}) {
+		t.Errorf("alice follows %v under their stored handle, want carol", got)
+	}
+	if got := follows(t, store, "dave"); !slices.Equal(got, []string{"alice-old"}) {
+		t.Errorf("dave follows %v, want alice under their stored handle", got)
+	}
+	if got := follows(t, store, "bob"); len(got) != 0 {
+		t.Errorf("bob follows %v, want them skipped", got)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	ds := importer.NewDataset()
+	readCSV(t, ds, "edges.csv", "source,target\nalice,bob\n")
+
+	store := graph.NewMemoryStore()
+	report, err := importer.NewImporter(store, fakePeople{{Id: 1, Username: "alice"}}).Import(context.Background(), ds, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Created != 1 || report.Updated != 1 || report.Follows != 1 {
+		t.Errorf("got %+v", report)
+	}
+	if people, _, _ := store.Subgraph(context.Background(), []int64{1, 2}, nil); len(people) != 0 {
+		t.Errorf("got %+v stored by a dry run", people)
+	}
+}
+
+func TestReadCSVUnknownColumns(t *testing.T) {
+	ds := importer.NewDataset()
+	if err := ds.ReadCSV("other.csv", strings.NewReader("a,b\n1,2\n")); err == nil {
+		t.Error("got no error for a file that is neither a node nor an edge list")
+	}
+}
@@ -0,0 +1,257 @@
+package importer
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxLineSize bounds the lines of JSONL files.
+const maxLineSize = 1 << 20
+
+// columns maps normalized column names and their aliases to the fields of nodes and edges.
+// Columns of neither, such as the metrics written by the export command, are ignored.
+var columns = map[string]string{
+	"id":         "id",
+	"key":        "id",
+	"username":   "username",
+	"handle":     "username",
+	"permalink":  "username",
+	"urn":        "urn",
+	"name":       "name",
+	"label":      "label",
+	"imageurl":   "imageurl",
+	"avatarurl":  "imageurl",
+	"verified":   "verified",
+	"plan":       "plan",
+	"trackcount": "trackcount",
+	"source":     "source",
+	"follower":   "source",
+	"from":       "source",
+	"target":     "target",
+	"followee":   "target",
+	"to":         "target",
+	"kind":       "kind",
+	"weight":     "weight",
+}
+
+// column returns the field a column name maps to, ignoring case and separators, or "" when
+// it maps to none.
+func column(name string) string {
+	name = strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+	return columns[name]
+}
+
+// ReadFile reads a file by its extension: a .csv node or edge list, a .jsonl or .ndjson file
+// of node and edge records, a .json node-link graph, or a .zip archive of such files, as the
+// export command writes.
+func (ds *Dataset) ReadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	source := filepath.Base(name)
+	if strings.EqualFold(filepath.Ext(name), ".zip") {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return ds.ReadZip(source, f, info.Size())
+	}
+	return ds.read(source, f)
+}
+
+// read reads a file of a dataset by the extension of its source name.
+func (ds *Dataset) read(source string, r io.Reader) error {
+	switch strings.ToLower(path.Ext(source)) {
+	case ".csv":
+		return ds.ReadCSV(source, r)
+	case ".jsonl", ".ndjson":
+		return ds.ReadJSONL(source, r)
+	case ".json":
+		return ds.ReadNodeLink(source, r)
+	default:
+		return fmt.Errorf("%s: unknown file type", source)
+	}
+}
+
+// ReadZip reads every CSV, JSONL and node-link JSON file of a zip archive.
+func (ds *Dataset) ReadZip(source string, r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	for _, f := range zr.File {
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".csv", ".jsonl", ".ndjson", ".json":
+		default:
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", source, f.Name, err)
+		}
+		err = ds.read(source+"/"+f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadCSV reads a node list, or an edge list when it has source and target columns. The first
+// row names the columns.
+func (ds *Dataset) ReadCSV(source string, r io.Reader) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("%s: reading header: %w", source, err)
+	}
+	fields := make([]string, len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		fields[i] = column(name)
+		present[fields[i]] = true
+	}
+	edges := present["source"] && present["target"]
+	if !edges && !present["username"] {
+		return fmt.Errorf("%s: neither a node list with a username column nor an edge list with source and target columns", source)
+	}
+
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		line, _ := cr.FieldPos(0)
+		if errors.Is(err, csv.ErrFieldCount) {
+			ds.invalid(source, line, "got %d columns, want %d", len(row), len(header))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+
+		record := make(map[string]string, len(row))
+		for i, value := range row {
+			if fields[i] != "" {
+				record[fields[i]] = value
+			}
+		}
+		if edges {
+			ds.addEdge(source, line, record)
+		} else {
+			ds.addNode(source, line, record)
+		}
+	}
+}
+
+// ReadJSONL reads a file of one JSON object per line, each a node or an edge. Edges have
+// source and target fields, or a type field of "edge".
+func (ds *Dataset) ReadJSONL(source string, r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxLineSize)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+
+		var object map[string]any
+		if err := decode(strings.NewReader(sc.Text()), &object); err != nil {
+			ds.invalid(source, line, "invalid JSON: %v", err)
+			continue
+		}
+		ds.addObject(source, line, object, "")
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	return nil
+}
+
+// ReadNodeLink reads a node-link JSON graph, as written by d3, NetworkX and the export command.
+func (ds *Dataset) ReadNodeLink(source string, r io.Reader) error {
+	var graph struct {
+		Nodes []map[string]any `json:"nodes"`
+		Links []map[string]any `json:"links"`
+		// Edges is how NetworkX 3.4 and later name links
+		Edges []map[string]any `json:"edges"`
+	}
+	if err := decode(r, &graph); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	for i, node := range graph.Nodes {
+		ds.addObject(source+" nodes", i+1, node, "node")
+	}
+	for i, link := range append(graph.Links, graph.Edges...) {
+		ds.addObject(source+" links", i+1, link, "edge")
+	}
+	return nil
+}
+
+// addObject adds a JSON object as a node or an edge, as typ says or as its fields suggest.
+func (ds *Dataset) addObject(source string, record int, object map[string]any, typ string) {
+	fields := make(map[string]string, len(object))
+	for name, value := range object {
+		if name == "type" {
+			typ, _ = value.(string)
+			continue
+		}
+		if field := column(name); field != "" {
+			fields[field] = jsonString(value)
+		}
+	}
+
+	switch typ {
+	case "node":
+		ds.addNode(source, record, fields)
+	case "edge":
+		ds.addEdge(source, record, fields)
+	case "":
+		if _, ok := fields["source"]; ok {
+			ds.addEdge(source, record, fields)
+		} else {
+			ds.addNode(source, record, fields)
+		}
+	default:
+		ds.invalid(source, record, "unknown record type %q", typ)
+	}
+}
+
+// decode decodes JSON, keeping numbers as written so that ids are not rounded.
+func decode(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// jsonString formats a JSON scalar as a CSV field would hold it.
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
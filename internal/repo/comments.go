@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// Comment is a comment left by one person on a track uploaded by another.
+type Comment struct {
+	Urn         string
+	TrackUrn    string
+	CommenterID int64
+	ArtistID    int64
+	Body        string
+	CreatedAt   time.Time
+}
+
+// CommentsRepository is a repository for track comments and the interactions they imply.
+type CommentsRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewCommentsRepository creates a new CommentsRepository.
+func NewCommentsRepository(db *sql.DB) *CommentsRepository {
+	return &CommentsRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// UpsertComments stores comments, ignoring any that were already ingested.
+func (cr *CommentsRepository) UpsertComments(ctx context.Context, comments []Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	if cr.dialect == data.DialectSQLite {
+		return cr.upsertCommentsSQLite(ctx, comments)
+	}
+
+	var (
+		urns       = make([]string, 0, len(comments))
+		trackUrns  = make([]string, 0, len(comments))
+		commenters = make([]int64, 0, len(comments))
+		artists    = make([]int64, 0, len(comments))
+		bodies     = make([]string, 0, len(comments))
+		createdAts = make([]string, 0, len(comments))
+	)
+	for _, c := range comments {
+		urns = append(urns, c.Urn)
+		trackUrns = append(trackUrns, c.TrackUrn)
+		commenters = append(commenters, c.CommenterID)
+		artists = append(artists, c.ArtistID)
+		bodies = append(bodies, c.Body)
+		createdAts = append(createdAts, c.CreatedAt.Format(time.RFC3339Nano))
+	}
+
+	_, err := cr.db.ExecContext(
+		ctx,
+		`INSERT INTO comments (urn, track_urn, commenter_id, artist_id, body, created_at)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::bigint[], $4::bigint[], $5::text[], $6::timestamptz[])
+		ON CONFLICT (urn) DO NOTHING;`,
+		pq.Array(urns), pq.Array(trackUrns), pq.Array(commenters), pq.Array(artists), pq.Array(bodies), pq.Array(createdAts),
+	)
+	if err != nil {
+		slog.Error("failed to upsert comments", "error", err)
+	}
+	return err
+}
+
+// upsertCommentsSQLite is UpsertComments for SQLite, which has no arrays, so the comments
+// are bound as a JSON array instead.
+func (cr *CommentsRepository) upsertCommentsSQLite(ctx context.Context, comments []Comment) error {
+	type comment struct {
+		Urn         string `json:"urn"`
+		TrackUrn    string `json:"track_urn"`
+		CommenterID int64  `json:"commenter_id"`
+		ArtistID    int64  `json:"artist_id"`
+		Body        string `json:"body"`
+		CreatedAt   string `json:"created_at"`
+	}
+	staged := make([]comment, 0, len(comments))
+	for _, c := range comments {
+		staged = append(staged, comment{
+			Urn:         c.Urn,
+			TrackUrn:    c.TrackUrn,
+			CommenterID: c.CommenterID,
+			ArtistID:    c.ArtistID,
+			Body:        c.Body,
+			CreatedAt:   c.CreatedAt.UTC().Format(sqliteTimeFormat),
+		})
+	}
+	b, err := json.Marshal(staged)
+	if err != nil {
+		return err
+	}
+
+	// SQLite only parses an upsert from a SELECT that has a WHERE clause
+	_, err = cr.db.ExecContext(
+		ctx,
+		`INSERT INTO comments (urn, track_urn, commenter_id, artist_id, body, created_at)
+		SELECT json_extract(value, '$.urn'), json_extract(value, '$.track_urn'), json_extract(value, '$.commenter_id'),
+			json_extract(value, '$.artist_id'), json_extract(value, '$.body'), json_extract(value, '$.created_at')
+		FROM json_each($1) WHERE true
+		ON CONFLICT (urn) DO NOTHING;`,
+		string(b),
+	)
+	if err != nil {
+		slog.Error("failed to upsert comments", "error", err)
+	}
+	return err
+}
+
+// MaterializeEdges rebuilds the commented_on edges pointing at an artist from the stored comments.
+// Each edge is weighted by the number of comments, and spans the first and last comment time.
+func (cr *CommentsRepository) MaterializeEdges(ctx context.Context, artistID int64) error {
+	_, err := cr.db.ExecContext(
+		ctx,
+		`INSERT INTO edges (source_id, target_id, kind, weight, first_at, last_at)
+		SELECT commenter_id, artist_id, 'commented_on', count(*), min(created_at), max(created_at)
+		FROM comments
+		WHERE artist_id = $1 AND commenter_id <> artist_id
+		GROUP BY commenter_id, artist_id
+		ON CONFLICT (source_id, target_id, kind) DO UPDATE SET
+			weight = EXCLUDED.weight,
+			first_at = EXCLUDED.first_at,
+			last_at = EXCLUDED.last_at,
+			updated_at = `+currentTime(cr.dialect)+`;`,
+		artistID,
+	)
+	if err != nil {
+		slog.Error("failed to materialize comment edges", "error", err)
+	}
+	return err
+}
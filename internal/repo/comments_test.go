@@ -0,0 +1,81 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestComments(t *testing.T) {
+	forEachDB(t, testComments)
+}
+
+func testComments(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	cr := repo.NewCommentsRepository(db)
+	er := repo.NewEdgesRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		p, err := pr.Upsert(ctx, repo.Person{Username: handle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+	alice, bob, carol := ids[0], ids[1], ids[2]
+
+	first := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 3, 5, 8, 30, 0, 0, time.UTC)
+	comments := []repo.Comment{
+		{Urn: "c1", TrackUrn: "a1", CommenterID: bob, ArtistID: alice, Body: "nice", CreatedAt: last},
+		{Urn: "c2", TrackUrn: "a2", CommenterID: bob, ArtistID: alice, Body: "nicer", CreatedAt: first},
+		{Urn: "c3", TrackUrn: "a1", CommenterID: carol, ArtistID: alice, Body: "wow", CreatedAt: first},
+		// Replying on her own track is not an edge
+		{Urn: "c4", TrackUrn: "a1", CommenterID: alice, ArtistID: alice, Body: "thanks", CreatedAt: last},
+	}
+	if err := cr.UpsertComments(ctx, comments); err != nil {
+		t.Fatal(err)
+	}
+	// Comments already ingested are ignored, so ingesting them again counts them once
+	if err := cr.UpsertComments(ctx, comments[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := cr.MaterializeEdges(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	edges, err := er.FindEdges(ctx, alice, repo.DirectionIn, []repo.EdgeKind{repo.EdgeKindCommentedOn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 2 {
+		t.Fatalf("got edges %+v, want one from bob and one from carol", edges)
+	}
+	fromBob := edges[0]
+	if fromBob.SourceID != bob || fromBob.Kind != repo.EdgeKindCommentedOn || fromBob.Weight != 2 {
+		t.Errorf("got edge %+v, want bob commented twice", fromBob)
+	}
+	if fromBob.FirstAt == nil || !fromBob.FirstAt.Equal(first) || fromBob.LastAt == nil || !fromBob.LastAt.Equal(last) {
+		t.Errorf("edge from bob spans %v to %v, want %v to %v", fromBob.FirstAt, fromBob.LastAt, first, last)
+	}
+	if fromCarol := edges[1]; fromCarol.SourceID != carol || fromCarol.Weight != 1 {
+		t.Errorf("got edge %+v, want carol commented once", fromCarol)
+	}
+
+	// Materializing again rebuilds the same edges
+	if err := cr.MaterializeEdges(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	again, err := er.FindEdges(ctx, alice, repo.DirectionIn, []repo.EdgeKind{repo.EdgeKindCommentedOn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 2 || again[0].Weight != 2 || again[1].Weight != 1 {
+		t.Errorf("edges after materializing again: got %+v", again)
+	}
+}
@@ -0,0 +1,256 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// CommunityAlgorithm is an algorithm detecting communities in the follow graph.
+type CommunityAlgorithm string
+
+const (
+	CommunityAlgorithmLouvain          CommunityAlgorithm = "louvain"
+	CommunityAlgorithmLabelPropagation CommunityAlgorithm = "label_propagation"
+)
+
+// Valid reports whether a is a known community detection algorithm.
+func (a CommunityAlgorithm) Valid() bool {
+	switch a {
+	case CommunityAlgorithmLouvain, CommunityAlgorithmLabelPropagation:
+		return true
+	default:
+		return false
+	}
+}
+
+// CommunityRun is a community detection run over the follow graph.
+type CommunityRun struct {
+	Id          int64
+	Algorithm   CommunityAlgorithm
+	Modularity  float64
+	Communities int
+	CreatedAt   time.Time
+}
+
+// Community is a community found by a run.
+type Community struct {
+	// Id numbers the community within its run, from 0 by decreasing size.
+	Id   int
+	Size int
+	// TopMembers lists the ids of the most followed members, most followed first.
+	TopMembers []int64
+	// Follows counts the follows between members, and Reciprocated those returned.
+	Follows      int
+	Reciprocated int
+}
+
+// CommunitiesRepository is a repository for community detection runs.
+type CommunitiesRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewCommunitiesRepository creates a new CommunitiesRepository.
+func NewCommunitiesRepository(db *sql.DB) *CommunitiesRepository {
+	return &CommunitiesRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// CreateRun stores a run with its communities and the community of every member, by person id.
+func (cr *CommunitiesRepository) CreateRun(ctx context.Context, run CommunityRun, communities []Community, members map[int64]int) (CommunityRun, error) {
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return CommunityRun{}, err
+	}
+	defer tx.Rollback()
+
+	run.Communities = len(communities)
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO community_runs (algorithm, modularity, communities) VALUES ($1, $2, $3) RETURNING id, created_at;`,
+		run.Algorithm, run.Modularity, run.Communities,
+	).Scan(&run.Id, &run.CreatedAt)
+	if err != nil {
+		slog.Error("failed to create community run", "error", err)
+		return CommunityRun{}, err
+	}
+
+	stmt, err := copyIn(ctx, tx, cr.dialect, "communities", "run_id", "id", "size", "top_members", "follows", "reciprocated")
+	if err != nil {
+		slog.Error("failed to start copying communities", "error", err)
+		return CommunityRun{}, err
+	}
+	for _, c := range communities {
+		topMembers, err := int64sArg(cr.dialect, c.TopMembers)
+		if err != nil {
+			stmt.Close()
+			return CommunityRun{}, err
+		}
+		if _, err = stmt.ExecContext(ctx, run.Id, c.Id, c.Size, topMembers, c.Follows, c.Reciprocated); err != nil {
+			stmt.Close()
+			slog.Error("failed to copy communities", "error", err)
+			return CommunityRun{}, err
+		}
+	}
+	if err = flushCopy(ctx, cr.dialect, stmt); err != nil {
+		slog.Error("failed to copy communities", "error", err)
+		return CommunityRun{}, err
+	}
+
+	stmt, err = copyIn(ctx, tx, cr.dialect, "community_members", "run_id", "person_id", "community_id")
+	if err != nil {
+		slog.Error("failed to start copying community members", "error", err)
+		return CommunityRun{}, err
+	}
+	for personID, communityID := range members {
+		if _, err = stmt.ExecContext(ctx, run.Id, personID, communityID); err != nil {
+			stmt.Close()
+			slog.Error("failed to copy community members", "error", err)
+			return CommunityRun{}, err
+		}
+	}
+	if err = flushCopy(ctx, cr.dialect, stmt); err != nil {
+		slog.Error("failed to copy community members", "error", err)
+		return CommunityRun{}, err
+	}
+
+	return run, tx.Commit()
+}
+
+// LatestRun returns the most recent run of an algorithm.
+func (cr *CommunitiesRepository) LatestRun(ctx context.Context, algorithm CommunityAlgorithm) (run CommunityRun, found bool, err error) {
+	err = cr.db.QueryRowContext(
+		ctx,
+		`SELECT id, algorithm, modularity, communities, created_at FROM community_runs
+		WHERE algorithm = $1 ORDER BY created_at DESC, id DESC LIMIT 1;`,
+		algorithm,
+	).Scan(&run.Id, &run.Algorithm, &run.Modularity, &run.Communities, &run.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CommunityRun{}, false, nil
+	}
+	if err != nil {
+		slog.Error("failed to query community run", "error", err)
+		return CommunityRun{}, false, err
+	}
+	return run, true, nil
+}
+
+// ListCommunities returns a page of the communities of a run, largest first.
+func (cr *CommunitiesRepository) ListCommunities(ctx context.Context, runID int64, limit, offset int) (communities []Community, err error) {
+	rows, err := cr.db.QueryContext(
+		ctx,
+		`SELECT id, size, top_members, follows, reciprocated FROM communities
+		WHERE run_id = $1 ORDER BY id LIMIT $2 OFFSET $3;`,
+		runID, limit, offset,
+	)
+	if err != nil {
+		slog.Error("failed to query communities", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Community
+		if err = rows.Scan(&c.Id, &c.Size, int64sDest(cr.dialect, &c.TopMembers), &c.Follows, &c.Reciprocated); err != nil {
+			return nil, err
+		}
+		communities = append(communities, c)
+	}
+	return communities, rows.Err()
+}
+
+// FindCommunity returns a community of a run.
+func (cr *CommunitiesRepository) FindCommunity(ctx context.Context, runID int64, communityID int) (c Community, found bool, err error) {
+	err = cr.db.QueryRowContext(
+		ctx,
+		`SELECT id, size, top_members, follows, reciprocated FROM communities WHERE run_id = $1 AND id = $2;`,
+		runID, communityID,
+	).Scan(&c.Id, &c.Size, int64sDest(cr.dialect, &c.TopMembers), &c.Follows, &c.Reciprocated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Community{}, false, nil
+	}
+	if err != nil {
+		slog.Error("failed to query community", "error", err)
+		return Community{}, false, err
+	}
+	return c, true, nil
+}
+
+// FindMemberships returns the community of every person in ids that belongs to one in a run,
+// by person id.
+func (cr *CommunitiesRepository) FindMemberships(ctx context.Context, runID int64, ids []int64) (memberships map[int64]int, err error) {
+	byPerson, arg, err := anyOf(cr.dialect, "person_id", 2, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := cr.db.QueryContext(
+		ctx,
+		`SELECT person_id, community_id FROM community_members WHERE run_id = $1 AND `+byPerson+`;`,
+		runID, arg,
+	)
+	if err != nil {
+		slog.Error("failed to query community members", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships = make(map[int64]int)
+	for rows.Next() {
+		var personID int64
+		var communityID int
+		if err = rows.Scan(&personID, &communityID); err != nil {
+			return nil, err
+		}
+		memberships[personID] = communityID
+	}
+	return memberships, rows.Err()
+}
+
+// ListMemberships returns the community of every member of a run, by person id.
+func (cr *CommunitiesRepository) ListMemberships(ctx context.Context, runID int64) (memberships map[int64]int, err error) {
+	rows, err := cr.db.QueryContext(ctx, `SELECT person_id, community_id FROM community_members WHERE run_id = $1;`, runID)
+	if err != nil {
+		slog.Error("failed to query community members", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships = make(map[int64]int)
+	for rows.Next() {
+		var personID int64
+		var communityID int
+		if err = rows.Scan(&personID, &communityID); err != nil {
+			return nil, err
+		}
+		memberships[personID] = communityID
+	}
+	return memberships, rows.Err()
+}
+
+// ListMembers returns a page of the ids of the members of a community, ordered by id.
+func (cr *CommunitiesRepository) ListMembers(ctx context.Context, runID int64, communityID int, limit, offset int) (ids []int64, err error) {
+	rows, err := cr.db.QueryContext(
+		ctx,
+		`SELECT person_id FROM community_members WHERE run_id = $1 AND community_id = $2
+		ORDER BY person_id LIMIT $3 OFFSET $4;`,
+		runID, communityID, limit, offset,
+	)
+	if err != nil {
+		slog.Error("failed to query community members", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
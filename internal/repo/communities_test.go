@@ -0,0 +1,78 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestCommunityRuns(t *testing.T) {
+	forEachDB(t, testCommunityRuns)
+}
+
+func testCommunityRuns(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	cr := repo.NewCommunitiesRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		p, err := pr.Upsert(ctx, repo.Person{Username: handle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+
+	if _, found, err := cr.LatestRun(ctx, repo.CommunityAlgorithmLouvain); err != nil || found {
+		t.Fatalf("before any run: found = %v, err = %v", found, err)
+	}
+
+	communities := []repo.Community{
+		{Id: 0, Size: 2, TopMembers: []int64{ids[1], ids[0]}, Follows: 2, Reciprocated: 2},
+		{Id: 1, Size: 1, TopMembers: []int64{ids[2]}},
+	}
+	members := map[int64]int{ids[0]: 0, ids[1]: 0, ids[2]: 1}
+	created, err := cr.CreateRun(ctx, repo.CommunityRun{Algorithm: repo.CommunityAlgorithmLouvain, Modularity: 0.25}, communities, members)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, found, err := cr.LatestRun(ctx, repo.CommunityAlgorithmLouvain)
+	if err != nil || !found {
+		t.Fatalf("found = %v, err = %v", found, err)
+	}
+	if run.Id != created.Id || run.Communities != 2 || run.Modularity != 0.25 {
+		t.Errorf("got %+v, want %+v", run, created)
+	}
+	if _, found, _ := cr.LatestRun(ctx, repo.CommunityAlgorithmLabelPropagation); found {
+		t.Error("found a run of another algorithm")
+	}
+
+	listed, err := cr.ListCommunities(ctx, run.Id, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 2 || !slices.Equal(listed[0].TopMembers, communities[0].TopMembers) || listed[0].Reciprocated != 2 {
+		t.Errorf("got %+v, want %+v", listed, communities)
+	}
+
+	memberships, err := cr.FindMemberships(ctx, run.Id, []int64{ids[0], ids[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 2 || memberships[ids[0]] != 0 || memberships[ids[2]] != 1 {
+		t.Errorf("got %v", memberships)
+	}
+
+	got, err := cr.ListMembers(ctx, run.Id, 0, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ids[:2]; !slices.Equal(got, want) {
+		t.Errorf("members: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// testPostgresURIEnv names the variable pointing the repository tests at a Postgres
+// database. Every table in it is truncated, so it must be a disposable database.
+const testPostgresURIEnv = "TEST_POSTGRES_URI"
+
+// newTestDB migrates and empties the test database, skipping the test when none is configured.
+func newTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	uri := os.Getenv(testPostgresURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", testPostgresURIEnv)
+	}
+
+	db, err := data.NewPostgresDB(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	if _, err := data.MigrateUp(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `TRUNCATE people, users, community_runs, layout_runs, snapshots, kv RESTART IDENTITY CASCADE;`); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// newSQLiteTestDB migrates a new SQLite database in a temporary file.
+func newSQLiteTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	db, err := data.NewSQLiteDB(filepath.Join(t.TempDir(), "sonimulus.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := data.MigrateUp(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// forEachDB runs test against a new Postgres database, when one is configured, and a new
+// SQLite database, for repositories that support both.
+func forEachDB(t *testing.T, test func(t *testing.T, db *sql.DB)) {
+	t.Run("postgres", func(t *testing.T) { test(t, newTestDB(t)) })
+	t.Run("sqlite", func(t *testing.T) { test(t, newSQLiteTestDB(t)) })
+}
@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// sqliteTimeFormat is how SQLite stores timestamps, so that bound times compare as text.
+const sqliteTimeFormat = "2006-01-02 15:04:05.000"
+
+// anyOf returns a condition matching column against any of values, bound as parameter n,
+// and the argument to bind. SQLite has no arrays, so values are bound there as a JSON array.
+func anyOf[T any](dialect data.Dialect, column string, n int, values []T) (cond string, arg any, err error) {
+	if dialect != data.DialectSQLite {
+		return fmt.Sprintf("%s = ANY($%d)", column, n), pq.Array(values), nil
+	}
+
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s IN (SELECT value FROM json_each($%d))", column, n), string(b), nil
+}
+
+// timeArg returns the argument to bind t as in a dialect.
+func timeArg(dialect data.Dialect, t time.Time) any {
+	if dialect == data.DialectSQLite {
+		return t.UTC().Format(sqliteTimeFormat)
+	}
+	return t
+}
+
+// limitArg returns the argument to bind limit as in a LIMIT clause of a dialect, where a
+// limit that is not positive means no limit. Postgres spells that as a NULL limit, and
+// SQLite as a negative one.
+func limitArg(dialect data.Dialect, limit int) any {
+	switch {
+	case limit > 0:
+		return limit
+	case dialect == data.DialectSQLite:
+		return -1
+	default:
+		return nil
+	}
+}
+
+// currentTime returns the expression for the current time in a dialect, as stored in
+// timestamp columns.
+func currentTime(dialect data.Dialect) string {
+	if dialect == data.DialectSQLite {
+		return "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+	}
+	return "now()"
+}
+
+// copyIn prepares a statement in tx inserting one row into columns of table per Exec. In
+// Postgres the rows are streamed with COPY; SQLite has no COPY, so each Exec inserts its
+// row, which is as fast within a transaction. Either way flushCopy must be called last.
+func copyIn(ctx context.Context, tx *sql.Tx, dialect data.Dialect, table string, columns ...string) (*sql.Stmt, error) {
+	if dialect != data.DialectSQLite {
+		return tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	}
+
+	params := make([]string, len(columns))
+	for i := range columns {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return tx.PrepareContext(ctx, fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s);",
+		table, strings.Join(columns, ", "), strings.Join(params, ", "),
+	))
+}
+
+// flushCopy sends the rows buffered by a statement from copyIn, and closes it.
+func flushCopy(ctx context.Context, dialect data.Dialect, stmt *sql.Stmt) error {
+	if dialect == data.DialectSQLite {
+		return stmt.Close()
+	}
+
+	// An empty Exec flushes the buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// int64sArg returns the argument to bind values as in an array column of a dialect.
+// SQLite stores arrays as JSON.
+func int64sArg(dialect data.Dialect, values []int64) (any, error) {
+	if dialect != data.DialectSQLite {
+		return pq.Array(values), nil
+	}
+	if values == nil {
+		values = []int64{}
+	}
+	b, err := json.Marshal(values)
+	return string(b), err
+}
+
+// int64sDest returns the destination to scan an array column of a dialect into values.
+func int64sDest(dialect data.Dialect, values *[]int64) sql.Scanner {
+	if dialect == data.DialectSQLite {
+		return (*jsonInt64s)(values)
+	}
+	return (*pq.Int64Array)(values)
+}
+
+// jsonInt64s scans a JSON array of integers.
+type jsonInt64s []int64
+
+func (j *jsonInt64s) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), (*[]int64)(j))
+	case []byte:
+		return json.Unmarshal(src, (*[]int64)(j))
+	case nil:
+		*j = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into an array of integers", src)
+	}
+}
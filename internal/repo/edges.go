@@ -0,0 +1,297 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// EdgeKind is the type of relationship an edge represents.
+type EdgeKind string
+
+const (
+	EdgeKindFollows         EdgeKind = "follows"
+	EdgeKindLikedTrackOf    EdgeKind = "liked_track_of"
+	EdgeKindRepostedTrackOf EdgeKind = "reposted_track_of"
+	EdgeKindCommentedOn     EdgeKind = "commented_on"
+	EdgeKindSoundsLike      EdgeKind = "sounds_like"
+	EdgeKindCoPlaylisted    EdgeKind = "co_playlisted"
+)
+
+// Layer is a graph over people built from one family of relationships.
+// Layers are stored separately and never mixed in a single query.
+type Layer string
+
+const (
+	// LayerSocial holds follows and engagement between people.
+	LayerSocial Layer = "social"
+	// LayerSimilarity holds sounds_like edges derived from related tracks.
+	LayerSimilarity Layer = "similarity"
+	// LayerPlaylists holds co_playlisted edges between artists sharing playlists.
+	LayerPlaylists Layer = "playlists"
+)
+
+// Valid reports whether l is a known layer.
+func (l Layer) Valid() bool {
+	switch l {
+	case LayerSocial, LayerSimilarity, LayerPlaylists:
+		return true
+	default:
+		return false
+	}
+}
+
+// EdgeKinds lists every edge kind in the social graph.
+var EdgeKinds = []EdgeKind{
+	EdgeKindFollows,
+	EdgeKindLikedTrackOf,
+	EdgeKindRepostedTrackOf,
+	EdgeKindCommentedOn,
+}
+
+// Direction selects which edges of a person are returned, relative to that person.
+type Direction string
+
+const (
+	DirectionOut  Direction = "out"
+	DirectionIn   Direction = "in"
+	DirectionBoth Direction = "both"
+)
+
+// Valid reports whether d is a known direction.
+func (d Direction) Valid() bool {
+	switch d {
+	case DirectionOut, DirectionIn, DirectionBoth:
+		return true
+	default:
+		return false
+	}
+}
+
+// Edge is a typed, weighted relationship from one person to another.
+//
+// Follows always carry a weight of 1. Engagement edges are weighted by the
+// number of interactions, e.g. how many of the target's tracks the source liked.
+// FirstAt and LastAt bound the interactions in time, when SoundCloud reports it.
+// Reciprocal is not stored, and is only set by callers that check for the reverse edge.
+type Edge struct {
+	SourceID   int64
+	TargetID   int64
+	Kind       EdgeKind
+	Weight     float64
+	FirstAt    *time.Time
+	LastAt     *time.Time
+	UpdatedAt  time.Time
+	Reciprocal bool
+}
+
+// socialEdges is a derived table of every edge in the social layer, reading
+// follows from their own table with a weight of 1. It is valid in every dialect.
+const socialEdges = `(
+	SELECT follower_id AS source_id, followee_id AS target_id, 'follows' AS kind, CAST(1.0 AS double precision) AS weight, CAST(NULL AS timestamptz) AS first_at, CAST(NULL AS timestamptz) AS last_at, created_at AS updated_at FROM follows
+	UNION ALL
+	SELECT source_id, target_id, kind, weight, first_at, last_at, updated_at FROM edges
+) e`
+
+// EdgesRepository is a repository for the typed edges between people.
+type EdgesRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewEdgesRepository creates a new EdgesRepository.
+func NewEdgesRepository(db *sql.DB) *EdgesRepository {
+	return &EdgesRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// UpsertEdges stores engagement edges. When an edge already exists the larger weight is kept,
+// since each ingestion pass only observes a truncated window of a person's activity.
+// Follows are owned by the people repository and are rejected here.
+func (er *EdgesRepository) UpsertEdges(ctx context.Context, edges []Edge) error {
+	return er.upsertEdges(ctx, er.db, edges)
+}
+
+// upsertEdges is UpsertEdges writing through db, which may be a transaction.
+func (er *EdgesRepository) upsertEdges(ctx context.Context, db DBExecer, edges []Edge) error {
+	if len(edges) == 0 {
+		return nil
+	}
+
+	for _, e := range edges {
+		if e.Kind == EdgeKindFollows {
+			return errors.New("follows cannot be upserted as engagement edges")
+		}
+	}
+	if er.dialect == data.DialectSQLite {
+		return er.upsertEdgesSQLite(ctx, db, edges)
+	}
+
+	var (
+		sources = make([]int64, 0, len(edges))
+		targets = make([]int64, 0, len(edges))
+		kinds   = make([]string, 0, len(edges))
+		weights = make([]float64, 0, len(edges))
+	)
+	for _, e := range edges {
+		sources = append(sources, e.SourceID)
+		targets = append(targets, e.TargetID)
+		kinds = append(kinds, string(e.Kind))
+		weights = append(weights, e.Weight)
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO edges (source_id, target_id, kind, weight)
+		SELECT * FROM unnest($1::bigint[], $2::bigint[], $3::text[], $4::double precision[])
+		ON CONFLICT (source_id, target_id, kind) DO UPDATE SET
+			weight = GREATEST(edges.weight, EXCLUDED.weight),
+			updated_at = now();`,
+		pq.Array(sources), pq.Array(targets), pq.Array(kinds), pq.Array(weights),
+	)
+	if err != nil {
+		slog.Error("failed to upsert edges", "error", err)
+	}
+	return err
+}
+
+// upsertEdgesSQLite is UpsertEdges for SQLite, which has no arrays, so the edges are
+// bound as a JSON array instead.
+func (er *EdgesRepository) upsertEdgesSQLite(ctx context.Context, db DBExecer, edges []Edge) error {
+	type edge struct {
+		SourceID int64    `json:"source_id"`
+		TargetID int64    `json:"target_id"`
+		Kind     EdgeKind `json:"kind"`
+		Weight   float64  `json:"weight"`
+	}
+	staged := make([]edge, 0, len(edges))
+	for _, e := range edges {
+		staged = append(staged, edge{SourceID: e.SourceID, TargetID: e.TargetID, Kind: e.Kind, Weight: e.Weight})
+	}
+	b, err := json.Marshal(staged)
+	if err != nil {
+		return err
+	}
+
+	// SQLite only parses an upsert from a SELECT that has a WHERE clause
+	_, err = db.ExecContext(
+		ctx,
+		`INSERT INTO edges (source_id, target_id, kind, weight)
+		SELECT json_extract(value, '$.source_id'), json_extract(value, '$.target_id'), json_extract(value, '$.kind'), json_extract(value, '$.weight')
+		FROM json_each($1) WHERE true
+		ON CONFLICT (source_id, target_id, kind) DO UPDATE SET
+			weight = max(edges.weight, EXCLUDED.weight),
+			updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now');`,
+		string(b),
+	)
+	if err != nil {
+		slog.Error("failed to upsert edges", "error", err)
+	}
+	return err
+}
+
+// FindEdges returns the edges of the given kinds touching a person in the given direction.
+// Follows are read from the follows table and reported with a weight of 1.
+func (er *EdgesRepository) FindEdges(ctx context.Context, personID int64, direction Direction, kinds []EdgeKind) (edges []Edge, err error) {
+	where, err := directionClause(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	inKinds, kindsArg, err := anyOf(er.dialect, "kind", 2, edgeKindStrings(kinds))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := er.db.QueryContext(
+		ctx,
+		`SELECT source_id, target_id, kind, weight, first_at, last_at, updated_at FROM `+socialEdges+` WHERE `+where+` AND `+inKinds+` ORDER BY weight DESC;`,
+		personID, kindsArg,
+	)
+	if err != nil {
+		slog.Error("failed to query edges", "error", err)
+		return nil, err
+	}
+	return scanEdges(rows)
+}
+
+// FindSimilarEdges returns the sounds_like edges touching a person in the given direction.
+func (er *EdgesRepository) FindSimilarEdges(ctx context.Context, personID int64, direction Direction) (edges []Edge, err error) {
+	where, err := directionClause(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := er.db.QueryContext(
+		ctx,
+		`SELECT source_id, target_id, 'sounds_like', weight, CAST(NULL AS timestamptz), CAST(NULL AS timestamptz), updated_at
+		FROM artist_similarities WHERE `+where+` ORDER BY weight DESC;`,
+		personID,
+	)
+	if err != nil {
+		slog.Error("failed to query similar edges", "error", err)
+		return nil, err
+	}
+	return scanEdges(rows)
+}
+
+// FindCoPlaylistEdges returns the co_playlisted edges touching a person in the given direction.
+func (er *EdgesRepository) FindCoPlaylistEdges(ctx context.Context, personID int64, direction Direction) (edges []Edge, err error) {
+	where, err := directionClause(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := er.db.QueryContext(
+		ctx,
+		`SELECT source_id, target_id, 'co_playlisted', weight, CAST(NULL AS timestamptz), CAST(NULL AS timestamptz), updated_at
+		FROM artist_cooccurrences WHERE `+where+` ORDER BY weight DESC;`,
+		personID,
+	)
+	if err != nil {
+		slog.Error("failed to query co-playlist edges", "error", err)
+		return nil, err
+	}
+	return scanEdges(rows)
+}
+
+// directionClause returns the condition selecting edges of the person bound to $1.
+func directionClause(direction Direction) (string, error) {
+	switch direction {
+	case DirectionOut:
+		return "source_id = $1", nil
+	case DirectionIn:
+		return "target_id = $1", nil
+	case DirectionBoth:
+		return "(source_id = $1 OR target_id = $1)", nil
+	default:
+		return "", errors.New("invalid edge direction")
+	}
+}
+
+func scanEdges(rows *sql.Rows) (edges []Edge, err error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var e Edge
+		if err = rows.Scan(&e.SourceID, &e.TargetID, &e.Kind, &e.Weight, &e.FirstAt, &e.LastAt, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+func edgeKindStrings(kinds []EdgeKind) []string {
+	s := make([]string, len(kinds))
+	for i, k := range kinds {
+		s[i] = string(k)
+	}
+	return s
+}
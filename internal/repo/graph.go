@@ -0,0 +1,280 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// GraphRepository reads and writes the social layer as a single graph of people
+// and edges, hiding that follows and engagement edges live in separate tables.
+type GraphRepository struct {
+	db     *sql.DB
+	people *PeopleRepository
+	edges  *EdgesRepository
+}
+
+// NewGraphRepository creates a new GraphRepository.
+func NewGraphRepository(db *sql.DB) *GraphRepository {
+	return &GraphRepository{
+		db:     db,
+		people: NewPeopleRepository(db),
+		edges:  NewEdgesRepository(db),
+	}
+}
+
+// UpsertNodes creates or updates people keyed by username, and returns the stored people.
+// As with PeopleRepository.Upsert, zero-valued fields leave stored values untouched.
+func (gr *GraphRepository) UpsertNodes(ctx context.Context, people []Person) (stored []Person, err error) {
+	if len(people) == 0 {
+		return nil, nil
+	}
+
+	var (
+		usernames   = make([]string, 0, len(people))
+		urns        = make([]string, 0, len(people))
+		names       = make([]string, 0, len(people))
+		imageUrls   = make([]string, 0, len(people))
+		verified    = make([]bool, 0, len(people))
+		plans       = make([]string, 0, len(people))
+		trackCounts = make([]int64, 0, len(people))
+	)
+	seen := make(map[string]bool, len(people))
+	for _, p := range people {
+		// A single upsert cannot touch the same row twice
+		if seen[p.Username] {
+			continue
+		}
+		seen[p.Username] = true
+
+		plan := p.Plan
+		if plan == "" {
+			plan = PlanNone
+		}
+		usernames = append(usernames, p.Username)
+		urns = append(urns, p.Urn)
+		names = append(names, p.Name)
+		imageUrls = append(imageUrls, p.ImageUrl)
+		verified = append(verified, p.Verified)
+		plans = append(plans, string(plan))
+		trackCounts = append(trackCounts, p.TrackCount)
+	}
+
+	if gr.people.dialect == data.DialectSQLite {
+		return gr.upsertNodesSQLite(ctx, usernames, urns, names, imageUrls, verified, plans, trackCounts)
+	}
+
+	return gr.people.queryPersonRows(
+		ctx,
+		`INSERT INTO people (username, urn, name, image_url, verified, plan, track_count)
+		SELECT username, NULLIF(urn, ''), name, image_url, verified, plan, track_count
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::boolean[], $6::text[], $7::bigint[])
+			AS p(username, urn, name, image_url, verified, plan, track_count)
+		ON CONFLICT (username) DO UPDATE SET
+			urn = COALESCE(EXCLUDED.urn, people.urn),
+			name = COALESCE(NULLIF(EXCLUDED.name, ''), people.name),
+			image_url = COALESCE(NULLIF(EXCLUDED.image_url, ''), people.image_url),
+			verified = people.verified OR EXCLUDED.verified,
+			plan = CASE WHEN EXCLUDED.plan = 'None' THEN people.plan ELSE EXCLUDED.plan END,
+			track_count = CASE WHEN EXCLUDED.track_count = 0 THEN people.track_count ELSE EXCLUDED.track_count END
+		RETURNING id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count;`,
+		pq.Array(usernames), pq.Array(urns), pq.Array(names), pq.Array(imageUrls), pq.Array(verified), pq.Array(plans), pq.Array(trackCounts),
+	)
+}
+
+// upsertNodesSQLite is UpsertNodes for SQLite, which has no arrays, so the columns are
+// bound as one JSON array of people instead.
+func (gr *GraphRepository) upsertNodesSQLite(ctx context.Context, usernames, urns, names, imageUrls []string, verified []bool, plans []string, trackCounts []int64) ([]Person, error) {
+	type node struct {
+		Username   string `json:"username"`
+		Urn        string `json:"urn"`
+		Name       string `json:"name"`
+		ImageUrl   string `json:"image_url"`
+		Verified   bool   `json:"verified"`
+		Plan       string `json:"plan"`
+		TrackCount int64  `json:"track_count"`
+	}
+	staged := make([]node, len(usernames))
+	for i := range usernames {
+		staged[i] = node{usernames[i], urns[i], names[i], imageUrls[i], verified[i], plans[i], trackCounts[i]}
+	}
+	b, err := json.Marshal(staged)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only parses an upsert from a SELECT that has a WHERE clause
+	return gr.people.queryPersonRows(
+		ctx,
+		`INSERT INTO people (username, urn, name, image_url, verified, plan, track_count)
+		SELECT json_extract(value, '$.username'), NULLIF(json_extract(value, '$.urn'), ''), json_extract(value, '$.name'),
+			json_extract(value, '$.image_url'), json_extract(value, '$.verified'), json_extract(value, '$.plan'), json_extract(value, '$.track_count')
+		FROM json_each($1) WHERE true
+		ON CONFLICT (username) DO UPDATE SET
+			urn = COALESCE(EXCLUDED.urn, people.urn),
+			name = COALESCE(NULLIF(EXCLUDED.name, ''), people.name),
+			image_url = COALESCE(NULLIF(EXCLUDED.image_url, ''), people.image_url),
+			verified = people.verified OR EXCLUDED.verified,
+			plan = CASE WHEN EXCLUDED.plan = 'None' THEN people.plan ELSE EXCLUDED.plan END,
+			track_count = CASE WHEN EXCLUDED.track_count = 0 THEN people.track_count ELSE EXCLUDED.track_count END
+		RETURNING id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count;`,
+		string(b),
+	)
+}
+
+// UpsertEdges stores social edges between stored people. Follows are written to the
+// follows table, and engagement edges keep the larger weight as in EdgesRepository.
+// Edges of the derived layers are rebuilt by their own repositories and are rejected.
+func (gr *GraphRepository) UpsertEdges(ctx context.Context, edges []Edge) error {
+	var (
+		followers  []int64
+		followees  []int64
+		engagement []Edge
+	)
+	for _, e := range edges {
+		switch e.Kind {
+		case EdgeKindFollows:
+			followers = append(followers, e.SourceID)
+			followees = append(followees, e.TargetID)
+		case EdgeKindLikedTrackOf, EdgeKindRepostedTrackOf, EdgeKindCommentedOn:
+			engagement = append(engagement, e)
+		default:
+			return fmt.Errorf("edge kind %q is not part of the social layer", e.Kind)
+		}
+	}
+
+	if len(edges) == 0 {
+		return nil
+	}
+
+	// Follows and engagement are stored together, or not at all
+	tx, err := gr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if len(followers) > 0 {
+		query, args := `INSERT INTO follows (follower_id, followee_id)
+			SELECT * FROM unnest($1::bigint[], $2::bigint[])
+			ON CONFLICT DO NOTHING;`, []any{pq.Array(followers), pq.Array(followees)}
+		if gr.people.dialect == data.DialectSQLite {
+			pairs := make([][2]int64, len(followers))
+			for i := range followers {
+				pairs[i] = [2]int64{followers[i], followees[i]}
+			}
+			b, err := json.Marshal(pairs)
+			if err != nil {
+				return err
+			}
+			query, args = `INSERT INTO follows (follower_id, followee_id)
+			SELECT json_extract(value, '$[0]'), json_extract(value, '$[1]') FROM json_each($1) WHERE true
+			ON CONFLICT DO NOTHING;`, []any{string(b)}
+		}
+
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			slog.Error("failed to upsert follows", "error", err)
+			return err
+		}
+	}
+
+	if err = gr.edges.upsertEdges(ctx, tx, engagement); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Neighbors returns the edges of the given kinds touching a person in the given direction,
+// heaviest first.
+func (gr *GraphRepository) Neighbors(ctx context.Context, personID int64, direction Direction, kinds []EdgeKind) (edges []Edge, err error) {
+	return gr.edges.FindEdges(ctx, personID, direction, kinds)
+}
+
+// Degree returns how many edges of the given kinds touch a person in the given direction.
+func (gr *GraphRepository) Degree(ctx context.Context, personID int64, direction Direction, kinds []EdgeKind) (degree int, err error) {
+	where, err := directionClause(direction)
+	if err != nil {
+		return 0, err
+	}
+
+	inKinds, kindsArg, err := anyOf(gr.people.dialect, "kind", 2, edgeKindStrings(kinds))
+	if err != nil {
+		return 0, err
+	}
+
+	err = gr.db.QueryRowContext(
+		ctx,
+		`SELECT count(*) FROM `+socialEdges+` WHERE `+where+` AND `+inKinds+`;`,
+		personID, kindsArg,
+	).Scan(&degree)
+	if err != nil {
+		slog.Error("failed to count edges", "error", err)
+	}
+	return degree, err
+}
+
+// Subgraph returns the people with the given ids, and every edge of the given kinds between them.
+func (gr *GraphRepository) Subgraph(ctx context.Context, ids []int64, kinds []EdgeKind) (people []Person, edges []Edge, err error) {
+	people, err = gr.people.FindPeopleByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inSources, idsArg, err := anyOf(gr.people.dialect, "source_id", 1, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	inTargets, _, err := anyOf(gr.people.dialect, "target_id", 1, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	inKinds, kindsArg, err := anyOf(gr.people.dialect, "kind", 2, edgeKindStrings(kinds))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := gr.db.QueryContext(
+		ctx,
+		`SELECT source_id, target_id, kind, weight, first_at, last_at, updated_at FROM `+socialEdges+`
+		WHERE `+inSources+` AND `+inTargets+` AND `+inKinds+`;`,
+		idsArg, kindsArg,
+	)
+	if err != nil {
+		slog.Error("failed to query subgraph edges", "error", err)
+		return nil, nil, err
+	}
+	edges, err = scanEdges(rows)
+	return people, edges, err
+}
+
+// ScanFollows calls fn for every follow created after since, in no particular order.
+// Rows are streamed, so the whole follows table can be scanned without holding it in memory.
+func (gr *GraphRepository) ScanFollows(ctx context.Context, since time.Time, fn func(followerID, followeeID int64, createdAt time.Time) error) error {
+	rows, err := gr.db.QueryContext(ctx, `SELECT follower_id, followee_id, created_at FROM follows WHERE created_at > $1;`, timeArg(gr.people.dialect, since))
+	if err != nil {
+		slog.Error("failed to scan follows", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			followerID, followeeID int64
+			createdAt              time.Time
+		)
+		if err := rows.Scan(&followerID, &followeeID, &createdAt); err != nil {
+			return err
+		}
+		if err := fn(followerID, followeeID, createdAt); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
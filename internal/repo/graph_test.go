@@ -0,0 +1,61 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"lopa.to/sonimulus/internal/graph"
+	"lopa.to/sonimulus/internal/graph/graphtest"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestGraphRepository(t *testing.T) {
+	graphtest.TestGraphStore(t, func(t *testing.T) graph.GraphStore {
+		return repo.NewGraphRepository(newTestDB(t))
+	})
+}
+
+func TestSQLiteGraphRepository(t *testing.T) {
+	graphtest.TestGraphStore(t, func(t *testing.T) graph.GraphStore {
+		return repo.NewGraphRepository(newSQLiteTestDB(t))
+	})
+}
+
+func TestGraphRepositoryUpsertEdgesAtomic(t *testing.T) {
+	forEachDB(t, testGraphRepositoryUpsertEdgesAtomic)
+}
+
+func testGraphRepositoryUpsertEdgesAtomic(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	gr := repo.NewGraphRepository(db)
+
+	people, err := gr.UpsertNodes(ctx, []repo.Person{{Username: "alice"}, {Username: "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := people[0].Id, people[1].Id
+
+	// The engagement edge points at nobody, so the follow stored with it is rolled back
+	err = gr.UpsertEdges(ctx, []repo.Edge{
+		{SourceID: alice, TargetID: bob, Kind: repo.EdgeKindFollows, Weight: 1},
+		{SourceID: alice, TargetID: bob + 1000, Kind: repo.EdgeKindLikedTrackOf, Weight: 2},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an edge to an unknown person")
+	}
+	if degree, err := gr.Degree(ctx, alice, repo.DirectionOut, repo.EdgeKinds); err != nil || degree != 0 {
+		t.Errorf("degree of alice after a failed upsert: got %d, err = %v", degree, err)
+	}
+
+	err = gr.UpsertEdges(ctx, []repo.Edge{
+		{SourceID: alice, TargetID: bob, Kind: repo.EdgeKindFollows, Weight: 1},
+		{SourceID: alice, TargetID: bob, Kind: repo.EdgeKindLikedTrackOf, Weight: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if degree, err := gr.Degree(ctx, alice, repo.DirectionOut, repo.EdgeKinds); err != nil || degree != 2 {
+		t.Errorf("degree of alice: got %d, err = %v", degree, err)
+	}
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KVStore stores values under keys until they expire. Sessions and OAuth states are kept in
+// one, so that the server runs against Redis, Postgres or its own memory alike.
+type KVStore interface {
+	// Set stores a value under a key for ttl, or until deleted if ttl is not positive.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// GetDel gets a value and deletes it at once, so that it is only ever read once.
+	GetDel(ctx context.Context, key string) (value []byte, found bool, err error)
+	Del(ctx context.Context, key string) (found bool, err error)
+}
+
+// RedisKV is a KVStore in Redis, which expires keys itself.
+type RedisKV struct {
+	kv KVStorer
+}
+
+// NewRedisKV creates a new RedisKV.
+func NewRedisKV(kv KVStorer) *RedisKV {
+	return &RedisKV{kv: kv}
+}
+
+func (rk *RedisKV) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return rk.kv.Set(ctx, key, value, max(ttl, 0)).Err()
+}
+
+func (rk *RedisKV) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return redisValue(rk.kv.Get(ctx, key))
+}
+
+func (rk *RedisKV) GetDel(ctx context.Context, key string) ([]byte, bool, error) {
+	return redisValue(rk.kv.GetDel(ctx, key))
+}
+
+func (rk *RedisKV) Del(ctx context.Context, key string) (bool, error) {
+	deleted, err := rk.kv.Del(ctx, key).Result()
+	return deleted > 0, err
+}
+
+func redisValue(cmd *redis.StringCmd) ([]byte, bool, error) {
+	value, err := cmd.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
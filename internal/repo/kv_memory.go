@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryKV is a KVStore in the memory of the process, for development and tests. Its values
+// are lost on restart. Expired values are never read, and are evicted by Run.
+type MemoryKV struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (me memoryEntry) expired(now time.Time) bool {
+	return !me.expiresAt.IsZero() && !now.Before(me.expiresAt)
+}
+
+// NewMemoryKV creates a new MemoryKV.
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{entries: make(map[string]memoryEntry)}
+}
+
+func (mk *MemoryKV) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+
+	entry := memoryEntry{value: slices.Clone(value)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	mk.entries[key] = entry
+	return nil
+}
+
+func (mk *MemoryKV) Get(_ context.Context, key string) ([]byte, bool, error) {
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+
+	value, found := mk.get(key)
+	return value, found, nil
+}
+
+func (mk *MemoryKV) GetDel(_ context.Context, key string) ([]byte, bool, error) {
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+
+	value, found := mk.get(key)
+	delete(mk.entries, key)
+	return value, found, nil
+}
+
+func (mk *MemoryKV) Del(_ context.Context, key string) (bool, error) {
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+
+	_, found := mk.get(key)
+	delete(mk.entries, key)
+	return found, nil
+}
+
+// get returns the value under a key unless it expired. mu must be held.
+func (mk *MemoryKV) get(key string) ([]byte, bool) {
+	entry, ok := mk.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, false
+	}
+	return slices.Clone(entry.value), true
+}
+
+// Evict deletes every expired value, and returns how many it deleted.
+func (mk *MemoryKV) Evict() int {
+	mk.mu.Lock()
+	defer mk.mu.Unlock()
+
+	now := time.Now()
+	evicted := 0
+	for key, entry := range mk.entries {
+		if entry.expired(now) {
+			delete(mk.entries, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Run evicts expired values every interval until ctx is done.
+func (mk *MemoryKV) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mk.Evict()
+		}
+	}
+}
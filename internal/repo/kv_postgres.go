@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// PostgresKV is a KVStore in the kv table. Expired values are never read, and are deleted
+// by Run.
+type PostgresKV struct {
+	db *sql.DB
+}
+
+// NewPostgresKV creates a new PostgresKV.
+func NewPostgresKV(db *sql.DB) *PostgresKV {
+	return &PostgresKV{db: db}
+}
+
+func (pk *PostgresKV) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+	_, err := pk.db.ExecContext(
+		ctx,
+		`INSERT INTO kv (key, value, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at;`,
+		key, value, expiresAt,
+	)
+	return err
+}
+
+func (pk *PostgresKV) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
+	err = pk.db.QueryRowContext(
+		ctx,
+		`SELECT value FROM kv WHERE key = $1 AND (expires_at IS NULL OR expires_at > now());`,
+		key,
+	).Scan(&value)
+	return kvValue(value, err)
+}
+
+func (pk *PostgresKV) GetDel(ctx context.Context, key string) (value []byte, found bool, err error) {
+	err = pk.db.QueryRowContext(
+		ctx,
+		`WITH deleted AS (DELETE FROM kv WHERE key = $1 RETURNING value, expires_at)
+		SELECT value FROM deleted WHERE expires_at IS NULL OR expires_at > now();`,
+		key,
+	).Scan(&value)
+	return kvValue(value, err)
+}
+
+func (pk *PostgresKV) Del(ctx context.Context, key string) (found bool, err error) {
+	res, err := pk.db.ExecContext(ctx, `DELETE FROM kv WHERE key = $1 AND (expires_at IS NULL OR expires_at > now());`, key)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := res.RowsAffected()
+	return deleted > 0, err
+}
+
+// Evict deletes every expired value, and returns how many it deleted.
+func (pk *PostgresKV) Evict(ctx context.Context) (int, error) {
+	res, err := pk.db.ExecContext(ctx, `DELETE FROM kv WHERE expires_at <= now();`)
+	if err != nil {
+		return 0, err
+	}
+	evicted, err := res.RowsAffected()
+	return int(evicted), err
+}
+
+// Run evicts expired values every interval until ctx is done.
+func (pk *PostgresKV) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := pk.Evict(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("Evicting expired values", "error", err)
+			}
+		}
+	}
+}
+
+func kvValue(value []byte, err error) ([]byte, bool, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// SQLiteKV is a KVStore in the kv table of an SQLite database, with expiry times in Unix
+// milliseconds. Expired values are never read, and are deleted by Run.
+type SQLiteKV struct {
+	db *sql.DB
+}
+
+// NewSQLiteKV creates a new SQLiteKV.
+func NewSQLiteKV(db *sql.DB) *SQLiteKV {
+	return &SQLiteKV{db: db}
+}
+
+func (sk *SQLiteKV) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	var expiresAt *int64
+	if ttl > 0 {
+		ms := time.Now().Add(ttl).UnixMilli()
+		expiresAt = &ms
+	}
+	_, err := sk.db.ExecContext(
+		ctx,
+		`INSERT INTO kv (key, value, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at;`,
+		key, value, expiresAt,
+	)
+	return err
+}
+
+func (sk *SQLiteKV) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
+	err = sk.db.QueryRowContext(
+		ctx,
+		`SELECT value FROM kv WHERE key = $1 AND (expires_at IS NULL OR expires_at > $2);`,
+		key, time.Now().UnixMilli(),
+	).Scan(&value)
+	return kvValue(value, err)
+}
+
+func (sk *SQLiteKV) GetDel(ctx context.Context, key string) (value []byte, found bool, err error) {
+	var expiresAt sql.NullInt64
+	err = sk.db.QueryRowContext(
+		ctx,
+		`DELETE FROM kv WHERE key = $1 RETURNING value, expires_at;`,
+		key,
+	).Scan(&value, &expiresAt)
+	if err == nil && expiresAt.Valid && expiresAt.Int64 <= time.Now().UnixMilli() {
+		err = sql.ErrNoRows
+	}
+	return kvValue(value, err)
+}
+
+func (sk *SQLiteKV) Del(ctx context.Context, key string) (found bool, err error) {
+	res, err := sk.db.ExecContext(
+		ctx,
+		`DELETE FROM kv WHERE key = $1 AND (expires_at IS NULL OR expires_at > $2);`,
+		key, time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := res.RowsAffected()
+	return deleted > 0, err
+}
+
+// Evict deletes every expired value, and returns how many it deleted.
+func (sk *SQLiteKV) Evict(ctx context.Context) (int, error) {
+	res, err := sk.db.ExecContext(ctx, `DELETE FROM kv WHERE expires_at <= $1;`, time.Now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	evicted, err := res.RowsAffected()
+	return int(evicted), err
+}
+
+// Run evicts expired values every interval until ctx is done.
+func (sk *SQLiteKV) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := sk.Evict(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("Evicting expired values", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package repo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// testKVStore checks the behavior every KVStore shares.
+func testKVStore(t *testing.T, kv repo.KVStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := kv.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := kv.Set(ctx, "a", []byte("2"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if v, found, err := kv.Get(ctx, "a"); err != nil || !found || string(v) != "2" {
+		t.Errorf("Get = %q, %v, %v, want the value set last", v, found, err)
+	}
+	if _, found, err := kv.Get(ctx, "missing"); err != nil || found {
+		t.Errorf("found = %v, err = %v for a missing key", found, err)
+	}
+
+	if v, found, err := kv.GetDel(ctx, "a"); err != nil || !found || string(v) != "2" {
+		t.Errorf("GetDel = %q, %v, %v", v, found, err)
+	}
+	if _, found, _ := kv.GetDel(ctx, "a"); found {
+		t.Error("read a value twice with GetDel")
+	}
+
+	if err := kv.Set(ctx, "b", []byte("1"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := kv.Del(ctx, "b"); err != nil || !found {
+		t.Errorf("Del: found = %v, err = %v", found, err)
+	}
+	if found, _ := kv.Del(ctx, "b"); found {
+		t.Error("deleted a value twice")
+	}
+
+	if err := kv.Set(ctx, "c", []byte("1"), 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, found, _ := kv.Get(ctx, "c"); found {
+		t.Error("read an expired value")
+	}
+	if _, found, _ := kv.GetDel(ctx, "c"); found {
+		t.Error("read an expired value with GetDel")
+	}
+}
+
+func TestMemoryKV(t *testing.T) {
+	kv := repo.NewMemoryKV()
+	testKVStore(t, kv)
+
+	ctx := context.Background()
+	kv.Set(ctx, "short", []byte("1"), time.Millisecond)
+	kv.Set(ctx, "long", []byte("1"), time.Hour)
+	time.Sleep(5 * time.Millisecond)
+	if evicted := kv.Evict(); evicted != 1 {
+		t.Errorf("evicted %d values, want the expired one", evicted)
+	}
+}
+
+func TestPostgresKV(t *testing.T) {
+	kv := repo.NewPostgresKV(newTestDB(t))
+	testKVStore(t, kv)
+
+	ctx := context.Background()
+	kv.Set(ctx, "short", []byte("1"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if evicted, err := kv.Evict(ctx); err != nil || evicted != 1 {
+		t.Errorf("evicted = %d, err = %v, want the expired value", evicted, err)
+	}
+}
+
+func TestSQLiteKV(t *testing.T) {
+	kv := repo.NewSQLiteKV(newSQLiteTestDB(t))
+	testKVStore(t, kv)
+
+	ctx := context.Background()
+	kv.Set(ctx, "short", []byte("1"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if evicted, err := kv.Evict(ctx); err != nil || evicted != 1 {
+		t.Errorf("evicted = %d, err = %v, want the expired value", evicted, err)
+	}
+}
+
+func TestSessionsAndStates(t *testing.T) {
+	ctx := context.Background()
+	kv := repo.NewMemoryKV()
+	sessions := repo.NewSessionRepository(kv)
+	states := repo.NewStateRepository(kv)
+
+	token := &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}
+	id, err := sessions.CreateSession(ctx, repo.Session{Token: token, User: repo.User{ID: 1, Username: "alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, found, err := sessions.GetSession(ctx, id)
+	if err != nil || !found || session.User.Username != "alice" || session.Token.AccessToken != "access" {
+		t.Errorf("got %+v, found = %v, err = %v", session, found, err)
+	}
+	if found, err := sessions.DeleteSession(ctx, id); err != nil || !found {
+		t.Errorf("found = %v, err = %v", found, err)
+	}
+	if _, found, _ := sessions.GetSession(ctx, id); found {
+		t.Error("found a deleted session")
+	}
+
+	state, err := states.CreateState(ctx, "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, found, err := states.GetVerifier(ctx, state); err != nil || !found || v != "verifier" {
+		t.Errorf("got %q, found = %v, err = %v", v, found, err)
+	}
+	if _, found, _ := states.GetVerifier(ctx, state); found {
+		t.Error("a state was used twice")
+	}
+}
@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// LayoutRun is a force-directed layout of the follow graph.
+type LayoutRun struct {
+	Id         int64
+	Nodes      int
+	Iterations int
+	// Incremental is set when the run only placed people missing from the previous run.
+	Incremental bool
+	CreatedAt   time.Time
+}
+
+// Position is where a person is placed by a layout.
+type Position struct {
+	PersonID int64
+	X        float64
+	Y        float64
+}
+
+// LayoutsRepository is a repository for layout runs and the positions they computed.
+type LayoutsRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewLayoutsRepository creates a new LayoutsRepository.
+func NewLayoutsRepository(db *sql.DB) *LayoutsRepository {
+	return &LayoutsRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// CreateRun stores a run with the position of everyone it placed.
+func (lr *LayoutsRepository) CreateRun(ctx context.Context, run LayoutRun, positions []Position) (LayoutRun, error) {
+	tx, err := lr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return LayoutRun{}, err
+	}
+	defer tx.Rollback()
+
+	run.Nodes = len(positions)
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO layout_runs (nodes, iterations, incremental) VALUES ($1, $2, $3) RETURNING id, created_at;`,
+		run.Nodes, run.Iterations, run.Incremental,
+	).Scan(&run.Id, &run.CreatedAt)
+	if err != nil {
+		slog.Error("failed to create layout run", "error", err)
+		return LayoutRun{}, err
+	}
+
+	stmt, err := copyIn(ctx, tx, lr.dialect, "layout_positions", "run_id", "person_id", "x", "y")
+	if err != nil {
+		slog.Error("failed to start copying positions", "error", err)
+		return LayoutRun{}, err
+	}
+	for _, p := range positions {
+		if _, err = stmt.ExecContext(ctx, run.Id, p.PersonID, p.X, p.Y); err != nil {
+			stmt.Close()
+			slog.Error("failed to copy positions", "error", err)
+			return LayoutRun{}, err
+		}
+	}
+	if err = flushCopy(ctx, lr.dialect, stmt); err != nil {
+		slog.Error("failed to copy positions", "error", err)
+		return LayoutRun{}, err
+	}
+
+	return run, tx.Commit()
+}
+
+// LatestRun returns the most recent layout run.
+func (lr *LayoutsRepository) LatestRun(ctx context.Context) (run LayoutRun, found bool, err error) {
+	err = lr.db.QueryRowContext(
+		ctx,
+		`SELECT id, nodes, iterations, incremental, created_at FROM layout_runs ORDER BY created_at DESC, id DESC LIMIT 1;`,
+	).Scan(&run.Id, &run.Nodes, &run.Iterations, &run.Incremental, &run.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return LayoutRun{}, false, nil
+	}
+	if err != nil {
+		slog.Error("failed to query layout run", "error", err)
+		return LayoutRun{}, false, err
+	}
+	return run, true, nil
+}
+
+// FindPositions returns the position of every person in ids placed by a run, by person id.
+func (lr *LayoutsRepository) FindPositions(ctx context.Context, runID int64, ids []int64) (positions map[int64]Position, err error) {
+	byPerson, arg, err := anyOf(lr.dialect, "person_id", 2, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := lr.db.QueryContext(
+		ctx,
+		`SELECT person_id, x, y FROM layout_positions WHERE run_id = $1 AND `+byPerson+`;`,
+		runID, arg,
+	)
+	if err != nil {
+		slog.Error("failed to query positions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions = make(map[int64]Position)
+	for rows.Next() {
+		var p Position
+		if err = rows.Scan(&p.PersonID, &p.X, &p.Y); err != nil {
+			return nil, err
+		}
+		positions[p.PersonID] = p
+	}
+	return positions, rows.Err()
+}
+
+// ListPositions returns a page of the positions placed by a run, ordered by person id.
+// A limit of 0 returns every position.
+func (lr *LayoutsRepository) ListPositions(ctx context.Context, runID int64, limit, offset int) (positions []Position, err error) {
+	rows, err := lr.db.QueryContext(
+		ctx,
+		`SELECT person_id, x, y FROM layout_positions WHERE run_id = $1 ORDER BY person_id LIMIT $2 OFFSET $3;`,
+		runID, limitArg(lr.dialect, limit), offset,
+	)
+	if err != nil {
+		slog.Error("failed to query positions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Position
+		if err = rows.Scan(&p.PersonID, &p.X, &p.Y); err != nil {
+			return nil, err
+		}
+		positions = append(positions, p)
+	}
+	return positions, rows.Err()
+}
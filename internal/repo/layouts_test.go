@@ -0,0 +1,65 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestLayoutRuns(t *testing.T) {
+	forEachDB(t, testLayoutRuns)
+}
+
+func testLayoutRuns(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	lr := repo.NewLayoutsRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob"} {
+		p, err := pr.Upsert(ctx, repo.Person{Username: handle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+
+	if _, found, err := lr.LatestRun(ctx); err != nil || found {
+		t.Fatalf("before any run: found = %v, err = %v", found, err)
+	}
+
+	if _, err := lr.CreateRun(ctx, repo.LayoutRun{Iterations: 10}, []repo.Position{{PersonID: ids[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	positions := []repo.Position{{PersonID: ids[1], X: -1.5, Y: 3}, {PersonID: ids[0], X: 2, Y: 0.25}}
+	created, err := lr.CreateRun(ctx, repo.LayoutRun{Iterations: 5, Incremental: true}, positions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, found, err := lr.LatestRun(ctx)
+	if err != nil || !found {
+		t.Fatalf("found = %v, err = %v", found, err)
+	}
+	if run.Id != created.Id || run.Nodes != 2 || !run.Incremental {
+		t.Errorf("got %+v, want %+v", run, created)
+	}
+
+	byID, err := lr.FindPositions(ctx, run.Id, []int64{ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byID) != 1 || byID[ids[1]] != positions[0] {
+		t.Errorf("got %v", byID)
+	}
+
+	listed, err := lr.ListPositions(ctx, run.Id, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 2 || listed[0] != positions[1] || listed[1] != positions[0] {
+		t.Errorf("got %v, want positions ordered by person id", listed)
+	}
+}
@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// MetricKey is a centrality metric people can be ranked by.
+type MetricKey string
+
+const (
+	MetricKeyInDegree  MetricKey = "in_degree"
+	MetricKeyOutDegree MetricKey = "out_degree"
+	MetricKeyPageRank  MetricKey = "pagerank"
+	MetricKeyHub       MetricKey = "hub"
+	MetricKeyAuthority MetricKey = "authority"
+)
+
+// Valid reports whether k names a column of person_metrics.
+func (k MetricKey) Valid() bool {
+	switch k {
+	case MetricKeyInDegree, MetricKeyOutDegree, MetricKeyPageRank, MetricKeyHub, MetricKeyAuthority:
+		return true
+	default:
+		return false
+	}
+}
+
+// PersonMetrics are the centrality scores of a person in the follow graph.
+type PersonMetrics struct {
+	PersonID   int64
+	InDegree   int
+	OutDegree  int
+	PageRank   float64
+	Hub        float64
+	Authority  float64
+	ComputedAt time.Time
+}
+
+// MetricsRepository is a repository for precomputed centrality metrics.
+type MetricsRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewMetricsRepository creates a new MetricsRepository.
+func NewMetricsRepository(db *sql.DB) *MetricsRepository {
+	return &MetricsRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// ReplaceMetrics replaces every stored metric with metrics, computed at computedAt.
+// People missing from metrics are left without any.
+func (mr *MetricsRepository) ReplaceMetrics(ctx context.Context, metrics []PersonMetrics, computedAt time.Time) error {
+	tx, err := mr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM person_metrics;`); err != nil {
+		slog.Error("failed to clear person metrics", "error", err)
+		return err
+	}
+
+	stmt, err := copyIn(ctx, tx, mr.dialect, "person_metrics", "person_id", "in_degree", "out_degree", "pagerank", "hub", "authority", "computed_at")
+	if err != nil {
+		slog.Error("failed to start copying person metrics", "error", err)
+		return err
+	}
+	for _, m := range metrics {
+		if _, err = stmt.ExecContext(ctx, m.PersonID, m.InDegree, m.OutDegree, m.PageRank, m.Hub, m.Authority, timeArg(mr.dialect, computedAt)); err != nil {
+			stmt.Close()
+			slog.Error("failed to copy person metrics", "error", err)
+			return err
+		}
+	}
+	if err = flushCopy(ctx, mr.dialect, stmt); err != nil {
+		slog.Error("failed to copy person metrics", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindByPersonIDs returns the metrics of every person in ids that has any, by person id.
+func (mr *MetricsRepository) FindByPersonIDs(ctx context.Context, ids []int64) (metrics map[int64]PersonMetrics, err error) {
+	byPerson, arg, err := anyOf(mr.dialect, "person_id", 1, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := mr.db.QueryContext(
+		ctx,
+		`SELECT person_id, in_degree, out_degree, pagerank, hub, authority, computed_at
+		FROM person_metrics WHERE `+byPerson+`;`,
+		arg,
+	)
+	if err != nil {
+		slog.Error("failed to query person metrics", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	metrics = make(map[int64]PersonMetrics)
+	for rows.Next() {
+		var m PersonMetrics
+		if err = rows.Scan(&m.PersonID, &m.InDegree, &m.OutDegree, &m.PageRank, &m.Hub, &m.Authority, &m.ComputedAt); err != nil {
+			return nil, err
+		}
+		metrics[m.PersonID] = m
+	}
+	return metrics, rows.Err()
+}
+
+// TopPeople returns a page of the ids of the people ranking highest by a metric.
+func (mr *MetricsRepository) TopPeople(ctx context.Context, key MetricKey, limit, offset int) (ids []int64, err error) {
+	if !key.Valid() {
+		return nil, fmt.Errorf("invalid metric %q", key)
+	}
+
+	rows, err := mr.db.QueryContext(
+		ctx,
+		fmt.Sprintf(`SELECT person_id FROM person_metrics ORDER BY %s DESC, person_id LIMIT $1 OFFSET $2;`, key),
+		limit, offset,
+	)
+	if err != nil {
+		slog.Error("failed to rank people", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,70 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestReplaceMetrics(t *testing.T) {
+	forEachDB(t, testReplaceMetrics)
+}
+
+func testReplaceMetrics(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	mr := repo.NewMetricsRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		p, err := pr.Upsert(ctx, repo.Person{Username: handle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+
+	// SQLite keeps milliseconds
+	computedAt := time.Now().Truncate(time.Millisecond)
+	stale := []repo.PersonMetrics{{PersonID: ids[2], PageRank: 0.9}}
+	if err := mr.ReplaceMetrics(ctx, stale, computedAt.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := []repo.PersonMetrics{
+		{PersonID: ids[0], InDegree: 1, PageRank: 0.2, Hub: 0.1, Authority: 0.7},
+		{PersonID: ids[1], OutDegree: 1, PageRank: 0.8, Hub: 0.9, Authority: 0.3},
+	}
+	if err := mr.ReplaceMetrics(ctx, metrics, computedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := mr.FindByPersonIDs(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("got metrics for %d people, want 2", len(found))
+	}
+	if m := found[ids[0]]; m.InDegree != 1 || m.Authority != 0.7 || !m.ComputedAt.Equal(computedAt) {
+		t.Errorf("got %+v", m)
+	}
+	if _, ok := found[ids[2]]; ok {
+		t.Error("stale metrics were not replaced")
+	}
+
+	top, err := mr.TopPeople(ctx, repo.MetricKeyAuthority, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{ids[0], ids[1]}; !slices.Equal(top, want) {
+		t.Errorf("top by authority: got %v, want %v", top, want)
+	}
+	if _, err := mr.TopPeople(ctx, repo.MetricKey("name"), 10, 0); err == nil {
+		t.Error("expected an error for an invalid metric")
+	}
+}
@@ -3,10 +3,16 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 
 	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
 )
 
 type PeopleKey string
@@ -14,8 +20,42 @@ type PeopleKey string
 const (
 	PeopleKeyID       PeopleKey = "id"
 	PeopleKeyUsername PeopleKey = "username"
+	PeopleKeyUrn      PeopleKey = "urn"
+)
+
+// FollowSort is the order in which followings and followers are listed.
+type FollowSort string
+
+const (
+	FollowSortFollowedAt FollowSort = "followed_at"
+	FollowSortUsername   FollowSort = "username"
+	FollowSortName       FollowSort = "name"
+	FollowSortTrackCount FollowSort = "track_count"
+	FollowSortPageRank   FollowSort = "pagerank"
+	FollowSortHub        FollowSort = "hub"
+	FollowSortAuthority  FollowSort = "authority"
 )
 
+// followSortColumns maps each FollowSort to the column it orders by.
+var followSortColumns = map[FollowSort]string{
+	FollowSortFollowedAt: "f.created_at",
+	FollowSortUsername:   "p.username",
+	FollowSortName:       "p.name",
+	FollowSortTrackCount: "p.track_count",
+	FollowSortPageRank:   "COALESCE(m.pagerank, 0)",
+	FollowSortHub:        "COALESCE(m.hub, 0)",
+	FollowSortAuthority:  "COALESCE(m.authority, 0)",
+}
+
+// FollowPage selects a page of followings or followers. The zero value lists the
+// most recently followed first, without a limit.
+type FollowPage struct {
+	Sort       FollowSort
+	Descending bool
+	Limit      int
+	Offset     int
+}
+
 type Plan string
 
 const (
@@ -27,35 +67,255 @@ const (
 type Person struct {
 	Id         int64
 	Username   string
+	Urn        string
 	Name       string
 	ImageUrl   string
 	Verified   bool
 	Plan       Plan
 	TrackCount int64
+	// Profile is only populated by callers that load it from the ProfilesRepository.
+	Profile *Profile
+	// Metrics is only populated by callers that load it from the MetricsRepository.
+	Metrics *PersonMetrics
+	// Community is the id of the person's community in a run of the CommunitiesRepository,
+	// and is only populated by callers that load it from there.
+	Community *int
+	// Position is only populated by callers that load it from the LayoutsRepository.
+	Position *Position
+}
+
+// Follow is a follow from a stored person to a handle that may not be stored yet.
+type Follow struct {
+	FollowerID     int64
+	FolloweeHandle string
 }
 
 type PeopleRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect data.Dialect
 }
 
 func NewPeopleRepository(db *sql.DB) *PeopleRepository {
-	return &PeopleRepository{db: db}
+	return &PeopleRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// FindPersonByIndex retrieves a person by one of their unique keys.
+func (pr *PeopleRepository) FindPersonByIndex(ctx context.Context, key PeopleKey, value string) (person Person, found bool, err error) {
+	if key != PeopleKeyID && key != PeopleKeyUsername && key != PeopleKeyUrn {
+		return person, false, errors.New("invalid people key")
+	}
+	query := fmt.Sprintf("SELECT id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count FROM people WHERE %s = $1;", key)
+	return pr.queryPersonRow(ctx, query, value)
+}
+
+// FindPersonByID retrieves a person by id.
+func (pr *PeopleRepository) FindPersonByID(ctx context.Context, id int64) (person Person, found bool, err error) {
+	return pr.FindPersonByIndex(ctx, PeopleKeyID, strconv.FormatInt(id, 10))
+}
+
+// FindPersonByUsername retrieves a person by their SoundCloud handle.
+func (pr *PeopleRepository) FindPersonByUsername(ctx context.Context, username string) (person Person, found bool, err error) {
+	return pr.FindPersonByIndex(ctx, PeopleKeyUsername, username)
+}
+
+// FindPersonByUrn retrieves a person by their SoundCloud URN.
+func (pr *PeopleRepository) FindPersonByUrn(ctx context.Context, urn string) (person Person, found bool, err error) {
+	return pr.FindPersonByIndex(ctx, PeopleKeyUrn, urn)
+}
+
+// FindPeopleByIDs retrieves every person whose id is in ids. Unknown ids are skipped.
+func (pr *PeopleRepository) FindPeopleByIDs(ctx context.Context, ids []int64) (people []Person, err error) {
+	cond, arg, err := anyOf(pr.dialect, "id", 1, ids)
+	if err != nil {
+		return nil, err
+	}
+	return pr.queryPersonRows(ctx, "SELECT id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count FROM people WHERE "+cond+";", arg)
+}
+
+// FindPeopleByUsernamesOrUrns retrieves every person whose username is in usernames or
+// whose URN is in urns.
+func (pr *PeopleRepository) FindPeopleByUsernamesOrUrns(ctx context.Context, usernames, urns []string) (people []Person, err error) {
+	byUsername, usernamesArg, err := anyOf(pr.dialect, "username", 1, usernames)
+	if err != nil {
+		return nil, err
+	}
+	byUrn, urnsArg, err := anyOf(pr.dialect, "urn", 2, urns)
+	if err != nil {
+		return nil, err
+	}
+	return pr.queryPersonRows(ctx, "SELECT id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count FROM people WHERE "+byUsername+" OR "+byUrn+";", usernamesArg, urnsArg)
+}
+
+// List returns up to limit people with an id greater than afterID, ordered by id.
+func (pr *PeopleRepository) List(ctx context.Context, afterID int64, limit int) (people []Person, err error) {
+	return pr.queryPersonRows(ctx, "SELECT id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count FROM people WHERE id > $1 ORDER BY id LIMIT $2;", afterID, limit)
+}
+
+// ListFollowings returns a page of the people personID follows.
+func (pr *PeopleRepository) ListFollowings(ctx context.Context, personID int64, page FollowPage) (people []Person, err error) {
+	return pr.listFollows(ctx, "f.followee_id", "f.follower_id", personID, page)
+}
+
+// ListFollowers returns a page of the people following personID.
+func (pr *PeopleRepository) ListFollowers(ctx context.Context, personID int64, page FollowPage) (people []Person, err error) {
+	return pr.listFollows(ctx, "f.follower_id", "f.followee_id", personID, page)
+}
+
+// CountFollows returns how many people follow personID, and how many people personID follows.
+func (pr *PeopleRepository) CountFollows(ctx context.Context, personID int64) (followers, followings int64, err error) {
+	err = pr.db.QueryRowContext(
+		ctx,
+		`SELECT
+			(SELECT count(*) FROM follows WHERE followee_id = $1),
+			(SELECT count(*) FROM follows WHERE follower_id = $1);`,
+		personID,
+	).Scan(&followers, &followings)
+	return followers, followings, err
 }
 
-func (pr *PeopleRepository) FindPersonByIndex(ctx context.Context, key PeopleKey, id int64) (person Person, found bool, err error) {
-	return pr.queryPersonRow(ctx, "SELECT id, username, name, image_url, verified, plan, track_count FROM people WHERE $1 = $2;", key, id)
+// listFollows lists the people joined on column from the follows of personID matched on by.
+func (pr *PeopleRepository) listFollows(ctx context.Context, column, by string, personID int64, page FollowPage) (people []Person, err error) {
+	sort := page.Sort
+	if sort == "" {
+		sort, page.Descending = FollowSortFollowedAt, true
+	}
+	order, ok := followSortColumns[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid follow sort %q", sort)
+	}
+	if page.Descending {
+		order += " DESC"
+	}
+
+	// A zero limit lists everyone
+	limit := limitArg(pr.dialect, page.Limit)
+
+	query := fmt.Sprintf(
+		`SELECT p.id, p.username, COALESCE(p.urn, ''), p.name, p.image_url, p.verified, p.plan, p.track_count
+		FROM follows f
+		JOIN people p ON p.id = %s
+		LEFT JOIN person_metrics m ON m.person_id = p.id
+		WHERE %s = $1
+		ORDER BY %s, p.id
+		LIMIT $2 OFFSET $3;`,
+		column, by, order,
+	)
+	return pr.queryPersonRows(ctx, query, personID, limit, page.Offset)
+}
+
+// likeEscaper escapes the LIKE wildcards in a search query.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search returns a page of the people whose handle or display name matches query,
+// best matches first.
+//
+// Names starting with the query rank above names merely resembling it by trigram
+// similarity, which tolerates typos. Among similar matches, verified people and people
+// with more followers rank higher.
+func (pr *PeopleRepository) Search(ctx context.Context, query string, limit, offset int) (people []Person, err error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+	if pr.dialect == data.DialectSQLite {
+		return pr.searchSQLite(ctx, query, limit, offset)
+	}
+
+	return pr.queryPersonRows(
+		ctx,
+		`SELECT id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count FROM (
+			SELECT p.*,
+				(lower(p.username) LIKE $2 || '%' OR lower(p.name) LIKE $2 || '%') AS prefix,
+				GREATEST(similarity(lower(p.username), $1), similarity(lower(p.name), $1)) AS similarity
+			FROM people p
+			WHERE lower(p.username) LIKE $2 || '%' OR lower(p.name) LIKE $2 || '%'
+				OR lower(p.username) % $1 OR lower(p.name) % $1
+		) m
+		CROSS JOIN LATERAL (SELECT count(*) AS followers FROM follows f WHERE f.followee_id = m.id) fc
+		ORDER BY
+			m.prefix::int + m.similarity + CASE WHEN m.verified THEN 0.1 ELSE 0 END + 0.05 * ln(1 + fc.followers) DESC,
+			m.id
+		LIMIT $3 OFFSET $4;`,
+		query, likeEscaper.Replace(query), limit, offset,
+	)
+}
+
+// searchSQLite is Search for SQLite, which has no trigram similarity. Names starting with
+// the query rank above names merely containing it, then verified people and people with
+// more followers rank higher. Case is folded for ASCII letters only.
+func (pr *PeopleRepository) searchSQLite(ctx context.Context, query string, limit, offset int) (people []Person, err error) {
+	return pr.queryPersonRows(
+		ctx,
+		`SELECT id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count FROM (
+			SELECT p.*,
+				(lower(p.username) LIKE $2 || '%' ESCAPE '\' OR lower(p.name) LIKE $2 || '%' ESCAPE '\') AS prefix,
+				(SELECT count(*) FROM follows f WHERE f.followee_id = p.id) AS followers
+			FROM people p
+			WHERE instr(lower(p.username), $1) > 0 OR instr(lower(p.name), $1) > 0
+		) m
+		ORDER BY m.prefix DESC, m.verified DESC, m.followers DESC, m.id
+		LIMIT $3 OFFSET $4;`,
+		query, likeEscaper.Replace(query), limit, offset,
+	)
 }
 
 func (pr *PeopleRepository) Create(ctx context.Context, handle, name, imageUrl string, verified bool, plan Plan, trackCount int64) (person Person, err error) {
+	if pr.dialect == data.DialectSQLite {
+		// SQLite has no functions, so new_person is inlined
+		person, _, err = pr.queryPersonRow(
+			ctx,
+			`INSERT INTO people (username, name, image_url, verified, plan, track_count)
+			VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'None'), $6)
+			ON CONFLICT (username) DO UPDATE SET
+				name = EXCLUDED.name,
+				image_url = EXCLUDED.image_url,
+				verified = EXCLUDED.verified,
+				plan = EXCLUDED.plan,
+				track_count = EXCLUDED.track_count
+			RETURNING id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count;`,
+			handle, name, imageUrl, verified, plan, trackCount,
+		)
+		return person, err
+	}
+
 	person, _, err = pr.queryPersonRow(
 		ctx,
-		"select id, username, name, image_url, verified, plan, track_count from new_person($1, $2, $3, $4, $5, $6);",
+		"select id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count from new_person($1, $2, $3, $4, $5, $6);",
 		handle, name, imageUrl, verified, plan, trackCount,
 	)
 	return person, err
 }
 
+// Upsert creates or updates a person keyed by username, and returns the stored person.
+// Zero-valued fields of p leave previously stored values untouched, since SoundCloud
+// often embeds partial user objects, e.g. the commenter of a comment.
+func (pr *PeopleRepository) Upsert(ctx context.Context, p Person) (person Person, err error) {
+	person, _, err = pr.queryPersonRow(
+		ctx,
+		`INSERT INTO people (username, urn, name, image_url, verified, plan, track_count)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, COALESCE(NULLIF($6, ''), 'None'), $7)
+		ON CONFLICT (username) DO UPDATE SET
+			urn = COALESCE(EXCLUDED.urn, people.urn),
+			name = COALESCE(NULLIF(EXCLUDED.name, ''), people.name),
+			image_url = COALESCE(NULLIF(EXCLUDED.image_url, ''), people.image_url),
+			verified = people.verified OR EXCLUDED.verified,
+			plan = CASE WHEN EXCLUDED.plan = 'None' THEN people.plan ELSE EXCLUDED.plan END,
+			track_count = CASE WHEN EXCLUDED.track_count = 0 THEN people.track_count ELSE EXCLUDED.track_count END
+		RETURNING id, username, COALESCE(urn, ''), name, image_url, verified, plan, track_count;`,
+		p.Username, p.Urn, p.Name, p.ImageUrl, p.Verified, p.Plan, p.TrackCount,
+	)
+	return person, err
+}
+
 func (pr *PeopleRepository) CreateFollows(ctx context.Context, followerId int64, followeeHandles []string) error {
+	if pr.dialect == data.DialectSQLite {
+		follows := make([]Follow, 0, len(followeeHandles))
+		for _, handle := range followeeHandles {
+			follows = append(follows, Follow{FollowerID: followerId, FolloweeHandle: handle})
+		}
+		return pr.BulkCreateFollows(ctx, follows)
+	}
+
 	_, err := pr.db.ExecContext(ctx, `SELECT new_follows($1, $2);`, followerId, pq.Array(followeeHandles))
 	if err != nil {
 		slog.Error("failed to create followee", "error", err)
@@ -63,12 +323,142 @@ func (pr *PeopleRepository) CreateFollows(ctx context.Context, followerId int64,
 	return err
 }
 
+// BulkCreateFollows records many follows at once, creating placeholder people for
+// handles that were not scraped yet.
+//
+// The follows are streamed with COPY into a staging table that only lives for the
+// transaction, then upserted into people and follows with two set-based statements,
+// instead of a new_follows call per follower.
+func (pr *PeopleRepository) BulkCreateFollows(ctx context.Context, follows []Follow) error {
+	if len(follows) == 0 {
+		return nil
+	}
+	if pr.dialect == data.DialectSQLite {
+		return pr.bulkCreateFollowsSQLite(ctx, follows)
+	}
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `CREATE TEMP TABLE follows_staging (follower_id bigint, followee_username text) ON COMMIT DROP;`)
+	if err != nil {
+		slog.Error("failed to create follows staging table", "error", err)
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("follows_staging", "follower_id", "followee_username"))
+	if err != nil {
+		slog.Error("failed to start copying follows", "error", err)
+		return err
+	}
+	for _, f := range follows {
+		if _, err = stmt.ExecContext(ctx, f.FollowerID, f.FolloweeHandle); err != nil {
+			stmt.Close()
+			slog.Error("failed to copy follow", "error", err)
+			return err
+		}
+	}
+	// An empty Exec flushes the buffered rows
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		slog.Error("failed to copy follows", "error", err)
+		return err
+	}
+	if err = stmt.Close(); err != nil {
+		return err
+	}
+
+	// Placeholders are inserted in username order, so concurrent batches lock rows in the same order
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO people (username)
+		SELECT DISTINCT followee_username FROM follows_staging ORDER BY followee_username
+		ON CONFLICT (username) DO NOTHING;`,
+	)
+	if err != nil {
+		slog.Error("failed to create followees", "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO follows (follower_id, followee_id)
+		SELECT DISTINCT s.follower_id, p.id
+		FROM follows_staging s
+		JOIN people p ON p.username = s.followee_username
+		ON CONFLICT DO NOTHING;`,
+	)
+	if err != nil {
+		slog.Error("failed to create follows", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// bulkCreateFollowsSQLite is BulkCreateFollows for SQLite, which has no COPY. The follows
+// are bound as a single JSON array instead of being staged in a table.
+func (pr *PeopleRepository) bulkCreateFollowsSQLite(ctx context.Context, follows []Follow) error {
+	type follow struct {
+		FollowerID int64  `json:"follower_id"`
+		Username   string `json:"username"`
+	}
+	staged := make([]follow, 0, len(follows))
+	for _, f := range follows {
+		staged = append(staged, follow{FollowerID: f.FollowerID, Username: f.FolloweeHandle})
+	}
+	b, err := json.Marshal(staged)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// SQLite only parses an upsert from a SELECT that has a WHERE clause
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO people (username)
+		SELECT DISTINCT json_extract(value, '$.username') FROM json_each($1) WHERE true
+		ON CONFLICT (username) DO NOTHING;`,
+		string(b),
+	)
+	if err != nil {
+		slog.Error("failed to create followees", "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO follows (follower_id, followee_id)
+		SELECT DISTINCT json_extract(s.value, '$.follower_id'), p.id
+		FROM json_each($1) s
+		JOIN people p ON p.username = json_extract(s.value, '$.username')
+		WHERE true
+		ON CONFLICT DO NOTHING;`,
+		string(b),
+	)
+	if err != nil {
+		slog.Error("failed to create follows", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (pr *PeopleRepository) queryPersonRow(ctx context.Context, query string, args ...any) (person Person, found bool, err error) {
 	err = pr.db.QueryRowContext(
 		ctx, query, args...,
 	).Scan(
 		&person.Id,
 		&person.Username,
+		&person.Urn,
 		&person.Name,
 		&person.ImageUrl,
 		&person.Verified,
@@ -83,3 +473,30 @@ func (pr *PeopleRepository) queryPersonRow(ctx context.Context, query string, ar
 	}
 	return person, true, nil
 }
+
+func (pr *PeopleRepository) queryPersonRows(ctx context.Context, query string, args ...any) (people []Person, err error) {
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var person Person
+		err = rows.Scan(
+			&person.Id,
+			&person.Username,
+			&person.Urn,
+			&person.Name,
+			&person.ImageUrl,
+			&person.Verified,
+			&person.Plan,
+			&person.TrackCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		people = append(people, person)
+	}
+	return people, rows.Err()
+}
@@ -0,0 +1,367 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/internal/data"
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func usernames(people []repo.Person) []string {
+	names := make([]string, 0, len(people))
+	for _, p := range people {
+		names = append(names, p.Username)
+	}
+	return names
+}
+
+func TestFindPersonByIndex(t *testing.T) {
+	forEachDB(t, testFindPersonByIndex)
+}
+
+func testFindPersonByIndex(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+
+	created, err := pr.Upsert(ctx, repo.Person{Username: "alice", Urn: "soundcloud:users:1", Name: "Alice", Plan: repo.PlanArtist})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookups := map[string]func() (repo.Person, bool, error){
+		"id":       func() (repo.Person, bool, error) { return pr.FindPersonByID(ctx, created.Id) },
+		"username": func() (repo.Person, bool, error) { return pr.FindPersonByUsername(ctx, "alice") },
+		"urn":      func() (repo.Person, bool, error) { return pr.FindPersonByUrn(ctx, "soundcloud:users:1") },
+	}
+	for name, lookup := range lookups {
+		person, found, err := lookup()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !found {
+			t.Fatalf("%s: person not found", name)
+		}
+		if person.Id != created.Id || person.Name != "Alice" || person.Plan != repo.PlanArtist {
+			t.Errorf("%s: got %+v, want %+v", name, person, created)
+		}
+	}
+
+	if _, found, err := pr.FindPersonByUsername(ctx, "bob"); err != nil || found {
+		t.Errorf("unknown username: found = %v, err = %v", found, err)
+	}
+	if _, _, err := pr.FindPersonByIndex(ctx, repo.PeopleKey("name"), "Alice"); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+func TestFindPeopleByIDs(t *testing.T) {
+	forEachDB(t, testFindPeopleByIDs)
+}
+
+func testFindPeopleByIDs(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		p, err := pr.Create(ctx, handle, handle, "", false, repo.PlanNone, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+
+	people, err := pr.FindPeopleByIDs(ctx, []int64{ids[0], ids[2], -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := usernames(people)
+	slices.Sort(got)
+	if want := []string{"alice", "carol"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindPeopleByUsernamesOrUrns(t *testing.T) {
+	forEachDB(t, testFindPeopleByUsernamesOrUrns)
+}
+
+func testFindPeopleByUsernamesOrUrns(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+
+	for i, handle := range []string{"alice", "bob", "carol"} {
+		if _, err := pr.Upsert(ctx, repo.Person{Username: handle, Urn: fmt.Sprintf("soundcloud:users:%d", i+1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	people, err := pr.FindPeopleByUsernamesOrUrns(ctx, []string{"alice", "dave"}, []string{"soundcloud:users:3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := usernames(people)
+	slices.Sort(got)
+	if want := []string{"alice", "carol"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestListFollows(t *testing.T) {
+	forEachDB(t, testListFollows)
+}
+
+func testListFollows(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+
+	alice, err := pr.Create(ctx, "alice", "Alice", "", false, repo.PlanNone, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Follow one at a time so that follow times are strictly ordered, even in SQLite, which
+	// keeps milliseconds
+	for _, handle := range []string{"carol", "bob", "dave"} {
+		if err := pr.CreateFollows(ctx, alice.Id, []string{handle}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE people SET track_count = length(username) WHERE username <> 'alice';`); err != nil {
+		t.Fatal(err)
+	}
+	// carol has no metrics yet, so she ranks last by PageRank
+	var metrics []repo.PersonMetrics
+	for handle, pageRank := range map[string]float64{"bob": 0.3, "dave": 0.5} {
+		person, _, err := pr.FindPersonByUsername(ctx, handle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		metrics = append(metrics, repo.PersonMetrics{PersonID: person.Id, PageRank: pageRank})
+	}
+	if err := repo.NewMetricsRepository(db).ReplaceMetrics(ctx, metrics, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		page repo.FollowPage
+		want []string
+	}{
+		{"most recent first by default", repo.FollowPage{}, []string{"dave", "bob", "carol"}},
+		{"by username", repo.FollowPage{Sort: repo.FollowSortUsername}, []string{"bob", "carol", "dave"}},
+		{"by track count descending", repo.FollowPage{Sort: repo.FollowSortTrackCount, Descending: true}, []string{"carol", "dave", "bob"}},
+		{"by pagerank descending", repo.FollowPage{Sort: repo.FollowSortPageRank, Descending: true}, []string{"dave", "bob", "carol"}},
+		{"paginated", repo.FollowPage{Sort: repo.FollowSortUsername, Limit: 1, Offset: 1}, []string{"carol"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			people, err := pr.ListFollowings(ctx, alice.Id, tt.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := usernames(people); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	bob, _, err := pr.FindPersonByUsername(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	followers, err := pr.ListFollowers(ctx, bob.Id, repo.FollowPage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usernames(followers); !slices.Equal(got, []string{"alice"}) {
+		t.Errorf("followers of bob: got %v, want [alice]", got)
+	}
+
+	nFollowers, nFollowings, err := pr.CountFollows(ctx, alice.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nFollowers != 0 || nFollowings != 3 {
+		t.Errorf("got %d followers and %d followings, want 0 and 3", nFollowers, nFollowings)
+	}
+
+	if _, err := pr.ListFollowers(ctx, alice.Id, repo.FollowPage{Sort: "bogus"}); err == nil {
+		t.Error("expected an error for an invalid sort")
+	}
+}
+
+func TestBulkCreateFollows(t *testing.T) {
+	forEachDB(t, testBulkCreateFollows)
+}
+
+func testBulkCreateFollows(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+
+	alice, err := pr.Create(ctx, "alice", "Alice", "", false, repo.PlanNone, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := pr.Create(ctx, "bob", "Bob", "", false, repo.PlanNone, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = pr.BulkCreateFollows(ctx, []repo.Follow{
+		{FollowerID: alice.Id, FolloweeHandle: "bob"},
+		{FollowerID: alice.Id, FolloweeHandle: "carol"},
+		{FollowerID: alice.Id, FolloweeHandle: "carol"},
+		{FollowerID: bob.Id, FolloweeHandle: "carol"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	followings, err := pr.ListFollowings(ctx, alice.Id, repo.FollowPage{Sort: repo.FollowSortUsername})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := usernames(followings), []string{"bob", "carol"}; !slices.Equal(got, want) {
+		t.Errorf("followings of alice: got %v, want %v", got, want)
+	}
+
+	carol, found, err := pr.FindPersonByUsername(ctx, "carol")
+	if err != nil || !found {
+		t.Fatalf("placeholder for carol: found = %v, err = %v", found, err)
+	}
+	followers, err := pr.ListFollowers(ctx, carol.Id, repo.FollowPage{Sort: repo.FollowSortUsername})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := usernames(followers), []string{"alice", "bob"}; !slices.Equal(got, want) {
+		t.Errorf("followers of carol: got %v, want %v", got, want)
+	}
+}
+
+const (
+	benchFollowers  = 50
+	benchFollowings = 200
+)
+
+// benchmarkFollows stores benchFollowers people following benchFollowings new handles
+// each per iteration with store, and reports the throughput in edges per second.
+func benchmarkFollows(b *testing.B, store func(ctx context.Context, pr *repo.PeopleRepository, follows map[int64][]string) error) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(newTestDB(b))
+
+	followers := make([]int64, 0, benchFollowers)
+	for i := range benchFollowers {
+		p, err := pr.Create(ctx, fmt.Sprintf("follower-%d", i), "", "", false, repo.PlanNone, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		followers = append(followers, p.Id)
+	}
+
+	b.ResetTimer()
+	for n := range b.N {
+		b.StopTimer()
+		follows := make(map[int64][]string, len(followers))
+		for _, id := range followers {
+			handles := make([]string, 0, benchFollowings)
+			for j := range benchFollowings {
+				// Followers share followees, as in a real crawl
+				handles = append(handles, fmt.Sprintf("followee-%d-%d", n, (int(id)*7+j)%(benchFollowings*2)))
+			}
+			follows[id] = handles
+		}
+		b.StartTimer()
+
+		if err := store(ctx, pr, follows); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*benchFollowers*benchFollowings)/b.Elapsed().Seconds(), "edges/s")
+}
+
+func BenchmarkCreateFollows(b *testing.B) {
+	benchmarkFollows(b, func(ctx context.Context, pr *repo.PeopleRepository, follows map[int64][]string) error {
+		for id, handles := range follows {
+			if err := pr.CreateFollows(ctx, id, handles); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func BenchmarkBulkCreateFollows(b *testing.B) {
+	benchmarkFollows(b, func(ctx context.Context, pr *repo.PeopleRepository, follows map[int64][]string) error {
+		batch := make([]repo.Follow, 0, benchFollowers*benchFollowings)
+		for id, handles := range follows {
+			for _, h := range handles {
+				batch = append(batch, repo.Follow{FollowerID: id, FolloweeHandle: h})
+			}
+		}
+		return pr.BulkCreateFollows(ctx, batch)
+	})
+}
+
+func TestSearch(t *testing.T) {
+	forEachDB(t, testSearch)
+}
+
+func testSearch(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+
+	people := []repo.Person{
+		{Username: "skrillex", Name: "Skrillex", Verified: true},
+		{Username: "skrillexfan", Name: "Fan Account"},
+		{Username: "realskrillex", Name: "Tribute"},
+		{Username: "deadmau5", Name: "deadmau5"},
+		{Username: "some_one", Name: "Someone"},
+	}
+	for _, p := range people {
+		if _, err := pr.Upsert(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		want         []string
+		postgresOnly bool
+	}{
+		{"prefix, verified first, then contained", "Skrillex", []string{"skrillex", "skrillexfan", "realskrillex"}, false},
+		{"display name prefix", "Fan", []string{"skrillexfan"}, false},
+		// SQLite has no trigram similarity, so only Postgres tolerates typos
+		{"typo", "deadmaus", []string{"deadmau5"}, true},
+		{"wildcards are literal", "some_", []string{"some_one"}, false},
+		{"empty", " ", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.postgresOnly && data.DialectOf(db) != data.DialectPostgres {
+				t.Skip("needs trigram similarity")
+			}
+			got, err := pr.Search(ctx, tt.query, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := usernames(got); !slices.Equal(names, tt.want) && !(len(names) == 0 && len(tt.want) == 0) {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	page, err := pr.Search(ctx, "skril", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := usernames(page); !slices.Equal(names, []string{"skrillexfan"}) {
+		t.Errorf("second page: got %v", names)
+	}
+}
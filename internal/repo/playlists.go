@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// Playlist is a playlist curated by a person.
+type Playlist struct {
+	Urn          string
+	OwnerID      int64
+	Title        string
+	PermalinkUrl string
+}
+
+// PlaylistsRepository is a repository for playlists and the artist co-occurrence graph derived from them.
+type PlaylistsRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewPlaylistsRepository creates a new PlaylistsRepository.
+func NewPlaylistsRepository(db *sql.DB) *PlaylistsRepository {
+	return &PlaylistsRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// UpsertPlaylist stores a playlist and replaces its tracks. trackUrns is in playlist order,
+// and every track must already be stored in the tracks table.
+func (pr *PlaylistsRepository) UpsertPlaylist(ctx context.Context, p Playlist, trackUrns []string) error {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO playlists (urn, owner_id, title, permalink_url)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (urn) DO UPDATE SET
+			owner_id = EXCLUDED.owner_id,
+			title = EXCLUDED.title,
+			permalink_url = EXCLUDED.permalink_url,
+			updated_at = `+currentTime(pr.dialect)+`;`,
+		p.Urn, p.OwnerID, p.Title, p.PermalinkUrl,
+	)
+	if err != nil {
+		slog.Error("failed to upsert playlist", "error", err)
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM playlist_tracks WHERE playlist_urn = $1;`, p.Urn); err != nil {
+		slog.Error("failed to clear playlist tracks", "error", err)
+		return err
+	}
+
+	query := `INSERT INTO playlist_tracks (playlist_urn, track_urn, position)
+		SELECT $1, track_urn, position FROM unnest($2::text[]) WITH ORDINALITY AS t(track_urn, position)
+		ON CONFLICT DO NOTHING;`
+	var tracks any = pq.Array(trackUrns)
+	if pr.dialect == data.DialectSQLite {
+		// json_each numbers the array from 0, where WITH ORDINALITY counts from 1. SQLite only
+		// parses an upsert from a SELECT that has a WHERE clause
+		query = `INSERT INTO playlist_tracks (playlist_urn, track_urn, position)
+		SELECT $1, value, key + 1 FROM json_each($2) WHERE true
+		ON CONFLICT DO NOTHING;`
+		if trackUrns == nil {
+			// A nil slice marshals as null, which json_each reads as a single null track
+			trackUrns = []string{}
+		}
+		b, err := json.Marshal(trackUrns)
+		if err != nil {
+			return err
+		}
+		tracks = string(b)
+	}
+
+	_, err = tx.ExecContext(ctx, query, p.Urn, tracks)
+	if err != nil {
+		slog.Error("failed to insert playlist tracks", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RebuildCoOccurrence recomputes the co_playlisted edges between artists from every stored playlist.
+//
+// Two artists are linked when tracks by both appear in the same playlist, weighted by the number
+// of playlists they share. Edges are stored in both directions.
+func (pr *PlaylistsRepository) RebuildCoOccurrence(ctx context.Context) error {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM artist_cooccurrences;`); err != nil {
+		slog.Error("failed to clear artist co-occurrences", "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`WITH playlist_artists AS (
+			SELECT DISTINCT pt.playlist_urn, t.artist_id
+			FROM playlist_tracks pt
+			JOIN tracks t ON t.urn = pt.track_urn
+		)
+		INSERT INTO artist_cooccurrences (source_id, target_id, weight)
+		SELECT a.artist_id, b.artist_id, count(*)
+		FROM playlist_artists a
+		JOIN playlist_artists b ON a.playlist_urn = b.playlist_urn AND a.artist_id <> b.artist_id
+		GROUP BY a.artist_id, b.artist_id;`,
+	)
+	if err != nil {
+		slog.Error("failed to rebuild artist co-occurrences", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
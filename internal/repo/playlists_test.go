@@ -0,0 +1,94 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestPlaylists(t *testing.T) {
+	forEachDB(t, testPlaylists)
+}
+
+func testPlaylists(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	tr := repo.NewTracksRepository(db)
+	pl := repo.NewPlaylistsRepository(db)
+	er := repo.NewEdgesRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob", "carol", "dave"} {
+		p, err := pr.Upsert(ctx, repo.Person{Username: handle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+	alice, bob, carol, dave := ids[0], ids[1], ids[2], ids[3]
+
+	err := tr.UpsertTracks(ctx, []repo.Track{
+		{Urn: "a1", ArtistID: alice},
+		{Urn: "b1", ArtistID: bob},
+		{Urn: "b2", ArtistID: bob},
+		{Urn: "c1", ArtistID: carol},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	playlists := map[string][]string{
+		// Two tracks by bob in one playlist count once
+		"p1": {"b2", "a1", "b1"},
+		"p2": {"a1", "c1", "b1"},
+		"p3": nil,
+	}
+	for urn, tracks := range playlists {
+		if err := pl.UpsertPlaylist(ctx, repo.Playlist{Urn: urn, OwnerID: dave, Title: urn}, tracks); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var first string
+	err = db.QueryRowContext(ctx, `SELECT track_urn FROM playlist_tracks WHERE playlist_urn = 'p1' ORDER BY position LIMIT 1;`).Scan(&first)
+	if err != nil || first != "b2" {
+		t.Errorf("first track of p1: got %q, err = %v", first, err)
+	}
+
+	if err := pl.RebuildCoOccurrence(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertCoPlaylisted(t, er, alice, map[int64]float64{bob: 2, carol: 1})
+	assertCoPlaylisted(t, er, bob, map[int64]float64{alice: 2, carol: 1})
+	assertCoPlaylisted(t, er, dave, map[int64]float64{})
+
+	// Upserting a playlist replaces its tracks
+	if err := pl.UpsertPlaylist(ctx, repo.Playlist{Urn: "p2", OwnerID: dave}, []string{"a1", "b1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pl.RebuildCoOccurrence(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertCoPlaylisted(t, er, alice, map[int64]float64{bob: 2})
+	assertCoPlaylisted(t, er, carol, map[int64]float64{})
+}
+
+// assertCoPlaylisted checks the weights of the co_playlisted edges from a person.
+func assertCoPlaylisted(t *testing.T, er *repo.EdgesRepository, personID int64, want map[int64]float64) {
+	t.Helper()
+
+	edges, err := er.FindCoPlaylistEdges(context.Background(), personID, repo.DirectionOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[int64]float64, len(edges))
+	for _, e := range edges {
+		got[e.TargetID] = e.Weight
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("co_playlisted weights from %d: got %v, want %v", personID, got, want)
+	}
+}
@@ -0,0 +1,266 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// Profile is the public profile information of a person, used to link them to other platforms.
+type Profile struct {
+	PersonID     int64
+	City         string
+	Country      string
+	Description  string
+	Website      string
+	WebsiteTitle string
+	DiscogsName  string
+	Links        []ProfileLink
+}
+
+// ProfileLink is a link from a SoundCloud profile to another platform, e.g. Instagram or Bandcamp.
+type ProfileLink struct {
+	Service  string
+	Url      string
+	Username string
+	Title    string
+}
+
+// ProfileFilter narrows a profile search. Empty fields match every profile.
+type ProfileFilter struct {
+	// Query is matched against the description, website and linked usernames.
+	Query   string
+	City    string
+	Country string
+	// Service only matches profiles linking to the given platform.
+	Service string
+	// Sort ranks matches by a centrality metric, highest first. Matches are ordered by
+	// person id when empty.
+	Sort MetricKey
+}
+
+// ProfilesRepository is a repository for the profiles of people.
+type ProfilesRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewProfilesRepository creates a new ProfilesRepository.
+func NewProfilesRepository(db *sql.DB) *ProfilesRepository {
+	return &ProfilesRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// Upsert stores a profile and replaces its links.
+func (pr *ProfilesRepository) Upsert(ctx context.Context, p Profile) error {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO profiles (person_id, city, country, description, website, website_title, discogs_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (person_id) DO UPDATE SET
+			city = EXCLUDED.city,
+			country = EXCLUDED.country,
+			description = EXCLUDED.description,
+			website = EXCLUDED.website,
+			website_title = EXCLUDED.website_title,
+			discogs_name = EXCLUDED.discogs_name,
+			updated_at = `+currentTime(pr.dialect)+`;`,
+		p.PersonID, p.City, p.Country, p.Description, p.Website, p.WebsiteTitle, p.DiscogsName,
+	)
+	if err != nil {
+		slog.Error("failed to upsert profile", "error", err)
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM profile_links WHERE person_id = $1;`, p.PersonID); err != nil {
+		slog.Error("failed to clear profile links", "error", err)
+		return err
+	}
+
+	if len(p.Links) > 0 {
+		if pr.dialect == data.DialectSQLite {
+			err = insertProfileLinksSQLite(ctx, tx, p)
+		} else {
+			err = insertProfileLinks(ctx, tx, p)
+		}
+		if err != nil {
+			slog.Error("failed to insert profile links", "error", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertProfileLinks(ctx context.Context, tx *sql.Tx, p Profile) error {
+	var (
+		services  = make([]string, 0, len(p.Links))
+		urls      = make([]string, 0, len(p.Links))
+		usernames = make([]string, 0, len(p.Links))
+		titles    = make([]string, 0, len(p.Links))
+	)
+	for _, l := range p.Links {
+		services = append(services, l.Service)
+		urls = append(urls, l.Url)
+		usernames = append(usernames, l.Username)
+		titles = append(titles, l.Title)
+	}
+
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO profile_links (person_id, service, url, username, title)
+		SELECT $1, * FROM unnest($2::text[], $3::text[], $4::text[], $5::text[])
+		ON CONFLICT DO NOTHING;`,
+		p.PersonID, pq.Array(services), pq.Array(urls), pq.Array(usernames), pq.Array(titles),
+	)
+	return err
+}
+
+// insertProfileLinksSQLite is insertProfileLinks for SQLite, which has no arrays, so the
+// links are bound as a JSON array instead.
+func insertProfileLinksSQLite(ctx context.Context, tx *sql.Tx, p Profile) error {
+	type link struct {
+		Service  string `json:"service"`
+		Url      string `json:"url"`
+		Username string `json:"username"`
+		Title    string `json:"title"`
+	}
+	staged := make([]link, 0, len(p.Links))
+	for _, l := range p.Links {
+		staged = append(staged, link{Service: l.Service, Url: l.Url, Username: l.Username, Title: l.Title})
+	}
+	b, err := json.Marshal(staged)
+	if err != nil {
+		return err
+	}
+
+	// SQLite only parses an upsert from a SELECT that has a WHERE clause
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO profile_links (person_id, service, url, username, title)
+		SELECT $1, json_extract(value, '$.service'), json_extract(value, '$.url'), json_extract(value, '$.username'), json_extract(value, '$.title')
+		FROM json_each($2) WHERE true
+		ON CONFLICT DO NOTHING;`,
+		p.PersonID, string(b),
+	)
+	return err
+}
+
+// FindByPersonIDs retrieves the profiles of the given people, keyed by person id.
+// People without a stored profile are absent from the result.
+func (pr *ProfilesRepository) FindByPersonIDs(ctx context.Context, ids []int64) (profiles map[int64]Profile, err error) {
+	byPerson, arg, err := anyOf(pr.dialect, "person_id", 1, ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pr.db.QueryContext(
+		ctx,
+		`SELECT person_id, city, country, description, website, website_title, discogs_name
+		FROM profiles WHERE `+byPerson+`;`,
+		arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles = make(map[int64]Profile, len(ids))
+	for rows.Next() {
+		var p Profile
+		if err = rows.Scan(&p.PersonID, &p.City, &p.Country, &p.Description, &p.Website, &p.WebsiteTitle, &p.DiscogsName); err != nil {
+			return nil, err
+		}
+		profiles[p.PersonID] = p
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	links, err := pr.db.QueryContext(
+		ctx,
+		`SELECT person_id, service, url, username, title FROM profile_links WHERE `+byPerson+` ORDER BY service;`,
+		arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer links.Close()
+
+	for links.Next() {
+		var (
+			id int64
+			l  ProfileLink
+		)
+		if err = links.Scan(&id, &l.Service, &l.Url, &l.Username, &l.Title); err != nil {
+			return nil, err
+		}
+		if p, ok := profiles[id]; ok {
+			p.Links = append(p.Links, l)
+			profiles[id] = p
+		}
+	}
+	return profiles, links.Err()
+}
+
+// Search returns the ids of people whose profile matches the filter, ordered by id.
+func (pr *ProfilesRepository) Search(ctx context.Context, filter ProfileFilter, limit, offset int) (ids []int64, err error) {
+	if limit <= 0 {
+		return nil, errors.New("invalid limit")
+	}
+
+	order := "p.person_id"
+	if filter.Sort != "" {
+		if !filter.Sort.Valid() {
+			return nil, fmt.Errorf("invalid metric %q", filter.Sort)
+		}
+		order = fmt.Sprintf("COALESCE(m.%s, 0) DESC, p.person_id", filter.Sort)
+	}
+
+	query := `SELECT p.person_id FROM profiles p
+		LEFT JOIN person_metrics m ON m.person_id = p.person_id
+		WHERE ($1 = '' OR p.city ILIKE $1 ESCAPE '\')
+		AND ($2 = '' OR p.country ILIKE $2 ESCAPE '\')
+		AND ($3 = '' OR EXISTS (SELECT 1 FROM profile_links l WHERE l.person_id = p.person_id AND l.service ILIKE $3 ESCAPE '\'))
+		AND ($4 = '' OR p.description ILIKE '%' || $4 || '%' ESCAPE '\' OR p.website ILIKE '%' || $4 || '%' ESCAPE '\'
+			OR EXISTS (SELECT 1 FROM profile_links l WHERE l.person_id = p.person_id AND l.username ILIKE '%' || $4 || '%' ESCAPE '\'))
+		ORDER BY ` + order + `
+		LIMIT $5 OFFSET $6;`
+	if pr.dialect == data.DialectSQLite {
+		// SQLite has no ILIKE, but its LIKE already ignores case, for ASCII letters only
+		query = strings.ReplaceAll(query, "ILIKE", "LIKE")
+	}
+
+	// The filters are matched literally, so their LIKE wildcards are escaped
+	rows, err := pr.db.QueryContext(
+		ctx, query,
+		likeEscaper.Replace(filter.City), likeEscaper.Replace(filter.Country), likeEscaper.Replace(filter.Service), likeEscaper.Replace(filter.Query),
+		limit, offset,
+	)
+	if err != nil {
+		slog.Error("failed to search profiles", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,94 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestProfiles(t *testing.T) {
+	forEachDB(t, testProfiles)
+}
+
+func testProfiles(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	profiles := repo.NewProfilesRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		p, err := pr.Upsert(ctx, repo.Person{Username: handle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+
+	stored := []repo.Profile{
+		{PersonID: ids[0], City: "Berlin", Country: "Germany", Description: "Techno DJ", Links: []repo.ProfileLink{
+			{Service: "instagram", Url: "https://instagram.com/alice", Username: "alice.ig"},
+			{Service: "bandcamp", Url: "https://alice.bandcamp.com"},
+		}},
+		{PersonID: ids[1], City: "berlin", Country: "Germany", Website: "https://bob.example"},
+		{PersonID: ids[2], City: "Paris", Country: "France", Links: []repo.ProfileLink{
+			{Service: "instagram", Url: "https://instagram.com/carol", Username: "carol_techno"},
+		}},
+	}
+	for _, p := range stored {
+		if err := profiles.Upsert(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Upserting again replaces the links
+	alice := stored[0]
+	alice.Links = alice.Links[:1]
+	if err := profiles.Upsert(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := profiles.FindByPersonIDs(ctx, []int64{ids[0], ids[1], 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[ids[1]].Website != "https://bob.example" {
+		t.Errorf("got %+v", found)
+	}
+	if links := found[ids[0]].Links; len(links) != 1 || links[0] != alice.Links[0] {
+		t.Errorf("links of alice: got %+v, want %+v", links, alice.Links)
+	}
+
+	metrics := []repo.PersonMetrics{{PersonID: ids[0], PageRank: 0.1}, {PersonID: ids[1], PageRank: 0.5}}
+	if err := repo.NewMetricsRepository(db).ReplaceMetrics(ctx, metrics, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter repo.ProfileFilter
+		want   []int64
+	}{
+		{"everyone", repo.ProfileFilter{}, ids},
+		{"city ignoring case", repo.ProfileFilter{City: "BERLIN"}, ids[:2]},
+		{"service", repo.ProfileFilter{Service: "Instagram"}, []int64{ids[0], ids[2]}},
+		{"query in description or linked username", repo.ProfileFilter{Query: "techno"}, []int64{ids[0], ids[2]}},
+		{"wildcards in city matched literally", repo.ProfileFilter{City: "%"}, nil},
+		{"wildcards in service matched literally", repo.ProfileFilter{Service: "insta_ram"}, nil},
+		{"underscore in query matched literally", repo.ProfileFilter{Query: "_"}, []int64{ids[2]}},
+		{"by pagerank", repo.ProfileFilter{Country: "germany", Sort: repo.MetricKeyPageRank}, []int64{ids[1], ids[0]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := profiles.Search(ctx, tt.filter, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
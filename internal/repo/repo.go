@@ -34,3 +34,8 @@ type KVStorer interface {
 type DBQuerier interface {
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
+
+// DBExecer is implemented by both *sql.DB and *sql.Tx, so that writes can join a transaction.
+type DBExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
@@ -0,0 +1,358 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// ErrSnapshotExists is returned when creating a snapshot under a name already taken.
+var ErrSnapshotExists = errors.New("snapshot already exists")
+
+// Snapshot is a named copy of the people and follows tables.
+type Snapshot struct {
+	Id      int64
+	Name    string
+	People  int
+	Follows int
+	// CommunityRunID is the Louvain run people's communities were copied from, if any.
+	CommunityRunID *int64
+	CreatedAt      time.Time
+}
+
+// SnapshotPerson is a person as copied into a snapshot.
+type SnapshotPerson struct {
+	Id         int64
+	Username   string
+	Urn        string
+	Name       string
+	Followers  int
+	Followings int
+	// Community is the person's community in the snapshot's community run, if any.
+	Community *int
+}
+
+// SnapshotFollow is a follow held by one snapshot, with the handles of both people.
+type SnapshotFollow struct {
+	FollowerID       int64
+	FolloweeID       int64
+	FollowerUsername string
+	FolloweeUsername string
+}
+
+// FollowerGain is how many followers a person had in two snapshots.
+type FollowerGain struct {
+	Person SnapshotPerson
+	// Before is 0 for people missing from the earlier snapshot.
+	Before int
+	After  int
+}
+
+// SnapshotDiffCounts counts what changed between two snapshots.
+type SnapshotDiffCounts struct {
+	AddedPeople    int
+	RemovedPeople  int
+	AddedFollows   int
+	RemovedFollows int
+}
+
+// SnapshotsRepository is a repository for named snapshots of the people graph.
+type SnapshotsRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewSnapshotsRepository creates a new SnapshotsRepository.
+func NewSnapshotsRepository(db *sql.DB) *SnapshotsRepository {
+	return &SnapshotsRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// snapshotPersonColumns selects a SnapshotPerson from snapshot_people aliased as sp.
+const snapshotPersonColumns = `sp.person_id, sp.username, COALESCE(sp.urn, ''), sp.name, sp.followers, sp.followings, sp.community`
+
+// CreateSnapshot copies every person and follow under a name, with people's follower counts
+// and their communities in the latest Louvain run. The copy reads a single consistent view of
+// the tables, however long it takes.
+func (sr *SnapshotsRepository) CreateSnapshot(ctx context.Context, name string) (snapshot Snapshot, err error) {
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead}
+	if sr.dialect == data.DialectSQLite {
+		// SQLite transactions are serializable, and the driver rejects other levels
+		opts = nil
+	}
+	tx, err := sr.db.BeginTx(ctx, opts)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO snapshots (name, community_run_id)
+		VALUES ($1, (SELECT id FROM community_runs WHERE algorithm = $2 ORDER BY created_at DESC, id DESC LIMIT 1))
+		RETURNING id, name, community_run_id, created_at;`,
+		name, CommunityAlgorithmLouvain,
+	).Scan(&snapshot.Id, &snapshot.Name, &snapshot.CommunityRunID, &snapshot.CreatedAt)
+	if data.IsUniqueViolation(err) {
+		return Snapshot{}, ErrSnapshotExists
+	}
+	if err != nil {
+		slog.Error("failed to create snapshot", "error", err)
+		return Snapshot{}, err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO snapshot_follows (snapshot_id, follower_id, followee_id)
+		SELECT $1, follower_id, followee_id FROM follows;`,
+		snapshot.Id,
+	)
+	if err != nil {
+		slog.Error("failed to copy follows into snapshot", "error", err)
+		return Snapshot{}, err
+	}
+	follows, err := res.RowsAffected()
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	res, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO snapshot_people (snapshot_id, person_id, username, urn, name, followers, followings, community)
+		SELECT $1, p.id, p.username, p.urn, p.name, COALESCE(fi.count, 0), COALESCE(fo.count, 0), cm.community_id
+		FROM people p
+		LEFT JOIN (SELECT followee_id, count(*) AS count FROM snapshot_follows WHERE snapshot_id = $1 GROUP BY followee_id) fi
+			ON fi.followee_id = p.id
+		LEFT JOIN (SELECT follower_id, count(*) AS count FROM snapshot_follows WHERE snapshot_id = $1 GROUP BY follower_id) fo
+			ON fo.follower_id = p.id
+		LEFT JOIN community_members cm ON cm.run_id = $2 AND cm.person_id = p.id;`,
+		snapshot.Id, snapshot.CommunityRunID,
+	)
+	if err != nil {
+		slog.Error("failed to copy people into snapshot", "error", err)
+		return Snapshot{}, err
+	}
+	people, err := res.RowsAffected()
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot.People, snapshot.Follows = int(people), int(follows)
+	_, err = tx.ExecContext(ctx, `UPDATE snapshots SET people = $2, follows = $3 WHERE id = $1;`, snapshot.Id, snapshot.People, snapshot.Follows)
+	if err != nil {
+		slog.Error("failed to count snapshot", "error", err)
+		return Snapshot{}, err
+	}
+
+	return snapshot, tx.Commit()
+}
+
+// ListSnapshots returns every snapshot, newest first.
+func (sr *SnapshotsRepository) ListSnapshots(ctx context.Context) (snapshots []Snapshot, err error) {
+	rows, err := sr.db.QueryContext(
+		ctx,
+		`SELECT id, name, people, follows, community_run_id, created_at FROM snapshots ORDER BY created_at DESC, id DESC;`,
+	)
+	if err != nil {
+		slog.Error("failed to query snapshots", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Snapshot
+		if err = rows.Scan(&s.Id, &s.Name, &s.People, &s.Follows, &s.CommunityRunID, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// FindSnapshot returns the snapshot with a name.
+func (sr *SnapshotsRepository) FindSnapshot(ctx context.Context, name string) (s Snapshot, found bool, err error) {
+	err = sr.db.QueryRowContext(
+		ctx,
+		`SELECT id, name, people, follows, community_run_id, created_at FROM snapshots WHERE name = $1;`,
+		name,
+	).Scan(&s.Id, &s.Name, &s.People, &s.Follows, &s.CommunityRunID, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		slog.Error("failed to query snapshot", "error", err)
+		return Snapshot{}, false, err
+	}
+	return s, true, nil
+}
+
+// DeleteSnapshot deletes the snapshot with a name, and everything copied into it.
+func (sr *SnapshotsRepository) DeleteSnapshot(ctx context.Context, name string) (found bool, err error) {
+	res, err := sr.db.ExecContext(ctx, `DELETE FROM snapshots WHERE name = $1;`, name)
+	if err != nil {
+		slog.Error("failed to delete snapshot", "error", err)
+		return false, err
+	}
+	deleted, err := res.RowsAffected()
+	return deleted > 0, err
+}
+
+// CountDiff counts the people and follows added and removed from one snapshot to another.
+func (sr *SnapshotsRepository) CountDiff(ctx context.Context, fromID, toID int64) (counts SnapshotDiffCounts, err error) {
+	err = sr.db.QueryRowContext(
+		ctx,
+		`SELECT
+			(SELECT count(*) FROM (SELECT person_id FROM snapshot_people WHERE snapshot_id = $2
+				EXCEPT SELECT person_id FROM snapshot_people WHERE snapshot_id = $1) d),
+			(SELECT count(*) FROM (SELECT person_id FROM snapshot_people WHERE snapshot_id = $1
+				EXCEPT SELECT person_id FROM snapshot_people WHERE snapshot_id = $2) d),
+			(SELECT count(*) FROM (SELECT follower_id, followee_id FROM snapshot_follows WHERE snapshot_id = $2
+				EXCEPT SELECT follower_id, followee_id FROM snapshot_follows WHERE snapshot_id = $1) d),
+			(SELECT count(*) FROM (SELECT follower_id, followee_id FROM snapshot_follows WHERE snapshot_id = $1
+				EXCEPT SELECT follower_id, followee_id FROM snapshot_follows WHERE snapshot_id = $2) d);`,
+		fromID, toID,
+	).Scan(&counts.AddedPeople, &counts.RemovedPeople, &counts.AddedFollows, &counts.RemovedFollows)
+	if err != nil {
+		slog.Error("failed to count snapshot diff", "error", err)
+	}
+	return counts, err
+}
+
+// PeopleOnlyIn returns up to limit of the people of a snapshot missing from another, most
+// followed first. Swapping the snapshots turns added people into removed people.
+func (sr *SnapshotsRepository) PeopleOnlyIn(ctx context.Context, inID, notInID int64, limit int) (people []SnapshotPerson, err error) {
+	return sr.querySnapshotPeople(
+		ctx,
+		`SELECT `+snapshotPersonColumns+` FROM snapshot_people sp
+		WHERE sp.snapshot_id = $1
+			AND NOT EXISTS (SELECT 1 FROM snapshot_people o WHERE o.snapshot_id = $2 AND o.person_id = sp.person_id)
+		ORDER BY sp.followers DESC, sp.person_id
+		LIMIT $3;`,
+		inID, notInID, limit,
+	)
+}
+
+// FollowsOnlyIn returns up to limit of the follows of a snapshot missing from another, those
+// of the most followed followees first.
+func (sr *SnapshotsRepository) FollowsOnlyIn(ctx context.Context, inID, notInID int64, limit int) (follows []SnapshotFollow, err error) {
+	rows, err := sr.db.QueryContext(
+		ctx,
+		`SELECT f.follower_id, f.followee_id, fr.username, fe.username
+		FROM snapshot_follows f
+		JOIN snapshot_people fr ON fr.snapshot_id = f.snapshot_id AND fr.person_id = f.follower_id
+		JOIN snapshot_people fe ON fe.snapshot_id = f.snapshot_id AND fe.person_id = f.followee_id
+		WHERE f.snapshot_id = $1
+			AND NOT EXISTS (
+				SELECT 1 FROM snapshot_follows o
+				WHERE o.snapshot_id = $2 AND o.follower_id = f.follower_id AND o.followee_id = f.followee_id
+			)
+		ORDER BY fe.followers DESC, f.followee_id, f.follower_id
+		LIMIT $3;`,
+		inID, notInID, limit,
+	)
+	if err != nil {
+		slog.Error("failed to query snapshot follows", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f SnapshotFollow
+		if err = rows.Scan(&f.FollowerID, &f.FolloweeID, &f.FollowerUsername, &f.FolloweeUsername); err != nil {
+			return nil, err
+		}
+		follows = append(follows, f)
+	}
+	return follows, rows.Err()
+}
+
+// FollowerGains returns up to limit of the people who gained the most followers from one
+// snapshot to another, biggest gain first. People who gained none are left out.
+func (sr *SnapshotsRepository) FollowerGains(ctx context.Context, fromID, toID int64, limit int) (gains []FollowerGain, err error) {
+	rows, err := sr.db.QueryContext(
+		ctx,
+		`SELECT `+snapshotPersonColumns+`, COALESCE(b.followers, 0)
+		FROM snapshot_people sp
+		LEFT JOIN snapshot_people b ON b.snapshot_id = $1 AND b.person_id = sp.person_id
+		WHERE sp.snapshot_id = $2 AND sp.followers > COALESCE(b.followers, 0)
+		ORDER BY sp.followers - COALESCE(b.followers, 0) DESC, sp.person_id
+		LIMIT $3;`,
+		fromID, toID, limit,
+	)
+	if err != nil {
+		slog.Error("failed to query follower gains", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g FollowerGain
+		p := &g.Person
+		if err = rows.Scan(&p.Id, &p.Username, &p.Urn, &p.Name, &p.Followers, &p.Followings, &p.Community, &g.Before); err != nil {
+			return nil, err
+		}
+		g.After = p.Followers
+		gains = append(gains, g)
+	}
+	return gains, rows.Err()
+}
+
+// FindSnapshotPeople returns the people of a snapshot whose id is in ids. Unknown ids are skipped.
+func (sr *SnapshotsRepository) FindSnapshotPeople(ctx context.Context, snapshotID int64, ids []int64) (people []SnapshotPerson, err error) {
+	inIDs, idsArg, err := anyOf(sr.dialect, "sp.person_id", 2, ids)
+	if err != nil {
+		return nil, err
+	}
+	return sr.querySnapshotPeople(
+		ctx,
+		`SELECT `+snapshotPersonColumns+` FROM snapshot_people sp WHERE sp.snapshot_id = $1 AND `+inIDs+`;`,
+		snapshotID, idsArg,
+	)
+}
+
+// ListSnapshotMemberships returns the community of every person of a snapshot who had one,
+// by person id.
+func (sr *SnapshotsRepository) ListSnapshotMemberships(ctx context.Context, snapshotID int64) (memberships map[int64]int, err error) {
+	rows, err := sr.db.QueryContext(
+		ctx,
+		`SELECT person_id, community FROM snapshot_people WHERE snapshot_id = $1 AND community IS NOT NULL;`,
+		snapshotID,
+	)
+	if err != nil {
+		slog.Error("failed to query snapshot communities", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships = make(map[int64]int)
+	for rows.Next() {
+		var personID int64
+		var community int
+		if err = rows.Scan(&personID, &community); err != nil {
+			return nil, err
+		}
+		memberships[personID] = community
+	}
+	return memberships, rows.Err()
+}
+
+func (sr *SnapshotsRepository) querySnapshotPeople(ctx context.Context, query string, args ...any) (people []SnapshotPerson, err error) {
+	rows, err := sr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error("failed to query snapshot people", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p SnapshotPerson
+		if err = rows.Scan(&p.Id, &p.Username, &p.Urn, &p.Name, &p.Followers, &p.Followings, &p.Community); err != nil {
+			return nil, err
+		}
+		people = append(people, p)
+	}
+	return people, rows.Err()
+}
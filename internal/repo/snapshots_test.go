@@ -0,0 +1,111 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestSnapshots(t *testing.T) {
+	forEachDB(t, testSnapshots)
+}
+
+func testSnapshots(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	sr := repo.NewSnapshotsRepository(db)
+
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		if _, err := pr.Upsert(ctx, repo.Person{Username: handle}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pr.CreateFollows(ctx, 1, []string{"bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pr.CreateFollows(ctx, 3, []string{"bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := sr.CreateSnapshot(ctx, "before")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.People != 3 || before.Follows != 2 || before.CommunityRunID != nil {
+		t.Errorf("got %+v", before)
+	}
+	if _, err := sr.CreateSnapshot(ctx, "before"); !errors.Is(err, repo.ErrSnapshotExists) {
+		t.Errorf("got %v, want %v", err, repo.ErrSnapshotExists)
+	}
+
+	// dave joins and follows alice, who follows carol, and carol unfollows bob
+	if err := pr.CreateFollows(ctx, 1, []string{"carol"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pr.Upsert(ctx, repo.Person{Username: "dave"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pr.CreateFollows(ctx, 4, []string{"alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM follows WHERE follower_id = 3 AND followee_id = 2;`); err != nil {
+		t.Fatal(err)
+	}
+	after, err := sr.CreateSnapshot(ctx, "after")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := sr.ListSnapshots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 2 || listed[0].Name != "after" || listed[0].People != 4 || listed[0].Follows != 3 {
+		t.Errorf("got %+v, want the newest snapshot first", listed)
+	}
+
+	counts, err := sr.CountDiff(ctx, before.Id, after.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (repo.SnapshotDiffCounts{AddedPeople: 1, AddedFollows: 2, RemovedFollows: 1}); counts != want {
+		t.Errorf("got %+v, want %+v", counts, want)
+	}
+
+	added, err := sr.PeopleOnlyIn(ctx, after.Id, before.Id, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || added[0].Username != "dave" || added[0].Followings != 1 {
+		t.Errorf("got added people %+v, want dave", added)
+	}
+	removed, err := sr.FollowsOnlyIn(ctx, before.Id, after.Id, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].FollowerUsername != "carol" || removed[0].FolloweeUsername != "bob" {
+		t.Errorf("got removed follows %+v, want carol's of bob", removed)
+	}
+
+	gains, err := sr.FollowerGains(ctx, before.Id, after.Id, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gains) != 2 || gains[0].Before != 0 || gains[0].After != 1 || gains[1].Person.Username != "carol" {
+		t.Errorf("got gains %+v, want alice's and carol's", gains)
+	}
+
+	found, err := sr.DeleteSnapshot(ctx, "before")
+	if err != nil || !found {
+		t.Fatalf("found = %v, err = %v", found, err)
+	}
+	if _, found, _ := sr.FindSnapshot(ctx, "before"); found {
+		t.Error("found a deleted snapshot")
+	}
+	if found, _ := sr.DeleteSnapshot(ctx, "before"); found {
+		t.Error("deleted a snapshot twice")
+	}
+}
@@ -2,9 +2,12 @@ package repo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"lopa.to/sonimulus/env"
 	"lopa.to/sonimulus/soundcloud"
@@ -30,3 +33,196 @@ func (scr *SoundCloudRepository) GetMe(ctx context.Context) (*soundcloud.Me, err
 	}
 	return nil, errors.New("unauthorized")
 }
+
+// ResolveUser looks up the SoundCloud user behind a profile handle.
+func (scr *SoundCloudRepository) ResolveUser(ctx context.Context, handle string) (*soundcloud.User, error) {
+	res, err := scr.client.GetResolveWithResponse(ctx, &soundcloud.GetResolveParams{
+		Url: strings.TrimSuffix(scr.env.Soundcloud.URL, "/") + "/" + handle,
+	})
+	if err != nil {
+		slog.Error("Failed to resolve user", "handle", handle, "error", err)
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("resolving %s: unexpected status %d", handle, res.StatusCode())
+	}
+
+	var user soundcloud.User
+	if err := json.Unmarshal(res.Body, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUser returns the SoundCloud user with the given URN.
+func (scr *SoundCloudRepository) GetUser(ctx context.Context, userUrn string) (*soundcloud.User, error) {
+	res, err := scr.client.GetUsersUserUrnWithResponse(ctx, userUrn)
+	if err != nil {
+		slog.Error("Failed to get user", "urn", userUrn, "error", err)
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK || res.ApplicationjsonCharsetUtf8200 == nil {
+		return nil, fmt.Errorf("getting user %s: unexpected status %d", userUrn, res.StatusCode())
+	}
+	return res.ApplicationjsonCharsetUtf8200, nil
+}
+
+// GetWebProfiles returns up to limit external links listed on a user's profile.
+func (scr *SoundCloudRepository) GetWebProfiles(ctx context.Context, userUrn string, limit int) (soundcloud.WebProfiles, error) {
+	res, err := scr.client.GetUsersUserUrnWebProfilesWithResponse(ctx, userUrn, &soundcloud.GetUsersUserUrnWebProfilesParams{
+		Limit: &limit,
+	})
+	if err != nil {
+		slog.Error("Failed to get web profiles", "urn", userUrn, "error", err)
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK || res.ApplicationjsonCharsetUtf8200 == nil {
+		return nil, fmt.Errorf("getting web profiles of %s: unexpected status %d", userUrn, res.StatusCode())
+	}
+	return *res.ApplicationjsonCharsetUtf8200, nil
+}
+
+// GetUserTracks returns up to limit tracks uploaded by a user.
+func (scr *SoundCloudRepository) GetUserTracks(ctx context.Context, userUrn string, limit int) ([]soundcloud.Track, error) {
+	res, err := scr.client.GetUsersUserUrnTracksWithResponse(ctx, userUrn, &soundcloud.GetUsersUserUrnTracksParams{
+		Limit:              &limit,
+		LinkedPartitioning: ptr(true),
+	})
+	if err != nil {
+		slog.Error("Failed to get user tracks", "urn", userUrn, "error", err)
+		return nil, err
+	}
+	return decodeTracks(res.StatusCode(), res.Body)
+}
+
+// GetUserLikedTracks returns up to limit tracks liked by a user.
+func (scr *SoundCloudRepository) GetUserLikedTracks(ctx context.Context, userUrn string, limit int) ([]soundcloud.Track, error) {
+	res, err := scr.client.GetUsersUserUrnLikesTracksWithResponse(ctx, userUrn, &soundcloud.GetUsersUserUrnLikesTracksParams{
+		Limit:              &limit,
+		LinkedPartitioning: ptr(true),
+	})
+	if err != nil {
+		slog.Error("Failed to get user likes", "urn", userUrn, "error", err)
+		return nil, err
+	}
+	return decodeTracks(res.StatusCode(), res.Body)
+}
+
+// GetTrackFavoriters returns up to limit users who liked a track.
+func (scr *SoundCloudRepository) GetTrackFavoriters(ctx context.Context, trackUrn string, limit int) ([]soundcloud.User, error) {
+	res, err := scr.client.GetTracksTrackUrnFavoritersWithResponse(ctx, trackUrn, &soundcloud.GetTracksTrackUrnFavoritersParams{
+		Limit:              &limit,
+		LinkedPartitioning: ptr(true),
+	})
+	if err != nil {
+		slog.Error("Failed to get track favoriters", "urn", trackUrn, "error", err)
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK || res.ApplicationjsonCharsetUtf8200 == nil {
+		return nil, fmt.Errorf("getting favoriters of %s: unexpected status %d", trackUrn, res.StatusCode())
+	}
+	return deref(res.ApplicationjsonCharsetUtf8200.Collection), nil
+}
+
+// GetTrackReposters returns up to limit users who reposted a track.
+func (scr *SoundCloudRepository) GetTrackReposters(ctx context.Context, trackUrn string, limit int) ([]soundcloud.User, error) {
+	res, err := scr.client.GetTracksTrackUrnRepostersWithResponse(ctx, trackUrn, &soundcloud.GetTracksTrackUrnRepostersParams{
+		Limit: &limit,
+	})
+	if err != nil {
+		slog.Error("Failed to get track reposters", "urn", trackUrn, "error", err)
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK || res.ApplicationjsonCharsetUtf8200 == nil {
+		return nil, fmt.Errorf("getting reposters of %s: unexpected status %d", trackUrn, res.StatusCode())
+	}
+	return deref(res.ApplicationjsonCharsetUtf8200.Collection), nil
+}
+
+// GetTrackComments returns up to limit comments left on a track.
+func (scr *SoundCloudRepository) GetTrackComments(ctx context.Context, trackUrn string, limit int) ([]soundcloud.Comment, error) {
+	res, err := scr.client.GetTracksTrackUrnCommentsWithResponse(ctx, trackUrn, &soundcloud.GetTracksTrackUrnCommentsParams{
+		Limit:              &limit,
+		LinkedPartitioning: ptr(true),
+	})
+	if err != nil {
+		slog.Error("Failed to get track comments", "urn", trackUrn, "error", err)
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK || res.ApplicationjsonCharsetUtf8200 == nil {
+		return nil, fmt.Errorf("getting comments of %s: unexpected status %d", trackUrn, res.StatusCode())
+	}
+	return deref(res.ApplicationjsonCharsetUtf8200.Collection), nil
+}
+
+// GetRelatedTracks returns up to limit tracks SoundCloud recommends as related to a track, most related first.
+func (scr *SoundCloudRepository) GetRelatedTracks(ctx context.Context, trackUrn string, limit int) ([]soundcloud.Track, error) {
+	res, err := scr.client.GetTracksTrackUrnRelatedWithResponse(ctx, trackUrn, &soundcloud.GetTracksTrackUrnRelatedParams{
+		Limit:              &limit,
+		LinkedPartitioning: ptr(true),
+	})
+	if err != nil {
+		slog.Error("Failed to get related tracks", "urn", trackUrn, "error", err)
+		return nil, err
+	}
+	return decodeTracks(res.StatusCode(), res.Body)
+}
+
+// GetUserPlaylists returns up to limit playlists created by a user, without their tracks.
+func (scr *SoundCloudRepository) GetUserPlaylists(ctx context.Context, userUrn string, limit int) ([]soundcloud.Playlist, error) {
+	res, err := scr.client.GetUsersUserUrnPlaylistsWithResponse(ctx, userUrn, &soundcloud.GetUsersUserUrnPlaylistsParams{
+		ShowTracks:         ptr(false),
+		Limit:              &limit,
+		LinkedPartitioning: ptr(true),
+	})
+	if err != nil {
+		slog.Error("Failed to get user playlists", "urn", userUrn, "error", err)
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("getting playlists of %s: unexpected status %d", userUrn, res.StatusCode())
+	}
+
+	var playlists soundcloud.Playlists
+	if err := json.Unmarshal(res.Body, &playlists); err != nil {
+		return nil, err
+	}
+	return deref(playlists.Collection), nil
+}
+
+// GetPlaylistTracks returns the tracks of a playlist, in playlist order.
+func (scr *SoundCloudRepository) GetPlaylistTracks(ctx context.Context, playlistUrn string) ([]soundcloud.Track, error) {
+	res, err := scr.client.GetPlaylistsPlaylistUrnTracksWithResponse(ctx, playlistUrn, &soundcloud.GetPlaylistsPlaylistUrnTracksParams{
+		LinkedPartitioning: ptr(true),
+	})
+	if err != nil {
+		slog.Error("Failed to get playlist tracks", "urn", playlistUrn, "error", err)
+		return nil, err
+	}
+	return decodeTracks(res.StatusCode(), res.Body)
+}
+
+// decodeTracks decodes a paginated track collection. The generated client leaves
+// these responses as an opaque union, so the body is decoded directly.
+func decodeTracks(status int, body []byte) ([]soundcloud.Track, error) {
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("getting tracks: unexpected status %d", status)
+	}
+	var tracks soundcloud.Tracks
+	if err := json.Unmarshal(body, &tracks); err != nil {
+		return nil, err
+	}
+	return deref(tracks.Collection), nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,205 @@
+package repo_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestSQLitePeople(t *testing.T) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(newSQLiteTestDB(t))
+
+	alice, err := pr.Create(ctx, "alice", "Alice", "", true, repo.PlanArtist, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := pr.Create(ctx, "alice", "Alice A.", "", true, "", 4); err != nil || again.Id != alice.Id || again.Name != "Alice A." || again.Plan != repo.PlanNone {
+		t.Errorf("recreating alice: got %+v, err = %v", again, err)
+	}
+	if _, err := pr.Upsert(ctx, repo.Person{Username: "bob", Urn: "soundcloud:users:2", Name: "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pr.CreateFollows(ctx, alice.Id, []string{"bob", "carol", "carol"}); err != nil {
+		t.Fatal(err)
+	}
+	bob, _, err := pr.FindPersonByUrn(ctx, "soundcloud:users:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pr.BulkCreateFollows(ctx, []repo.Follow{
+		{FollowerID: bob.Id, FolloweeHandle: "carol"},
+		{FollowerID: bob.Id, FolloweeHandle: "dave"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	followings, err := pr.ListFollowings(ctx, alice.Id, repo.FollowPage{Sort: repo.FollowSortUsername})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := usernames(followings), []string{"bob", "carol"}; !slices.Equal(got, want) {
+		t.Errorf("followings of alice: got %v, want %v", got, want)
+	}
+
+	carol, found, err := pr.FindPersonByUsername(ctx, "carol")
+	if err != nil || !found {
+		t.Fatalf("placeholder for carol: found = %v, err = %v", found, err)
+	}
+	followers, err := pr.ListFollowers(ctx, carol.Id, repo.FollowPage{Limit: 1})
+	if err != nil || len(followers) != 1 {
+		t.Errorf("first follower of carol: got %v, err = %v", usernames(followers), err)
+	}
+	if followers, followings, err := pr.CountFollows(ctx, carol.Id); err != nil || followers != 2 || followings != 0 {
+		t.Errorf("CountFollows = %d, %d, %v, want 2, 0", followers, followings, err)
+	}
+
+	people, err := pr.FindPeopleByIDs(ctx, []int64{alice.Id, carol.Id, 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(people); got != 2 {
+		t.Errorf("FindPeopleByIDs found %d people, want 2", got)
+	}
+	people, err = pr.FindPeopleByUsernamesOrUrns(ctx, []string{"dave"}, []string{"soundcloud:users:2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := usernames(people), []string{"bob", "dave"}; len(got) != 2 || !slices.Contains(got, want[0]) || !slices.Contains(got, want[1]) {
+		t.Errorf("FindPeopleByUsernamesOrUrns: got %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"ALI", []string{"alice"}},
+		// Prefixes rank first, then people with more followers
+		{"a", []string{"alice", "carol", "dave"}},
+		{"_", nil},
+	}
+	for _, tt := range tests {
+		got, err := pr.Search(ctx, tt.query, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := usernames(got); !slices.Equal(names, tt.want) && !(len(names) == 0 && len(tt.want) == 0) {
+			t.Errorf("Search(%q): got %v, want %v", tt.query, names, tt.want)
+		}
+	}
+}
+
+func TestSQLiteScanFollows(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	pr := repo.NewPeopleRepository(db)
+	gr := repo.NewGraphRepository(db)
+
+	alice, err := pr.Create(ctx, "alice", "", "", false, repo.PlanNone, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now().Add(-time.Second)
+	if err := pr.CreateFollows(ctx, alice.Id, []string{"bob", "carol"}); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(since time.Time) int {
+		n := 0
+		err := gr.ScanFollows(ctx, since, func(followerID, followeeID int64, createdAt time.Time) error {
+			if followerID != alice.Id || createdAt.Before(before) {
+				t.Errorf("scanned follow %d -> %d at %v", followerID, followeeID, createdAt)
+			}
+			n++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count(time.Time{}); n != 2 {
+		t.Errorf("scanned %d follows, want 2", n)
+	}
+	if n := count(time.Now().Add(time.Minute)); n != 0 {
+		t.Errorf("scanned %d follows created in the future", n)
+	}
+}
+
+// TestSQLiteEdges checks the layers the server reads besides the social one.
+func TestSQLiteEdges(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	pr := repo.NewPeopleRepository(db)
+	er := repo.NewEdgesRepository(db)
+
+	alice, err := pr.Create(ctx, "alice", "", "", false, repo.PlanNone, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := pr.Create(ctx, "bob", "", "", false, repo.PlanNone, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if edges, err := er.FindSimilarEdges(ctx, alice.Id, repo.DirectionBoth); err != nil || len(edges) != 0 {
+		t.Errorf("similar edges before any ingestion: got %v, err = %v", edges, err)
+	}
+
+	_, err = db.ExecContext(ctx, `INSERT INTO artist_similarities (source_id, target_id, weight) VALUES ($1, $2, 0.5);
+		INSERT INTO artist_cooccurrences (source_id, target_id, weight) VALUES ($2, $1, 3);`, alice.Id, bob.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	similar, err := er.FindSimilarEdges(ctx, bob.Id, repo.DirectionIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 1 || similar[0].Kind != repo.EdgeKindSoundsLike || similar[0].Weight != 0.5 || similar[0].FirstAt != nil || similar[0].UpdatedAt.IsZero() {
+		t.Errorf("similar edges of bob: got %+v", similar)
+	}
+	coPlaylisted, err := er.FindCoPlaylistEdges(ctx, alice.Id, repo.DirectionBoth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coPlaylisted) != 1 || coPlaylisted[0].Kind != repo.EdgeKindCoPlaylisted || coPlaylisted[0].SourceID != bob.Id {
+		t.Errorf("co-playlist edges of alice: got %+v", coPlaylisted)
+	}
+}
+
+// TestSQLiteAnalysis checks that a database the analysis commands never wrote to answers
+// with no profiles and analysis.
+func TestSQLiteAnalysis(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteTestDB(t)
+	ids := []int64{1, 2}
+
+	if profiles, err := repo.NewProfilesRepository(db).FindByPersonIDs(ctx, ids); err != nil || len(profiles) != 0 {
+		t.Errorf("profiles = %v, err = %v", profiles, err)
+	}
+	if metrics, err := repo.NewMetricsRepository(db).FindByPersonIDs(ctx, ids); err != nil || len(metrics) != 0 {
+		t.Errorf("metrics = %v, err = %v", metrics, err)
+	}
+
+	cr := repo.NewCommunitiesRepository(db)
+	if _, found, err := cr.LatestRun(ctx, repo.CommunityAlgorithmLouvain); err != nil || found {
+		t.Errorf("latest community run: found = %v, err = %v", found, err)
+	}
+	if memberships, err := cr.FindMemberships(ctx, 1, ids); err != nil || len(memberships) != 0 {
+		t.Errorf("memberships = %v, err = %v", memberships, err)
+	}
+
+	lr := repo.NewLayoutsRepository(db)
+	if _, found, err := lr.LatestRun(ctx); err != nil || found {
+		t.Errorf("latest layout run: found = %v, err = %v", found, err)
+	}
+	if positions, err := lr.FindPositions(ctx, 1, ids); err != nil || len(positions) != 0 {
+		t.Errorf("positions = %v, err = %v", positions, err)
+	}
+}
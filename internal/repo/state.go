@@ -3,24 +3,21 @@ package repo
 import (
 	"context"
 	"crypto/rand"
-	"errors"
 	"log/slog"
 	"time"
-
-	"github.com/redis/go-redis/v9"
 )
 
 type StateRepository struct {
-	kv KVStorer
+	kv KVStore
 }
 
-func NewStateRepository(kv KVStorer) *StateRepository {
+func NewStateRepository(kv KVStore) *StateRepository {
 	return &StateRepository{kv: kv}
 }
 
 func (sr *StateRepository) CreateState(ctx context.Context, verifier string) (state string, err error) {
 	state = rand.Text()
-	err = sr.kv.Set(ctx, "state:"+state, verifier, 10*time.Minute).Err()
+	err = sr.kv.Set(ctx, "state:"+state, []byte(verifier), 10*time.Minute)
 	if err != nil {
 		slog.Error("Error creating state", "error", err)
 		return "", err
@@ -29,12 +26,9 @@ func (sr *StateRepository) CreateState(ctx context.Context, verifier string) (st
 }
 
 func (sr *StateRepository) GetVerifier(ctx context.Context, state string) (verifier string, found bool, err error) {
-	verifier, err = sr.kv.GetDel(ctx, "state:"+state).Result()
-	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			return "", false, nil
-		}
+	value, found, err := sr.kv.GetDel(ctx, "state:"+state)
+	if err != nil || !found {
 		return "", false, err
 	}
-	return verifier, true, nil
+	return string(value), true, nil
 }
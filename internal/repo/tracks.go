@@ -0,0 +1,196 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/lib/pq"
+
+	"lopa.to/sonimulus/internal/data"
+)
+
+// Track is a track uploaded by a person.
+type Track struct {
+	Urn          string
+	ArtistID     int64
+	Title        string
+	Genre        string
+	PermalinkUrl string
+	ArtworkUrl   string
+}
+
+// TracksRepository is a repository for tracks and the similarity graph between them.
+//
+// The similarity graph is kept apart from the social graph: tracks are linked by
+// SoundCloud's related-track recommendations, and rolled up into sounds_like edges
+// between the artists who uploaded them.
+type TracksRepository struct {
+	db      *sql.DB
+	dialect data.Dialect
+}
+
+// NewTracksRepository creates a new TracksRepository.
+func NewTracksRepository(db *sql.DB) *TracksRepository {
+	return &TracksRepository{db: db, dialect: data.DialectOf(db)}
+}
+
+// UpsertTracks creates or updates tracks keyed by URN.
+func (tr *TracksRepository) UpsertTracks(ctx context.Context, tracks []Track) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+
+	var (
+		urns      = make([]string, 0, len(tracks))
+		artists   = make([]int64, 0, len(tracks))
+		titles    = make([]string, 0, len(tracks))
+		genres    = make([]string, 0, len(tracks))
+		permalink = make([]string, 0, len(tracks))
+		artwork   = make([]string, 0, len(tracks))
+	)
+	seen := make(map[string]bool, len(tracks))
+	for _, t := range tracks {
+		// A single upsert cannot touch the same row twice
+		if seen[t.Urn] {
+			continue
+		}
+		seen[t.Urn] = true
+
+		urns = append(urns, t.Urn)
+		artists = append(artists, t.ArtistID)
+		titles = append(titles, t.Title)
+		genres = append(genres, t.Genre)
+		permalink = append(permalink, t.PermalinkUrl)
+		artwork = append(artwork, t.ArtworkUrl)
+	}
+	if tr.dialect == data.DialectSQLite {
+		return tr.upsertTracksSQLite(ctx, urns, artists, titles, genres, permalink, artwork)
+	}
+
+	_, err := tr.db.ExecContext(
+		ctx,
+		`INSERT INTO tracks (urn, artist_id, title, genre, permalink_url, artwork_url)
+		SELECT * FROM unnest($1::text[], $2::bigint[], $3::text[], $4::text[], $5::text[], $6::text[])
+		ON CONFLICT (urn) DO UPDATE SET
+			artist_id = EXCLUDED.artist_id,
+			title = EXCLUDED.title,
+			genre = EXCLUDED.genre,
+			permalink_url = EXCLUDED.permalink_url,
+			artwork_url = EXCLUDED.artwork_url;`,
+		pq.Array(urns), pq.Array(artists), pq.Array(titles), pq.Array(genres), pq.Array(permalink), pq.Array(artwork),
+	)
+	if err != nil {
+		slog.Error("failed to upsert tracks", "error", err)
+	}
+	return err
+}
+
+// upsertTracksSQLite is UpsertTracks for SQLite, which has no arrays, so the columns are
+// bound as JSON arrays instead.
+func (tr *TracksRepository) upsertTracksSQLite(ctx context.Context, urns []string, artists []int64, titles, genres, permalink, artwork []string) error {
+	columns := make([]string, 0, 6)
+	for _, column := range []any{urns, artists, titles, genres, permalink, artwork} {
+		b, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		columns = append(columns, string(b))
+	}
+
+	// SQLite only parses an upsert from a SELECT that has a WHERE clause
+	_, err := tr.db.ExecContext(
+		ctx,
+		`INSERT INTO tracks (urn, artist_id, title, genre, permalink_url, artwork_url)
+		SELECT u.value, json_extract($2, '$[' || u.key || ']'), json_extract($3, '$[' || u.key || ']'),
+			json_extract($4, '$[' || u.key || ']'), json_extract($5, '$[' || u.key || ']'), json_extract($6, '$[' || u.key || ']')
+		FROM json_each($1) u WHERE true
+		ON CONFLICT (urn) DO UPDATE SET
+			artist_id = EXCLUDED.artist_id,
+			title = EXCLUDED.title,
+			genre = EXCLUDED.genre,
+			permalink_url = EXCLUDED.permalink_url,
+			artwork_url = EXCLUDED.artwork_url;`,
+		columns[0], columns[1], columns[2], columns[3], columns[4], columns[5],
+	)
+	if err != nil {
+		slog.Error("failed to upsert tracks", "error", err)
+	}
+	return err
+}
+
+// ReplaceRelated replaces the tracks related to trackUrn. relatedUrns is ordered
+// by SoundCloud's ranking, most similar first.
+func (tr *TracksRepository) ReplaceRelated(ctx context.Context, trackUrn string, relatedUrns []string) error {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM related_tracks WHERE track_urn = $1;`, trackUrn); err != nil {
+		slog.Error("failed to clear related tracks", "error", err)
+		return err
+	}
+
+	query := `INSERT INTO related_tracks (track_urn, related_urn, rank)
+		SELECT $1, related_urn, rank FROM unnest($2::text[]) WITH ORDINALITY AS r(related_urn, rank)
+		WHERE related_urn <> $1
+		ON CONFLICT DO NOTHING;`
+	var related any = pq.Array(relatedUrns)
+	if tr.dialect == data.DialectSQLite {
+		// json_each numbers the array from 0, where WITH ORDINALITY ranks from 1
+		query = `INSERT INTO related_tracks (track_urn, related_urn, rank)
+		SELECT $1, value, key + 1 FROM json_each($2)
+		WHERE value <> $1
+		ON CONFLICT DO NOTHING;`
+		b, err := json.Marshal(relatedUrns)
+		if err != nil {
+			return err
+		}
+		related = string(b)
+	}
+
+	_, err = tx.ExecContext(ctx, query, trackUrn, related)
+	if err != nil {
+		slog.Error("failed to insert related tracks", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RollUpSimilarity rebuilds the sounds_like edges from an artist, weighting each
+// edge by how many of the artist's tracks list at least one of the target's tracks
+// as related.
+func (tr *TracksRepository) RollUpSimilarity(ctx context.Context, artistID int64) error {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM artist_similarities WHERE source_id = $1;`, artistID); err != nil {
+		slog.Error("failed to clear artist similarities", "error", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO artist_similarities (source_id, target_id, weight)
+		SELECT t.artist_id, rt.artist_id, count(DISTINCT t.urn)
+		FROM tracks t
+		JOIN related_tracks r ON r.track_urn = t.urn
+		JOIN tracks rt ON rt.urn = r.related_urn
+		WHERE t.artist_id = $1 AND rt.artist_id <> t.artist_id
+		GROUP BY t.artist_id, rt.artist_id;`,
+		artistID,
+	)
+	if err != nil {
+		slog.Error("failed to roll up artist similarities", "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
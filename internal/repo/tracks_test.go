@@ -0,0 +1,106 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestTracks(t *testing.T) {
+	forEachDB(t, testTracks)
+}
+
+func testTracks(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	pr := repo.NewPeopleRepository(db)
+	tr := repo.NewTracksRepository(db)
+	er := repo.NewEdgesRepository(db)
+
+	var ids []int64
+	for _, handle := range []string{"alice", "bob", "carol"} {
+		p, err := pr.Upsert(ctx, repo.Person{Username: handle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.Id)
+	}
+	alice, bob, carol := ids[0], ids[1], ids[2]
+
+	err := tr.UpsertTracks(ctx, []repo.Track{
+		{Urn: "a1", ArtistID: alice, Title: "First"},
+		{Urn: "a2", ArtistID: alice},
+		{Urn: "b1", ArtistID: bob},
+		{Urn: "b2", ArtistID: bob},
+		{Urn: "c1", ArtistID: carol},
+		// The first occurrence of a track wins
+		{Urn: "a1", ArtistID: alice, Title: "Duplicate"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var title string
+	if err := db.QueryRowContext(ctx, `SELECT title FROM tracks WHERE urn = 'a1';`).Scan(&title); err != nil || title != "First" {
+		t.Errorf("title of a1: got %q, err = %v", title, err)
+	}
+
+	// A track is never related to itself
+	if err := tr.ReplaceRelated(ctx, "a1", []string{"b2", "a1", "b1", "c1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.ReplaceRelated(ctx, "a2", []string{"b1"}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.QueryContext(ctx, `SELECT related_urn FROM related_tracks WHERE track_urn = 'a1' ORDER BY rank;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var related []string
+	for rows.Next() {
+		var urn string
+		if err := rows.Scan(&urn); err != nil {
+			t.Fatal(err)
+		}
+		related = append(related, urn)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b2", "b1", "c1"}; !slices.Equal(related, want) {
+		t.Errorf("tracks related to a1: got %v, want %v", related, want)
+	}
+
+	if err := tr.RollUpSimilarity(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	// a1 lists two of bob's tracks, but only counts once
+	assertSimilar(t, er, alice, map[int64]float64{bob: 2, carol: 1})
+
+	if err := tr.ReplaceRelated(ctx, "a1", []string{"c1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.RollUpSimilarity(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	assertSimilar(t, er, alice, map[int64]float64{bob: 1, carol: 1})
+}
+
+// assertSimilar checks the weights of the sounds_like edges from a person.
+func assertSimilar(t *testing.T, er *repo.EdgesRepository, personID int64, want map[int64]float64) {
+	t.Helper()
+
+	edges, err := er.FindSimilarEdges(context.Background(), personID, repo.DirectionOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[int64]float64, len(edges))
+	for _, e := range edges {
+		got[e.TargetID] = e.Weight
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("sounds_like weights from %d: got %v, want %v", personID, got, want)
+	}
+}
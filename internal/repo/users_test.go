@@ -1,2 +1,32 @@
 package repo_test
 
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+func TestUsers(t *testing.T) {
+	forEachDB(t, testUsers)
+}
+
+func testUsers(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	ur := repo.NewUsersRepository(db)
+
+	created, err := ur.Create(ctx, 42, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 42 || created.Username != "alice" || created.CreatedAt.IsZero() {
+		t.Errorf("created %+v", created)
+	}
+
+	user, found, err := ur.FindByKey(ctx, repo.UserKeyID, strconv.Itoa(42))
+	if err != nil || !found || user.Username != "alice" {
+		t.Errorf("got %+v, found = %v, err = %v", user, found, err)
+	}
+}
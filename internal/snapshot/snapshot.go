@@ -0,0 +1,184 @@
+// Package snapshot freezes the people graph under names, and reports what changed between
+// two of them.
+package snapshot
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// namePattern is what snapshot names look like, so that they fit in URLs and file names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidName reports whether a snapshot can be named name.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+type SnapshotProvider interface {
+	CreateSnapshot(ctx context.Context, name string) (snapshot repo.Snapshot, err error)
+	ListSnapshots(ctx context.Context) (snapshots []repo.Snapshot, err error)
+	FindSnapshot(ctx context.Context, name string) (snapshot repo.Snapshot, found bool, err error)
+	DeleteSnapshot(ctx context.Context, name string) (found bool, err error)
+	CountDiff(ctx context.Context, fromID, toID int64) (counts repo.SnapshotDiffCounts, err error)
+	PeopleOnlyIn(ctx context.Context, inID, notInID int64, limit int) (people []repo.SnapshotPerson, err error)
+	FollowsOnlyIn(ctx context.Context, inID, notInID int64, limit int) (follows []repo.SnapshotFollow, err error)
+	FollowerGains(ctx context.Context, fromID, toID int64, limit int) (gains []repo.FollowerGain, err error)
+	FindSnapshotPeople(ctx context.Context, snapshotID int64, ids []int64) (people []repo.SnapshotPerson, err error)
+	ListSnapshotMemberships(ctx context.Context, snapshotID int64) (memberships map[int64]int, err error)
+}
+
+// CommunityMove is a person who left the community their old community became.
+type CommunityMove struct {
+	Person repo.SnapshotPerson
+	// From and To are the person's communities, numbered as in each snapshot's run.
+	From int
+	To   int
+}
+
+// Diff reports what changed from one snapshot to another. Lists are cut to a limit, most
+// followed people first; the counts are not.
+type Diff struct {
+	From repo.Snapshot
+	To   repo.Snapshot
+	// Counts counts every person and follow added or removed.
+	Counts         repo.SnapshotDiffCounts
+	AddedPeople    []repo.SnapshotPerson
+	RemovedPeople  []repo.SnapshotPerson
+	AddedFollows   []repo.SnapshotFollow
+	RemovedFollows []repo.SnapshotFollow
+	FollowerGains  []repo.FollowerGain
+	// MovedPeople counts the people in both snapshots who changed community. It stays 0 when
+	// either snapshot was taken before communities were detected.
+	MovedPeople    int
+	CommunityMoves []CommunityMove
+}
+
+// SnapshotController creates, lists, deletes and compares snapshots.
+type SnapshotController struct {
+	snapshots SnapshotProvider
+}
+
+// NewSnapshotController creates a new instance of SnapshotController.
+func NewSnapshotController(snapshotsRepo SnapshotProvider) *SnapshotController {
+	return &SnapshotController{snapshots: snapshotsRepo}
+}
+
+// Create snapshots the people graph under a name. It returns repo.ErrSnapshotExists if the
+// name is taken.
+func (sc *SnapshotController) Create(ctx context.Context, name string) (repo.Snapshot, error) {
+	return sc.snapshots.CreateSnapshot(ctx, name)
+}
+
+// List returns every snapshot, newest first.
+func (sc *SnapshotController) List(ctx context.Context) ([]repo.Snapshot, error) {
+	return sc.snapshots.ListSnapshots(ctx)
+}
+
+// Delete deletes a snapshot.
+func (sc *SnapshotController) Delete(ctx context.Context, name string) (found bool, err error) {
+	return sc.snapshots.DeleteSnapshot(ctx, name)
+}
+
+// Diff compares the snapshot named from to the one named to, listing up to limit of each
+// kind of change.
+func (sc *SnapshotController) Diff(ctx context.Context, from, to string, limit int) (diff Diff, found bool, err error) {
+	for _, s := range []struct {
+		name     string
+		snapshot *repo.Snapshot
+	}{{from, &diff.From}, {to, &diff.To}} {
+		*s.snapshot, found, err = sc.snapshots.FindSnapshot(ctx, s.name)
+		if err != nil || !found {
+			return Diff{}, found, err
+		}
+	}
+	fromID, toID := diff.From.Id, diff.To.Id
+
+	if diff.Counts, err = sc.snapshots.CountDiff(ctx, fromID, toID); err != nil {
+		return Diff{}, false, err
+	}
+	if diff.AddedPeople, err = sc.snapshots.PeopleOnlyIn(ctx, toID, fromID, limit); err != nil {
+		return Diff{}, false, err
+	}
+	if diff.RemovedPeople, err = sc.snapshots.PeopleOnlyIn(ctx, fromID, toID, limit); err != nil {
+		return Diff{}, false, err
+	}
+	if diff.AddedFollows, err = sc.snapshots.FollowsOnlyIn(ctx, toID, fromID, limit); err != nil {
+		return Diff{}, false, err
+	}
+	if diff.RemovedFollows, err = sc.snapshots.FollowsOnlyIn(ctx, fromID, toID, limit); err != nil {
+		return Diff{}, false, err
+	}
+	if diff.FollowerGains, err = sc.snapshots.FollowerGains(ctx, fromID, toID, limit); err != nil {
+		return Diff{}, false, err
+	}
+
+	if diff.From.CommunityRunID == nil || diff.To.CommunityRunID == nil {
+		return diff, true, nil
+	}
+	before, err := sc.snapshots.ListSnapshotMemberships(ctx, fromID)
+	if err != nil {
+		return Diff{}, false, err
+	}
+	after, err := sc.snapshots.ListSnapshotMemberships(ctx, toID)
+	if err != nil {
+		return Diff{}, false, err
+	}
+	moved := Moves(before, after)
+	diff.MovedPeople = len(moved)
+
+	ids := make([]int64, 0, len(moved))
+	for id := range moved {
+		ids = append(ids, id)
+	}
+	people, err := sc.snapshots.FindSnapshotPeople(ctx, toID, ids)
+	if err != nil {
+		return Diff{}, false, err
+	}
+	slices.SortFunc(people, func(a, b repo.SnapshotPerson) int {
+		return cmp.Or(b.Followers-a.Followers, cmp.Compare(a.Id, b.Id))
+	})
+	for _, p := range people[:min(limit, len(people))] {
+		diff.CommunityMoves = append(diff.CommunityMoves, CommunityMove{Person: p, From: before[p.Id], To: after[p.Id]})
+	}
+	return diff, true, nil
+}
+
+// MatchCommunities matches each community of one run to the community of another run that
+// holds most of its members, as community ids are not comparable across runs. Ties go to the
+// lower id. Communities whose members all left are not matched.
+func MatchCommunities(before, after map[int64]int) map[int]int {
+	overlaps := make(map[[2]int]int)
+	for id, c := range before {
+		if d, ok := after[id]; ok {
+			overlaps[[2]int{c, d}]++
+		}
+	}
+
+	matches := make(map[int]int)
+	best := make(map[int]int)
+	for pair, n := range overlaps {
+		c, d := pair[0], pair[1]
+		if m, ok := matches[c]; !ok || n > best[c] || (n == best[c] && d < m) {
+			matches[c], best[c] = d, n
+		}
+	}
+	return matches
+}
+
+// Moves returns the people in both runs whose community in the later run is not the match of
+// their community in the earlier one, with their earlier community.
+func Moves(before, after map[int64]int) map[int64]int {
+	matches := MatchCommunities(before, after)
+	moved := make(map[int64]int)
+	for id, c := range before {
+		if d, ok := after[id]; ok && d != matches[c] {
+			moved[id] = c
+		}
+	}
+	return moved
+}
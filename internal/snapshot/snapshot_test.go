@@ -0,0 +1,42 @@
+package snapshot_test
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"lopa.to/sonimulus/internal/snapshot"
+)
+
+func TestMatchCommunities(t *testing.T) {
+	// Community 0 was renumbered 1 and lost person 3 to community 0, community 1 was split
+	// evenly and kept the lower id, and community 2 lost all its members
+	before := map[int64]int{1: 0, 2: 0, 3: 0, 4: 1, 5: 1, 6: 2}
+	after := map[int64]int{1: 1, 2: 1, 3: 0, 4: 0, 5: 2, 7: 0}
+
+	matches := snapshot.MatchCommunities(before, after)
+	if want := map[int]int{0: 1, 1: 0}; !maps.Equal(matches, want) {
+		t.Errorf("got matches %v, want %v", matches, want)
+	}
+
+	moved := snapshot.Moves(before, after)
+	if want := map[int64]int{3: 0, 5: 1}; !maps.Equal(moved, want) {
+		t.Errorf("got moves %v, want %v", moved, want)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"2026-10-19":            true,
+		"crawl_3.v2":            true,
+		"":                      false,
+		"-latest":               false,
+		"with space":            false,
+		"../escape":             false,
+		strings.Repeat("a", 65): false,
+	} {
+		if got := snapshot.ValidName(name); got != want {
+			t.Errorf("ValidName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
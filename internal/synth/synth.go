@@ -0,0 +1,265 @@
+// Package synth generates synthetic SoundCloud-like follow networks, for developing the
+// visualization and analytics without crawling.
+package synth
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+const (
+	// urnBase numbers synthetic people far above real SoundCloud user ids.
+	urnBase = 9_000_000_000
+	// urnPrefix starts every SoundCloud user URN.
+	urnPrefix = "soundcloud:users:"
+	// handleAlphabet lists the characters of handles, as digits of their URN.
+	handleAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789_-"
+	// defaultAvatar is SoundCloud's avatar for people who did not upload one.
+	defaultAvatar = "https://a1.sndcdn.com/images/default_avatar_large.png"
+	// avatarURL draws an avatar seeded by handle, so that people look apart.
+	avatarURL = "https://api.dicebear.com/9.x/shapes/png?seed=%s"
+)
+
+// Options tunes a synthetic network.
+type Options struct {
+	People      int
+	Communities int
+	// FollowsPerPerson is how many people a newcomer follows on average. Counts are
+	// log-normally distributed, so that a few people follow many.
+	FollowsPerPerson float64
+	// Mixing is the share of follows made outside of the follower's community.
+	Mixing float64
+	// Reciprocity is the chance that a follow is returned.
+	Reciprocity float64
+	// Seed seeds every random choice, so that networks are reproducible.
+	Seed uint64
+}
+
+// DefaultOptions generate a network of the size of a two-hop crawl.
+var DefaultOptions = Options{
+	People:           10000,
+	Communities:      24,
+	FollowsPerPerson: 20,
+	Mixing:           0.1,
+	Reciprocity:      0.25,
+	Seed:             1,
+}
+
+// IsSynthetic reports whether p was generated rather than crawled.
+func IsSynthetic(p repo.Person) bool {
+	id, found := strings.CutPrefix(p.Urn, urnPrefix)
+	if !found {
+		return false
+	}
+	n, ok := new(big.Int).SetString(id, 10)
+	return ok && n.Cmp(big.NewInt(urnBase)) >= 0
+}
+
+// urn numbers a handle above urnBase. Handles are read as numbers in bijective base 38,
+// where no digit is zero, so that every handle gets its own number.
+func urn(handle string) string {
+	n, base := new(big.Int), big.NewInt(int64(len(handleAlphabet)))
+	for _, r := range handle {
+		n.Mul(n, base)
+		n.Add(n, big.NewInt(int64(strings.IndexRune(handleAlphabet, r)+1)))
+	}
+	return urnPrefix + n.Add(n, big.NewInt(urnBase)).String()
+}
+
+// Network is a synthetic follow network.
+type Network struct {
+	// People are not stored yet, and have no ids.
+	People []repo.Person
+	// Communities lists the community each person was generated in, by index into People.
+	Communities []int
+	// Follows lists follows from and to people, by index into People.
+	Follows [][2]int32
+}
+
+// Generate grows a network one newcomer at a time, by preferential attachment within
+// communities: newcomers follow people of their own community in proportion to how many
+// followers they already have, and occasionally someone from elsewhere. Attributes are
+// assigned once the network is grown, so that the most followed are the most likely to be
+// verified and to pay for an artist plan, and to upload more tracks.
+func Generate(opts Options) Network {
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	n := opts.People
+	net := Network{
+		People:      make([]repo.Person, n),
+		Communities: make([]int, n),
+	}
+	if n == 0 {
+		return net
+	}
+	communities := max(opts.Communities, 1)
+
+	// Community sizes follow Zipf's law, a few large scenes and a long tail of niches
+	weights := make([]float64, communities)
+	var total float64
+	for c := range weights {
+		total += 1 / math.Pow(float64(c+1), 0.8)
+		weights[c] = total
+	}
+
+	// Pools list every person once, and once more per follower, so that a uniform pick
+	// from a pool picks people in proportion to their followers
+	global := make([]int32, 0, n+int(float64(n)*opts.FollowsPerPerson))
+	pools := make([][]int32, communities)
+	inDegrees := make([]int, n)
+	seen := make(map[[2]int32]bool)
+	follow := func(from, to int32) bool {
+		if from == to || seen[[2]int32{from, to}] {
+			return false
+		}
+		seen[[2]int32{from, to}] = true
+		net.Follows = append(net.Follows, [2]int32{from, to})
+		inDegrees[to]++
+		global = append(global, to)
+		pools[net.Communities[to]] = append(pools[net.Communities[to]], to)
+		return true
+	}
+
+	// Follow counts are log-normal with the requested mean
+	const sigma = 1.0
+	mu := math.Log(max(opts.FollowsPerPerson, 1)) - sigma*sigma/2
+	for i := range int32(n) {
+		c, _ := slices.BinarySearch(weights, rng.Float64()*total)
+		net.Communities[i] = c
+
+		follows := max(1, int(math.Round(math.Exp(mu+sigma*rng.NormFloat64()))))
+		// Picks repeat people already followed, more so in small pools, so they are bounded
+		attempts := 4 * follows
+		for ; follows > 0 && attempts > 0 && len(global) > 0; attempts-- {
+			pool := pools[c]
+			if len(pool) == 0 || rng.Float64() < opts.Mixing {
+				pool = global
+			}
+			j := pool[rng.IntN(len(pool))]
+			if !follow(i, j) {
+				continue
+			}
+			follows--
+			if rng.Float64() < opts.Reciprocity {
+				follow(j, i)
+			}
+		}
+
+		global = append(global, i)
+		pools[c] = append(pools[c], i)
+	}
+
+	assignAttributes(rng, net, inDegrees)
+	return net
+}
+
+// assignAttributes names everyone, and draws their plan, verification, tracks and avatar
+// from how high they rank by followers.
+func assignAttributes(rng *rand.Rand, net Network, inDegrees []int) {
+	n := len(net.People)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return inDegrees[b] - inDegrees[a] })
+	ranks := make([]int, n)
+	for rank, i := range order {
+		ranks[i] = rank
+	}
+
+	handles := make(map[string]int)
+	for i := range net.People {
+		// top is 1 for the most followed person, and falls towards 0 for the least followed
+		top := 1 - float64(ranks[i])/float64(n)
+
+		p := repo.Person{Plan: repo.PlanNone}
+		p.Name, p.Username = name(rng, handles)
+		// The URN follows from the handle, which people are upserted by, so that networks
+		// generated with other options can be stored over each other
+		p.Urn = urn(p.Username)
+
+		pro := 0.03 + 0.4*math.Pow(top, 8)
+		artist := 0.12 + 0.3*math.Pow(top, 4)
+		switch r := rng.Float64(); {
+		case r < pro:
+			p.Plan = repo.PlanArtistPro
+		case r < pro+artist:
+			p.Plan = repo.PlanArtist
+		}
+		p.Verified = rng.Float64() < 0.002+0.6*math.Pow(top, 200)
+
+		// Track counts are log-normal around a median that grows with the plan, and most
+		// listeners never upload
+		median := 25.0
+		switch p.Plan {
+		case repo.PlanNone:
+			median = 3
+		case repo.PlanArtistPro:
+			median = 80
+		}
+		if p.Plan != repo.PlanNone || rng.Float64() < 0.3 {
+			p.TrackCount = max(1, int64(math.Round(median*math.Exp(rng.NormFloat64()))))
+		}
+
+		p.ImageUrl = defaultAvatar
+		if rng.Float64() < 0.75 {
+			p.ImageUrl = fmt.Sprintf(avatarURL, p.Username)
+		}
+		net.People[i] = p
+	}
+}
+
+var (
+	firstNames = []string{
+		"Alex", "Ana", "Ben", "Carla", "Dani", "Eli", "Finn", "Gia", "Hugo", "Iris", "Jay", "Kai",
+		"Lena", "Milo", "Nia", "Omar", "Pia", "Quinn", "Rosa", "Sam", "Tess", "Umi", "Vic", "Wes",
+		"Xan", "Yara", "Zoe", "Leo", "Mara", "Noah", "Ines", "Theo", "Lou", "Ravi", "Sofi", "Tomas",
+	}
+	lastNames = []string{
+		"Adler", "Baker", "Costa", "Diaz", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Jensen",
+		"Kim", "Lopez", "Moreau", "Novak", "Okafor", "Park", "Rossi", "Silva", "Tanaka", "Weber",
+	}
+	stageWords = []string{
+		"Velvet", "Neon", "Echo", "Midnight", "Static", "Lunar", "Golden", "Hollow", "Crystal",
+		"Analog", "Drift", "Ember", "Phantom", "Solar", "Vapor", "Wild", "Ghost", "Honey",
+	}
+	stageNouns = []string{
+		"Waves", "Tapes", "Bloom", "Circuit", "Garden", "Signal", "Tide", "Haze", "Motel", "Youth",
+		"Pulse", "Season", "Engine", "Theory", "Club", "Machine",
+	}
+	prefixes = []string{"DJ ", "MC ", "Lil ", "", "", "", "", ""}
+)
+
+// name draws a display name, a person's or a stage name, and a handle for it. handles counts
+// the handles made from each base, which is numbered after its first use. Names hold no
+// digits, so numbered handles never clash with those of another base.
+func name(rng *rand.Rand, handles map[string]int) (display, handle string) {
+	switch rng.IntN(3) {
+	case 0:
+		display = pick(rng, firstNames) + " " + pick(rng, lastNames)
+	case 1:
+		display = pick(rng, prefixes) + pick(rng, firstNames)
+	default:
+		display = pick(rng, stageWords) + " " + pick(rng, stageNouns)
+	}
+
+	base := strings.ToLower(strings.ReplaceAll(display, " ", "-"))
+	if rng.IntN(2) == 0 {
+		base = strings.ReplaceAll(base, "-", "")
+	}
+	handle = base
+	if count := handles[base]; count > 0 {
+		handle = fmt.Sprintf("%s%d", base, count)
+	}
+	handles[base]++
+	return display, handle
+}
+
+func pick(rng *rand.Rand, words []string) string {
+	return words[rng.IntN(len(words))]
+}
@@ -0,0 +1,129 @@
+package synth_test
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+
+	"lopa.to/sonimulus/internal/repo"
+	"lopa.to/sonimulus/internal/synth"
+)
+
+func TestGenerateDeterministic(t *testing.T) {
+	opts := synth.DefaultOptions
+	opts.People = 500
+
+	a, b := synth.Generate(opts), synth.Generate(opts)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("got different networks from the same seed")
+	}
+
+	opts.Seed++
+	if c := synth.Generate(opts); reflect.DeepEqual(a.Follows, c.Follows) {
+		t.Error("got the same follows from another seed")
+	}
+}
+
+func TestGenerateStructure(t *testing.T) {
+	opts := synth.DefaultOptions
+	net := synth.Generate(opts)
+
+	if len(net.People) != opts.People || len(net.Communities) != opts.People {
+		t.Fatalf("got %d people, want %d", len(net.People), opts.People)
+	}
+
+	seen := make(map[[2]int32]bool, len(net.Follows))
+	inDegrees := make([]int, len(net.People))
+	var intra, reciprocated int
+	for _, f := range net.Follows {
+		if f[0] == f[1] || seen[f] {
+			t.Fatalf("got self or duplicate follow %v", f)
+		}
+		seen[f] = true
+		inDegrees[f[1]]++
+		if net.Communities[f[0]] == net.Communities[f[1]] {
+			intra++
+		}
+	}
+	for _, f := range net.Follows {
+		if seen[[2]int32{f[1], f[0]}] {
+			reciprocated++
+		}
+	}
+
+	mean := float64(len(net.Follows)) / float64(len(net.People))
+	if mean < opts.FollowsPerPerson || mean > 2*opts.FollowsPerPerson {
+		t.Errorf("got %.1f follows per person, want around %g and their returns", mean, opts.FollowsPerPerson)
+	}
+	// Preferential attachment makes a few people far more followed than the average
+	if top := slices.Max(inDegrees); float64(top) < 20*mean {
+		t.Errorf("got at most %d followers, want a heavy tail over a mean of %.1f", top, mean)
+	}
+	if share := float64(intra) / float64(len(net.Follows)); share < 0.75 {
+		t.Errorf("got %.2f of follows within communities, want most", share)
+	}
+	if share := float64(reciprocated) / float64(len(net.Follows)); share < 0.2 || share > 0.6 {
+		t.Errorf("got %.2f of follows reciprocated", share)
+	}
+}
+
+func TestGenerateAttributes(t *testing.T) {
+	net := synth.Generate(synth.DefaultOptions)
+	handle := regexp.MustCompile(`^[a-z0-9_-]+$`)
+	urn := regexp.MustCompile(`^soundcloud:users:[0-9]+$`)
+
+	handles := make(map[string]bool, len(net.People))
+	urns := make(map[string]bool, len(net.People))
+	plans := make(map[repo.Plan]int)
+	var verified, uploaders int
+	for _, p := range net.People {
+		if !handle.MatchString(p.Username) || handles[p.Username] {
+			t.Fatalf("got invalid or duplicate handle %q", p.Username)
+		}
+		handles[p.Username] = true
+		if !urn.MatchString(p.Urn) || !synth.IsSynthetic(p) || urns[p.Urn] {
+			t.Fatalf("got an invalid, real or duplicate urn %q", p.Urn)
+		}
+		urns[p.Urn] = true
+		if p.Name == "" || p.ImageUrl == "" {
+			t.Errorf("got %+v, want a name and an avatar", p)
+		}
+
+		plans[p.Plan]++
+		if p.Verified {
+			verified++
+		}
+		if p.TrackCount > 0 {
+			uploaders++
+		}
+	}
+
+	n := float64(len(net.People))
+	if share := float64(verified) / n; share < 0.001 || share > 0.03 {
+		t.Errorf("got %.4f verified, want a few", share)
+	}
+	if share := float64(plans[repo.PlanNone]) / n; share < 0.6 || share > 0.95 {
+		t.Errorf("got %.2f without a plan, want most", share)
+	}
+	if plans[repo.PlanArtist] <= plans[repo.PlanArtistPro] || plans[repo.PlanArtistPro] == 0 {
+		t.Errorf("got plans %v, want fewer pros than artists", plans)
+	}
+	if share := float64(uploaders) / n; share < 0.2 || share > 0.7 {
+		t.Errorf("got %.2f uploading tracks", share)
+	}
+}
+
+func TestIsSynthetic(t *testing.T) {
+	for urn, want := range map[string]bool{
+		"soundcloud:users:123":            false,
+		"soundcloud:users:9000000001":     true,
+		"soundcloud:users:12345678901234": true,
+		"soundcloud:users:":               false,
+		"":                                false,
+	} {
+		if got := synth.IsSynthetic(repo.Person{Urn: urn}); got != want {
+			t.Errorf("IsSynthetic(%q) = %v, want %v", urn, got, want)
+		}
+	}
+}
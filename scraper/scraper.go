@@ -12,7 +12,7 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	"lopa.to/sonimulus/env"
-	"lopa.to/sonimulus/repository"
+	"lopa.to/sonimulus/internal/repo"
 )
 
 const (
@@ -61,7 +61,7 @@ func NewScraper(maxDepth int, e env.Env) *Scraper {
 func (s *Scraper) ScrapePeopleConcurrent(
 	numWorkers int,
 	rootHandle string,
-	onPerson func(handle, name, imageUrl string, verified bool, plan repository.Plan, trackCount int64) (id int64),
+	onPerson func(handle, name, imageUrl string, verified bool, plan repo.Plan, trackCount int64) (id int64),
 	onFollows func(followerId int64, followeeHandles []string),
 ) {
 	visited := sync.Map{}
@@ -124,14 +124,14 @@ func (s *Scraper) ScrapePeopleConcurrent(
 func (s *Scraper) ScrapePerson(
 	handle string,
 	getFollows bool,
-	onPerson func(handle, name, imageUrl string, verified bool, plan repository.Plan, trackCount int64) (id int64),
+	onPerson func(handle, name, imageUrl string, verified bool, plan repo.Plan, trackCount int64) (id int64),
 	onFollows func(followerId int64, followeeHandles []string),
 ) error {
 	var (
 		name       string
 		imageUrl   string
 		verified   bool = false
-		plan       repository.Plan
+		plan       repo.Plan
 		trackCount int64
 	)
 
@@ -177,11 +177,11 @@ func (s *Scraper) ScrapePerson(
 		if title != nil {
 			switch *title {
 			case "Artist":
-				plan = repository.PlanArtist
+				plan = repo.PlanArtist
 			case "Artist Pro":
-				plan = repository.PlanArtistPro
+				plan = repo.PlanArtistPro
 			default:
-				plan = repository.PlanNone
+				plan = repo.PlanNone
 			}
 		}
 	}
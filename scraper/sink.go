@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"lopa.to/sonimulus/internal/repo"
+)
+
+// FollowsBulkStorer stores many follows at once.
+type FollowsBulkStorer interface {
+	BulkCreateFollows(ctx context.Context, follows []repo.Follow) error
+}
+
+// FollowSink batches scraped follows, and stores a batch once it holds batchSize
+// follows or once flushInterval has passed since the last store.
+type FollowSink struct {
+	store         FollowsBulkStorer
+	batchSize     int
+	flushInterval time.Duration
+	follows       chan repo.Follow
+	done          chan struct{}
+}
+
+// NewFollowSink creates a FollowSink and starts batching in the background. Close must
+// be called to store the last batch.
+func NewFollowSink(ctx context.Context, store FollowsBulkStorer, batchSize int, flushInterval time.Duration) *FollowSink {
+	s := &FollowSink{
+		store:         store,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		follows:       make(chan repo.Follow, batchSize),
+		done:          make(chan struct{}),
+	}
+	go s.run(ctx)
+	return s
+}
+
+// Add queues the follows of a person. It has the signature of the onFollows callback
+// of ScrapePeopleConcurrent.
+func (s *FollowSink) Add(followerId int64, followeeHandles []string) {
+	for _, handle := range followeeHandles {
+		s.follows <- repo.Follow{FollowerID: followerId, FolloweeHandle: handle}
+	}
+}
+
+// Close stores every queued follow, and waits for the store to complete.
+func (s *FollowSink) Close() {
+	close(s.follows)
+	<-s.done
+}
+
+func (s *FollowSink) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]repo.Follow, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		start := time.Now()
+		if err := s.store.BulkCreateFollows(ctx, batch); err != nil {
+			slog.Error("error storing follows", "follows", len(batch), "error", err)
+		} else {
+			slog.Info("stored follows", "follows", len(batch), "duration", time.Since(start))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case f, ok := <-s.follows:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, f)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}